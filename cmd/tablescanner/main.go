@@ -0,0 +1,351 @@
+// Command tablescanner lists, dumps, detects and profiles spreadsheet documents
+//
+//	tablescanner sheets [flags] FILE
+//	tablescanner dump [flags] FILE
+//	tablescanner export [flags] FILE
+//	tablescanner detect FILE
+//	tablescanner profile [flags] FILE
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+	"github.com/technix86/golang-tablescanner/writer/arrow"
+	"github.com/technix86/golang-tablescanner/writer/jsonl"
+	"github.com/technix86/golang-tablescanner/writer/parquet"
+)
+
+const usage = `usage: tablescanner COMMAND [flags] FILE
+
+commands:
+  sheets   list sheet names and hide levels
+  dump     write sheet rows as csv, tsv, jsonl or markdown
+  export   write typed sheet rows as ndjson, arrow or parquet
+  detect   print detected workbook type
+  profile  print column types and statistics of sheet as json
+
+run "tablescanner COMMAND -h" for command flags
+`
+
+type tOpenOptions struct {
+	i18n              string
+	dateFormat        string
+	decimalSeparator  string
+	thousandSeparator string
+	trim              bool
+	sheet             string
+	password          string
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "sheets":
+		err = runSheets(os.Args[2:])
+	case "dump":
+		err = runDump(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	case "detect":
+		err = runDetect(os.Args[2:])
+	case "profile":
+		err = runProfile(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "tablescanner: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet declares flags shared by commands opening document, sheet selection is optional
+func newFlagSet(name string, options *tOpenOptions, withSheet bool) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.StringVar(&options.i18n, "i18n", "", "number format locale (en, ru)")
+	flags.StringVar(&options.dateFormat, "date-format", "", "fixed excel format of all date cells, like yyyy-mm-dd")
+	flags.StringVar(&options.decimalSeparator, "decimal-separator", "", "decimal separator of formatted numbers")
+	flags.StringVar(&options.thousandSeparator, "thousand-separator", "", "thousand separator of formatted numbers")
+	flags.BoolVar(&options.trim, "trim", false, "trim spaces of formatted values")
+	flags.StringVar(&options.password, "password", "", "password of encrypted workbook")
+	if withSheet {
+		flags.StringVar(&options.sheet, "sheet", "", "sheet name, 0-based index or glob pattern like 'report*', default is active sheet")
+	}
+	return flags
+}
+
+// parseFileArg parses flags and requires single FILE argument
+func parseFileArg(flags *flag.FlagSet, args []string) (error, string) {
+	if err := flags.Parse(args); nil != err {
+		return err, ""
+	}
+	if 1 != flags.NArg() {
+		return fmt.Errorf("%s: single FILE argument expected", flags.Name()), ""
+	}
+	return nil, flags.Arg(0)
+}
+
+// openDocument opens file and applies formatter options, sheet is selected when requested
+func openDocument(fileName string, options *tOpenOptions) (error, tablescanner.ITableDocumentScanner) {
+	var err error
+	var scanner tablescanner.ITableDocumentScanner
+	if "" != options.password {
+		err, scanner = tablescanner.NewTableStreamWithPassword(fileName, options.password)
+	} else {
+		err, scanner = tablescanner.NewTableStream(fileName)
+	}
+	if nil != err {
+		return err, nil
+	}
+	if "" != options.i18n {
+		if err = scanner.SetI18n(options.i18n); nil != err {
+			_ = scanner.Close()
+			return err, nil
+		}
+	}
+	// formatter of XLS format is a stub which warns when requested, so it is touched only when needed
+	if "" != options.dateFormat || "" != options.decimalSeparator || "" != options.thousandSeparator || options.trim {
+		formatter := scanner.Formatter()
+		formatter.SetDateFixedFormat(options.dateFormat)
+		if "" != options.decimalSeparator {
+			formatter.SetDecimalSeparator(options.decimalSeparator)
+		}
+		if "" != options.thousandSeparator {
+			formatter.SetThousandSeparator(options.thousandSeparator)
+		}
+		if options.trim {
+			formatter.SetTrimOn()
+		}
+	}
+	if "" != options.sheet {
+		if err = selectSheet(scanner, options.sheet); nil != err {
+			_ = scanner.Close()
+			return err, nil
+		}
+	}
+	return nil, scanner
+}
+
+// selectSheet selects sheet by exact name, then by 0-based index, then by case-insensitive name or glob pattern
+func selectSheet(scanner tablescanner.ITableDocumentScanner, sheet string) error {
+	if nil == scanner.SetSheetByName(sheet, tablescanner.SheetMatchExact) {
+		return nil
+	}
+	if id, err := strconv.Atoi(sheet); nil == err && id >= 0 && id < len(scanner.GetSheets()) {
+		return scanner.SetSheetId(id)
+	}
+	if strings.ContainsAny(sheet, "*?[") {
+		return scanner.SetSheetByName(sheet, tablescanner.SheetMatchGlob)
+	}
+	return scanner.SetSheetByName(sheet, tablescanner.SheetMatchIgnoreCase)
+}
+
+func hideLevelName(level tablescanner.TSheetHideLevel) string {
+	switch level {
+	case tablescanner.TableSheetVisible:
+		return "visible"
+	case tablescanner.TableSheetHidden:
+		return "hidden"
+	case tablescanner.TableSheetVeryHidden:
+		return "veryHidden"
+	}
+	return strconv.Itoa(int(level))
+}
+
+func runSheets(args []string) error {
+	options := &tOpenOptions{}
+	err, fileName := parseFileArg(newFlagSet("sheets", options, false), args)
+	if nil != err {
+		return err
+	}
+	err, scanner := openDocument(fileName, options)
+	if nil != err {
+		return err
+	}
+	defer scanner.Close()
+	current := scanner.GetActiveSheetId()
+	for id, info := range scanner.GetSheets() {
+		marker := ""
+		if id == current {
+			marker = "\tactive"
+		}
+		fmt.Printf("%d\t%s\t%s%s\n", id, info.GetName(), hideLevelName(info.GetHideLevel()), marker)
+	}
+	return nil
+}
+
+func runDump(args []string) error {
+	options := &tOpenOptions{}
+	flags := newFlagSet("dump", options, true)
+	format := flags.String("format", "csv", "output format: csv, tsv, jsonl or markdown")
+	maxRows := flags.Int("max-rows", 0, "rows limit, 0 means whole sheet")
+	err, fileName := parseFileArg(flags, args)
+	if nil != err {
+		return err
+	}
+	var writer iRowWriter
+	switch *format {
+	case "csv":
+		writer = newCSVWriter(os.Stdout, ',')
+	case "tsv":
+		writer = newCSVWriter(os.Stdout, '\t')
+	case "jsonl":
+		writer = &tJSONLinesWriter{encoder: json.NewEncoder(os.Stdout)}
+	case "markdown", "md":
+		writer = &tMarkdownWriter{output: os.Stdout}
+	default:
+		return fmt.Errorf("unknown dump format %q", *format)
+	}
+	err, scanner := openDocument(fileName, options)
+	if nil != err {
+		return err
+	}
+	defer scanner.Close()
+	for rowNum := 0; 0 == *maxRows || rowNum < *maxRows; rowNum++ {
+		err = scanner.Scan()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err
+		}
+		if err = writer.WriteRow(scanner.GetScanned()); nil != err {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func runExport(args []string) error {
+	options := &tOpenOptions{}
+	flags := newFlagSet("export", options, true)
+	format := flags.String("format", "ndjson", "output format: ndjson, arrow or parquet")
+	outputName := flags.String("o", "", "output file, default is stdout")
+	rowOptions := tablescanner.TTypedRowOptions{}
+	flags.IntVar(&rowOptions.HeaderRows, "header-rows", -1, "leading header rows, -1 detects them")
+	flags.IntVar(&rowOptions.SampleRows, "sample-rows", 0, "rows used to infer column types, 0 means 1000, -1 means whole sheet")
+	batchRows := flags.Int("batch-rows", 0, "rows per arrow record batch or parquet row group, 0 means writer default")
+	err, fileName := parseFileArg(flags, args)
+	if nil != err {
+		return err
+	}
+	switch *format {
+	case "ndjson", "jsonl", "arrow", "parquet":
+	default:
+		return fmt.Errorf("unknown export format %q", *format)
+	}
+	err, scanner := openDocument(fileName, options)
+	if nil != err {
+		return err
+	}
+	defer scanner.Close()
+	output := os.Stdout
+	if "" != *outputName {
+		if output, err = os.Create(*outputName); nil != err {
+			return err
+		}
+		defer output.Close()
+	}
+	sheetId := scanner.GetCurrentSheetId()
+	switch *format {
+	case "arrow":
+		err = arrow.WriteSheet(output, scanner, sheetId, arrow.TOptions{TTypedRowOptions: rowOptions, BatchRows: *batchRows})
+	case "parquet":
+		err = parquet.WriteSheet(output, scanner, sheetId, parquet.TOptions{TTypedRowOptions: rowOptions, RowGroupRows: *batchRows})
+	default:
+		err = jsonl.WriteSheet(output, scanner, sheetId, rowOptions)
+	}
+	if nil != err {
+		return err
+	}
+	if "" != *outputName {
+		return output.Close()
+	}
+	return nil
+}
+
+func runDetect(args []string) error {
+	flags := flag.NewFlagSet("detect", flag.ExitOnError)
+	err, fileName := parseFileArg(flags, args)
+	if nil != err {
+		return err
+	}
+	err, detection := tablescanner.DetectContentType(fileName)
+	if nil != err {
+		return err
+	}
+	fmt.Printf("type: %s\n", workbookTypeName(detection.Type))
+	fmt.Printf("confidence: %d\n", detection.Confidence)
+	fmt.Printf("reason: %s\n", detection.Reason)
+	fmt.Printf("kind: %d\n", detection.Kind)
+	if "" != detection.WorkbookPart {
+		fmt.Printf("workbook part: %s\n", detection.WorkbookPart)
+	}
+	fmt.Printf("text encoding: %d\n", detection.TextEncoding)
+	if len(detection.BOMPresent) > 0 {
+		fmt.Printf("bom: % X\n", detection.BOMPresent)
+	}
+	fmt.Printf("encrypted: %t\n", detection.Encrypted)
+	fmt.Printf("macros: %t\n", detection.MacrosPresent)
+	return nil
+}
+
+func workbookTypeName(bookType tablescanner.TExcelWorkbookType) string {
+	switch bookType {
+	case tablescanner.TypeExcelWorkbookXLSX:
+		return "xlsx"
+	case tablescanner.TypeExcelWorkbookXLS:
+		return "xls"
+	case tablescanner.TypeExcelWorkbookXML:
+		return "xml"
+	case tablescanner.TypeExcelWorkbookSingleHTML:
+		return "html"
+	case tablescanner.TypeExcelWorkbookXLSB:
+		return "xlsb"
+	case tablescanner.TypeExcelWorkbookODS:
+		return "ods"
+	case tablescanner.TypeExcelWorkbookFODS:
+		return "fods"
+	}
+	return "unknown"
+}
+
+func runProfile(args []string) error {
+	options := &tOpenOptions{}
+	flags := newFlagSet("profile", options, true)
+	profileOptions := tablescanner.TProfileOptions{}
+	flags.IntVar(&profileOptions.HeaderRows, "header-rows", -1, "leading header rows, -1 detects them")
+	flags.IntVar(&profileOptions.MaxRows, "max-rows", 0, "profiled rows limit, 0 means whole sheet")
+	flags.IntVar(&profileOptions.Samples, "samples", 0, "sample values per column, 0 means 5")
+	err, fileName := parseFileArg(flags, args)
+	if nil != err {
+		return err
+	}
+	err, scanner := openDocument(fileName, options)
+	if nil != err {
+		return err
+	}
+	defer scanner.Close()
+	err, profile := tablescanner.ProfileSheet(scanner, scanner.GetCurrentSheetId(), profileOptions)
+	if nil != err {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(profile)
+}
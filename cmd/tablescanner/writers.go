@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type iRowWriter interface {
+	WriteRow(values []string) error
+	Flush() error
+}
+
+type tCSVWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(output io.Writer, comma rune) *tCSVWriter {
+	writer := csv.NewWriter(output)
+	writer.Comma = comma
+	return &tCSVWriter{writer: writer}
+}
+
+func (w *tCSVWriter) WriteRow(values []string) error {
+	return w.writer.Write(values)
+}
+
+func (w *tCSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// tJSONLinesWriter writes every row as json array of strings
+type tJSONLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *tJSONLinesWriter) WriteRow(values []string) error {
+	if nil == values {
+		values = []string{}
+	}
+	return w.encoder.Encode(values)
+}
+
+func (w *tJSONLinesWriter) Flush() error {
+	return nil
+}
+
+// tMarkdownWriter keeps rows until Flush() because table width is known after the last row,
+// the first row becomes table header
+type tMarkdownWriter struct {
+	output io.Writer
+	rows   [][]string
+	width  int
+}
+
+func (w *tMarkdownWriter) WriteRow(values []string) error {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = escapeMarkdownCell(value)
+	}
+	if len(row) > w.width {
+		w.width = len(row)
+	}
+	w.rows = append(w.rows, row)
+	return nil
+}
+
+func (w *tMarkdownWriter) Flush() error {
+	if 0 == len(w.rows) || 0 == w.width {
+		return nil
+	}
+	separator := make([]string, w.width)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	for i, row := range w.rows {
+		if err := w.writeLine(row); nil != err {
+			return err
+		}
+		if 0 == i {
+			if err := w.writeLine(separator); nil != err {
+				return err
+			}
+		}
+	}
+	w.rows = nil
+	return nil
+}
+
+func (w *tMarkdownWriter) writeLine(row []string) error {
+	cells := make([]string, w.width)
+	copy(cells, row)
+	_, err := io.WriteString(w.output, "| "+strings.Join(cells, " | ")+" |\n")
+	return err
+}
+
+func escapeMarkdownCell(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	value = strings.Replace(value, "|", "\\|", -1)
+	value = strings.Replace(value, "\r\n", "<br>", -1)
+	return strings.Replace(value, "\n", "<br>", -1)
+}
@@ -0,0 +1,211 @@
+// Package sqldriver registers read-only database/sql driver "tablescanner" which exposes sheets of workbook
+// as tables, data source name is file path with optional query parameters:
+//
+//	db, err := sql.Open("tablescanner", "report.xlsx?header=1")
+//	rows, err := db.Query(`SELECT "Name", Amount FROM "Sheet1" WHERE Amount > ? LIMIT 10`, 100)
+//
+// parameters are header (leading header rows, -1 detects them and is default), sample (rows used to infer
+// column types, default 1000, -1 profiles whole sheet), password and i18n; column names come from header rows,
+// columns without name are named by letters like "A"; query fails on cell which does not fit type inferred
+// from sample rows
+//
+// supported statement is SELECT with column list or *, single sheet in FROM, optional WHERE and LIMIT/OFFSET;
+// WHERE supports AND, OR, NOT, comparisons, IS [NOT] NULL, [NOT] LIKE, [NOT] IN, [NOT] BETWEEN,
+// placeholders ? and $N; WHERE is pushed into typed row reader, so columns used by it are converted first and
+// other selected columns are converted only for matching rows; every query reads the file again from the beginning
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+const DriverName = "tablescanner"
+
+func init() {
+	sql.Register(DriverName, &TDriver{})
+}
+
+type TDriver struct{}
+
+// tDataSource is parsed data source name
+type tDataSource struct {
+	fileName   string
+	headerRows int
+	sampleRows int
+	password   string
+	i18n       string
+}
+
+type tConn struct {
+	source tDataSource
+}
+
+type tStmt struct {
+	conn  *tConn
+	query *tQuery
+}
+
+func parseDataSource(name string) (error, tDataSource) {
+	source := tDataSource{fileName: name, headerRows: -1}
+	position := strings.IndexByte(name, '?')
+	if position < 0 {
+		return nil, source
+	}
+	source.fileName = name[:position]
+	values, err := url.ParseQuery(name[position+1:])
+	if nil != err {
+		return fmt.Errorf("invalid data source parameters: %w", err), source
+	}
+	for key, list := range values {
+		value := list[len(list)-1]
+		switch key {
+		case "header":
+			source.headerRows, err = strconv.Atoi(value)
+		case "sample":
+			source.sampleRows, err = strconv.Atoi(value)
+		case "password":
+			source.password = value
+		case "i18n":
+			source.i18n = value
+		default:
+			return fmt.Errorf("unknown data source parameter %q", key), source
+		}
+		if nil != err {
+			return fmt.Errorf("invalid data source parameter %s=%q", key, value), source
+		}
+	}
+	return nil, source
+}
+
+// Open checks data source name, file itself is opened by every query
+func (d *TDriver) Open(name string) (driver.Conn, error) {
+	err, source := parseDataSource(name)
+	if nil != err {
+		return nil, err
+	}
+	return &tConn{source: source}, nil
+}
+
+func (conn *tConn) Prepare(query string) (driver.Stmt, error) {
+	err, parsed := parseQuery(query)
+	if nil != err {
+		return nil, err
+	}
+	return &tStmt{conn: conn, query: parsed}, nil
+}
+
+func (conn *tConn) Close() error {
+	return nil
+}
+
+func (conn *tConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported by %s driver", DriverName)
+}
+
+// openDocument opens file of data source and resolves sheet by exact name, then by case-insensitive name
+func (conn *tConn) openDocument(table string) (error, tablescanner.ITableDocumentScanner, int) {
+	var err error
+	var scanner tablescanner.ITableDocumentScanner
+	if "" != conn.source.password {
+		err, scanner = tablescanner.NewTableStreamWithPassword(conn.source.fileName, conn.source.password)
+	} else {
+		err, scanner = tablescanner.NewTableStream(conn.source.fileName)
+	}
+	if nil != err {
+		return err, nil, -1
+	}
+	if "" != conn.source.i18n {
+		if err = scanner.SetI18n(conn.source.i18n); nil != err {
+			_ = scanner.Close()
+			return err, nil, -1
+		}
+	}
+	err, sheetId := tablescanner.FindSheet(scanner.GetSheets(), table, tablescanner.SheetMatchExact)
+	if nil != err {
+		err, sheetId = tablescanner.FindSheet(scanner.GetSheets(), table, tablescanner.SheetMatchIgnoreCase)
+	}
+	if nil != err {
+		_ = scanner.Close()
+		return fmt.Errorf("no such table: %s", table), nil, -1
+	}
+	return nil, scanner, sheetId
+}
+
+func (stmt *tStmt) Close() error {
+	return nil
+}
+
+// NumInput returns the highest placeholder number of statement
+func (stmt *tStmt) NumInput() int {
+	return stmt.query.numInput
+}
+
+func (stmt *tStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("only SELECT statements are supported by %s driver", DriverName)
+}
+
+func (stmt *tStmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return stmt.QueryContext(context.Background(), named)
+}
+
+// QueryContext opens file and streams rows, cancelled context stops scanning at the next row
+func (stmt *tStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	err, scanner, sheetId := stmt.conn.openDocument(stmt.query.table)
+	if nil != err {
+		return nil, err
+	}
+	rows, err := stmt.newRows(ctx, scanner, sheetId, args)
+	if nil != err {
+		_ = scanner.Close()
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (stmt *tStmt) newRows(ctx context.Context, scanner tablescanner.ITableDocumentScanner, sheetId int, args []driver.NamedValue) (*tRows, error) {
+	options := tablescanner.TTypedRowOptions{HeaderRows: stmt.conn.source.headerRows, SampleRows: stmt.conn.source.sampleRows}
+	err, reader := tablescanner.NewTypedRowReader(scanner, sheetId, options)
+	if nil != err {
+		return nil, err
+	}
+	rows := &tRows{ctx: ctx, scanner: scanner, reader: reader, limit: stmt.query.limit, offset: stmt.query.offset}
+	columns := reader.Columns()
+	if nil == stmt.query.columns {
+		for i, column := range columns {
+			rows.columns = append(rows.columns, i)
+			rows.names = append(rows.names, column.Name)
+		}
+	}
+	for _, selected := range stmt.query.columns {
+		err, column := findColumn(columns, selected.name)
+		if nil != err {
+			return nil, err
+		}
+		rows.columns = append(rows.columns, column)
+		rows.names = append(rows.names, selected.alias)
+	}
+	binder := &binder{columns: columns, args: args}
+	err, where := binder.bind(stmt.query.where)
+	if nil != err {
+		return nil, err
+	}
+	if nil != where {
+		reader.SetFilter(binder.used, func(row []interface{}) bool {
+			return truthTrue == eval(where, row)
+		})
+	}
+	reader.SelectColumns(rows.columns)
+	return rows, nil
+}
@@ -0,0 +1,485 @@
+package sqldriver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tTokenKind byte
+
+const (
+	tokenEOF tTokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPlaceholder
+	tokenSymbol
+)
+
+type tToken struct {
+	kind tTokenKind
+	text string // identifier, string value, number, placeholder index or symbol
+	pos  int
+}
+
+// tQuery is parsed statement: SELECT columns FROM table [WHERE expr] [LIMIT n [OFFSET m]]
+type tQuery struct {
+	columns  []tSelectColumn // nil selects all columns
+	table    string
+	where    tExpr
+	limit    int64 // negative means no limit
+	offset   int64
+	numInput int
+}
+
+type tSelectColumn struct {
+	name  string
+	alias string
+}
+
+// tTerm is column reference, literal value or placeholder of expression
+type tTerm struct {
+	column      string
+	isColumn    bool
+	value       interface{} // nil, int64, float64, bool or string
+	placeholder int         // 1-based argument index, 0 for columns and literals
+}
+
+type tExpr interface{}
+
+type tLogicalExpr struct {
+	and         bool
+	left, right tExpr
+}
+
+type tNotExpr struct {
+	expr tExpr
+}
+
+type tCompareExpr struct {
+	op          string // =, <>, <, <=, >, >=
+	left, right tTerm
+}
+
+type tIsNullExpr struct {
+	term tTerm
+	not  bool
+}
+
+type tLikeExpr struct {
+	term, pattern tTerm
+	not           bool
+}
+
+type tInExpr struct {
+	term   tTerm
+	values []tTerm
+	not    bool
+}
+
+type tParser struct {
+	tokens          []tToken
+	pos             int
+	nextPlaceholder int
+	numInput        int
+}
+
+func tokenize(query string) (error, []tToken) {
+	var tokens []tToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case '-' == r && i+1 < len(runes) && '-' == runes[i+1]:
+			for i < len(runes) && '\n' != runes[i] {
+				i++
+			}
+		case unicode.IsLetter(r) || '_' == r:
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || '_' == runes[i]) {
+				i++
+			}
+			tokens = append(tokens, tToken{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r) || ('.' == r && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || '.' == runes[i]) {
+				i++
+			}
+			if i < len(runes) && ('e' == runes[i] || 'E' == runes[i]) {
+				i++
+				if i < len(runes) && ('+' == runes[i] || '-' == runes[i]) {
+					i++
+				}
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, tToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case '\'' == r || '"' == r || '`' == r || '[' == r:
+			closing := r
+			kind := tokenQuotedIdent
+			if '[' == r {
+				closing = ']'
+			}
+			if '\'' == r {
+				kind = tokenString
+			}
+			var text []rune
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return fmt.Errorf("unterminated %c at position %d", r, start), nil
+				}
+				if closing == runes[i] {
+					// doubled quote is escaped quote
+					if i+1 < len(runes) && closing == runes[i+1] && '[' != r {
+						text = append(text, closing)
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				text = append(text, runes[i])
+			}
+			tokens = append(tokens, tToken{kind: kind, text: string(text), pos: start})
+		case '?' == r:
+			i++
+			tokens = append(tokens, tToken{kind: tokenPlaceholder, pos: start})
+		case '$' == r:
+			for i++; i < len(runes) && unicode.IsDigit(runes[i]); i++ {
+			}
+			if i == start+1 {
+				return fmt.Errorf("placeholder number expected at position %d", start), nil
+			}
+			tokens = append(tokens, tToken{kind: tokenPlaceholder, text: string(runes[start+1 : i]), pos: start})
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) {
+				switch string(runes[i : i+2]) {
+				case "<=", ">=", "<>", "!=", "==":
+					symbol = string(runes[i : i+2])
+				}
+			}
+			if !strings.Contains("=<>!(),*;-", symbol[:1]) || "!" == symbol {
+				return fmt.Errorf("unexpected %q at position %d", symbol, start), nil
+			}
+			i += len([]rune(symbol))
+			tokens = append(tokens, tToken{kind: tokenSymbol, text: symbol, pos: start})
+		}
+	}
+	return nil, append(tokens, tToken{kind: tokenEOF, pos: len(runes)})
+}
+
+// parseQuery parses SELECT statement, keywords are case-insensitive
+func parseQuery(query string) (error, *tQuery) {
+	err, tokens := tokenize(query)
+	if nil != err {
+		return err, nil
+	}
+	parser := &tParser{tokens: tokens}
+	err, parsed := parser.parseSelect()
+	if nil != err {
+		return err, nil
+	}
+	parsed.numInput = parser.numInput
+	return nil, parsed
+}
+
+func (parser *tParser) peek() tToken {
+	return parser.tokens[parser.pos]
+}
+
+func (parser *tParser) next() tToken {
+	token := parser.tokens[parser.pos]
+	if tokenEOF != token.kind {
+		parser.pos++
+	}
+	return token
+}
+
+func (parser *tParser) isKeyword(keyword string) bool {
+	token := parser.peek()
+	return tokenIdent == token.kind && strings.EqualFold(token.text, keyword)
+}
+
+func (parser *tParser) acceptKeyword(keyword string) bool {
+	if parser.isKeyword(keyword) {
+		parser.pos++
+		return true
+	}
+	return false
+}
+
+func (parser *tParser) acceptSymbol(symbol string) bool {
+	token := parser.peek()
+	if tokenSymbol == token.kind && symbol == token.text {
+		parser.pos++
+		return true
+	}
+	return false
+}
+
+func (parser *tParser) unexpected(expected string) error {
+	token := parser.peek()
+	if tokenEOF == token.kind {
+		return fmt.Errorf("%s expected at end of query", expected)
+	}
+	return fmt.Errorf("%s expected at position %d", expected, token.pos)
+}
+
+func (parser *tParser) expectKeyword(keyword string) error {
+	if !parser.acceptKeyword(keyword) {
+		return parser.unexpected(keyword)
+	}
+	return nil
+}
+
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true,
+	"LIKE": true, "IN": true, "BETWEEN": true, "LIMIT": true, "OFFSET": true, "AS": true, "TRUE": true, "FALSE": true,
+	"ORDER": true, "GROUP": true, "JOIN": true,
+}
+
+// parseIdentifier accepts bare identifier which is not reserved word or quoted identifier
+func (parser *tParser) parseIdentifier(expected string) (error, string) {
+	token := parser.peek()
+	if tokenQuotedIdent == token.kind || (tokenIdent == token.kind && !reservedWords[strings.ToUpper(token.text)]) {
+		parser.pos++
+		return nil, token.text
+	}
+	return parser.unexpected(expected), ""
+}
+
+func (parser *tParser) parseSelect() (error, *tQuery) {
+	query := &tQuery{limit: -1}
+	if err := parser.expectKeyword("SELECT"); nil != err {
+		return err, nil
+	}
+	if !parser.acceptSymbol("*") {
+		for {
+			err, name := parser.parseIdentifier("column name")
+			if nil != err {
+				return err, nil
+			}
+			column := tSelectColumn{name: name, alias: name}
+			if parser.acceptKeyword("AS") || tokenQuotedIdent == parser.peek().kind || (tokenIdent == parser.peek().kind && !reservedWords[strings.ToUpper(parser.peek().text)]) {
+				if err, column.alias = parser.parseIdentifier("column alias"); nil != err {
+					return err, nil
+				}
+			}
+			query.columns = append(query.columns, column)
+			if !parser.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if err := parser.expectKeyword("FROM"); nil != err {
+		return err, nil
+	}
+	err, table := parser.parseIdentifier("sheet name")
+	if nil != err {
+		return err, nil
+	}
+	query.table = table
+	if parser.acceptKeyword("WHERE") {
+		if err, query.where = parser.parseOr(); nil != err {
+			return err, nil
+		}
+	}
+	if parser.isKeyword("ORDER") || parser.isKeyword("GROUP") || parser.isKeyword("JOIN") || parser.acceptSymbol(",") {
+		return fmt.Errorf("only single sheet queries with WHERE and LIMIT clauses are supported"), nil
+	}
+	if parser.acceptKeyword("LIMIT") {
+		if err, query.limit = parser.parseCount("LIMIT"); nil != err {
+			return err, nil
+		}
+		if parser.acceptKeyword("OFFSET") {
+			if err, query.offset = parser.parseCount("OFFSET"); nil != err {
+				return err, nil
+			}
+		}
+	}
+	parser.acceptSymbol(";")
+	if tokenEOF != parser.peek().kind {
+		return parser.unexpected("end of query"), nil
+	}
+	return nil, query
+}
+
+func (parser *tParser) parseCount(clause string) (error, int64) {
+	token := parser.next()
+	count, err := strconv.ParseInt(token.text, 10, 64)
+	if tokenNumber != token.kind || nil != err || count < 0 {
+		return fmt.Errorf("non-negative integer expected after %s at position %d", clause, token.pos), 0
+	}
+	return nil, count
+}
+
+func (parser *tParser) parseOr() (error, tExpr) {
+	err, left := parser.parseAnd()
+	for nil == err && parser.acceptKeyword("OR") {
+		var right tExpr
+		if err, right = parser.parseAnd(); nil == err {
+			left = &tLogicalExpr{and: false, left: left, right: right}
+		}
+	}
+	return err, left
+}
+
+func (parser *tParser) parseAnd() (error, tExpr) {
+	err, left := parser.parseNot()
+	for nil == err && parser.acceptKeyword("AND") {
+		var right tExpr
+		if err, right = parser.parseNot(); nil == err {
+			left = &tLogicalExpr{and: true, left: left, right: right}
+		}
+	}
+	return err, left
+}
+
+func (parser *tParser) parseNot() (error, tExpr) {
+	if parser.acceptKeyword("NOT") {
+		err, expr := parser.parseNot()
+		return err, &tNotExpr{expr: expr}
+	}
+	return parser.parsePredicate()
+}
+
+func (parser *tParser) parsePredicate() (error, tExpr) {
+	if parser.acceptSymbol("(") {
+		err, expr := parser.parseOr()
+		if nil == err && !parser.acceptSymbol(")") {
+			err = parser.unexpected(")")
+		}
+		return err, expr
+	}
+	err, left := parser.parseTerm()
+	if nil != err {
+		return err, nil
+	}
+	if token := parser.peek(); tokenSymbol == token.kind {
+		op := token.text
+		switch op {
+		case "=", "==", "<>", "!=", "<", "<=", ">", ">=":
+			parser.pos++
+			err, right := parser.parseTerm()
+			if "==" == op {
+				op = "="
+			} else if "!=" == op {
+				op = "<>"
+			}
+			return err, &tCompareExpr{op: op, left: left, right: right}
+		}
+	}
+	if parser.acceptKeyword("IS") {
+		not := parser.acceptKeyword("NOT")
+		if err = parser.expectKeyword("NULL"); nil != err {
+			return err, nil
+		}
+		return nil, &tIsNullExpr{term: left, not: not}
+	}
+	not := parser.acceptKeyword("NOT")
+	switch {
+	case parser.acceptKeyword("LIKE"):
+		err, pattern := parser.parseTerm()
+		return err, &tLikeExpr{term: left, pattern: pattern, not: not}
+	case parser.acceptKeyword("IN"):
+		if !parser.acceptSymbol("(") {
+			return parser.unexpected("("), nil
+		}
+		in := &tInExpr{term: left, not: not}
+		for {
+			err, value := parser.parseTerm()
+			if nil != err {
+				return err, nil
+			}
+			in.values = append(in.values, value)
+			if !parser.acceptSymbol(",") {
+				break
+			}
+		}
+		if !parser.acceptSymbol(")") {
+			return parser.unexpected(")"), nil
+		}
+		return nil, in
+	case parser.acceptKeyword("BETWEEN"):
+		err, low := parser.parseTerm()
+		if nil == err {
+			err = parser.expectKeyword("AND")
+		}
+		if nil != err {
+			return err, nil
+		}
+		err, high := parser.parseTerm()
+		var expr tExpr = &tLogicalExpr{and: true, left: &tCompareExpr{op: ">=", left: left, right: low}, right: &tCompareExpr{op: "<=", left: left, right: high}}
+		if not {
+			expr = &tNotExpr{expr: expr}
+		}
+		return err, expr
+	case not:
+		return parser.unexpected("LIKE, IN or BETWEEN"), nil
+	}
+	// bare term is true when it equals TRUE, like boolean column in "WHERE active"
+	return nil, &tCompareExpr{op: "=", left: left, right: tTerm{value: true}}
+}
+
+func (parser *tParser) parseTerm() (error, tTerm) {
+	token := parser.peek()
+	switch token.kind {
+	case tokenQuotedIdent:
+		parser.pos++
+		return nil, tTerm{column: token.text, isColumn: true}
+	case tokenString:
+		parser.pos++
+		return nil, tTerm{value: token.text}
+	case tokenNumber:
+		parser.pos++
+		return parseNumberTerm(token.text, token.pos)
+	case tokenPlaceholder:
+		parser.pos++
+		index := parser.nextPlaceholder + 1
+		if "" != token.text {
+			var err error
+			if index, err = strconv.Atoi(token.text); nil != err || index < 1 {
+				return fmt.Errorf("invalid placeholder at position %d", token.pos), tTerm{}
+			}
+		}
+		parser.nextPlaceholder = index
+		if index > parser.numInput {
+			parser.numInput = index
+		}
+		return nil, tTerm{placeholder: index}
+	case tokenSymbol:
+		if "-" == token.text && tokenNumber == parser.tokens[parser.pos+1].kind {
+			parser.pos += 2
+			return parseNumberTerm("-"+parser.tokens[parser.pos-1].text, token.pos)
+		}
+	case tokenIdent:
+		switch strings.ToUpper(token.text) {
+		case "NULL":
+			parser.pos++
+			return nil, tTerm{}
+		case "TRUE", "FALSE":
+			parser.pos++
+			return nil, tTerm{value: strings.EqualFold(token.text, "TRUE")}
+		}
+		err, name := parser.parseIdentifier("column name or value")
+		return err, tTerm{column: name, isColumn: true}
+	}
+	return parser.unexpected("column name or value"), tTerm{}
+}
+
+func parseNumberTerm(text string, pos int) (error, tTerm) {
+	if integer, err := strconv.ParseInt(text, 10, 64); nil == err {
+		return nil, tTerm{value: integer}
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if nil != err {
+		return fmt.Errorf("invalid number %q at position %d", text, pos), tTerm{}
+	}
+	return nil, tTerm{value: number}
+}
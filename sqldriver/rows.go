@@ -0,0 +1,426 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// tRows streams sheet rows filtered by typed row reader, cells of columns not used by query are not converted
+type tRows struct {
+	ctx      context.Context
+	scanner  tablescanner.ITableDocumentScanner
+	reader   *tablescanner.TTypedRowReader
+	names    []string
+	columns  []int // projected 0-based sheet columns
+	limit    int64
+	offset   int64
+	returned int64
+	skipped  int64
+}
+
+// tBoundTerm is tTerm with resolved column index and placeholder value
+type tBoundTerm struct {
+	column int // -1 for values
+	value  interface{}
+}
+
+type tBoundCompare struct {
+	op          string
+	left, right tBoundTerm
+}
+
+type tBoundIsNull struct {
+	term tBoundTerm
+	not  bool
+}
+
+type tBoundLike struct {
+	term, pattern tBoundTerm
+	compiled      *regexp.Regexp // nil when pattern is column
+	not           bool
+}
+
+type tBoundIn struct {
+	term   tBoundTerm
+	values []tBoundTerm
+	not    bool
+}
+
+// tTruth is three-valued logic of SQL where comparisons with NULL are unknown
+type tTruth byte
+
+const (
+	truthFalse tTruth = iota
+	truthTrue
+	truthUnknown
+)
+
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339Nano}
+
+// findColumn resolves column by exact name, then by case-insensitive name
+func findColumn(columns []tablescanner.TTypedColumn, name string) (error, int) {
+	for i, column := range columns {
+		if column.Name == name {
+			return nil, i
+		}
+	}
+	for i, column := range columns {
+		if strings.EqualFold(column.Name, name) {
+			return nil, i
+		}
+	}
+	return fmt.Errorf("no such column: %s", name), -1
+}
+
+// binder resolves columns and placeholders of parsed expression and collects used columns
+type binder struct {
+	columns []tablescanner.TTypedColumn
+	args    []driver.NamedValue
+	used    []int
+}
+
+func (binder *binder) bindTerm(term tTerm) (error, tBoundTerm) {
+	if term.isColumn {
+		err, column := findColumn(binder.columns, term.column)
+		binder.used = append(binder.used, column)
+		return err, tBoundTerm{column: column}
+	}
+	if term.placeholder > 0 {
+		for _, arg := range binder.args {
+			if arg.Ordinal == term.placeholder {
+				value := arg.Value
+				if bytes, ok := value.([]byte); ok {
+					value = string(bytes)
+				}
+				return nil, tBoundTerm{column: -1, value: value}
+			}
+		}
+		return fmt.Errorf("missing argument $%d", term.placeholder), tBoundTerm{}
+	}
+	return nil, tBoundTerm{column: -1, value: term.value}
+}
+
+func (binder *binder) bind(expr tExpr) (error, tExpr) {
+	var err error
+	switch typed := expr.(type) {
+	case nil:
+		return nil, nil
+	case *tLogicalExpr:
+		bound := &tLogicalExpr{and: typed.and}
+		if err, bound.left = binder.bind(typed.left); nil == err {
+			err, bound.right = binder.bind(typed.right)
+		}
+		return err, bound
+	case *tNotExpr:
+		bound := &tNotExpr{}
+		err, bound.expr = binder.bind(typed.expr)
+		return err, bound
+	case *tCompareExpr:
+		bound := &tBoundCompare{op: typed.op}
+		if err, bound.left = binder.bindTerm(typed.left); nil == err {
+			err, bound.right = binder.bindTerm(typed.right)
+		}
+		return err, bound
+	case *tIsNullExpr:
+		bound := &tBoundIsNull{not: typed.not}
+		err, bound.term = binder.bindTerm(typed.term)
+		return err, bound
+	case *tLikeExpr:
+		bound := &tBoundLike{not: typed.not}
+		if err, bound.term = binder.bindTerm(typed.term); nil == err {
+			err, bound.pattern = binder.bindTerm(typed.pattern)
+		}
+		if nil == err && bound.pattern.column < 0 {
+			if pattern, ok := bound.pattern.value.(string); ok {
+				bound.compiled = compileLike(pattern)
+			}
+		}
+		return err, bound
+	case *tInExpr:
+		bound := &tBoundIn{not: typed.not, values: make([]tBoundTerm, len(typed.values))}
+		err, bound.term = binder.bindTerm(typed.term)
+		for i := 0; nil == err && i < len(typed.values); i++ {
+			err, bound.values[i] = binder.bindTerm(typed.values[i])
+		}
+		return err, bound
+	}
+	return fmt.Errorf("unsupported expression %T", expr), nil
+}
+
+// compileLike converts LIKE pattern to case-insensitive regexp, % matches any string and _ matches any character
+func compileLike(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+func termValue(term tBoundTerm, row []interface{}) interface{} {
+	if term.column < 0 {
+		return term.value
+	}
+	return row[term.column]
+}
+
+func not(truth tTruth) tTruth {
+	switch truth {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+func truthOf(value bool) tTruth {
+	if value {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func eval(expr tExpr, row []interface{}) tTruth {
+	switch typed := expr.(type) {
+	case *tLogicalExpr:
+		left := eval(typed.left, row)
+		if typed.and && truthFalse == left || !typed.and && truthTrue == left {
+			return left
+		}
+		right := eval(typed.right, row)
+		if left == right || (typed.and && truthFalse == right) || (!typed.and && truthTrue == right) {
+			return right
+		}
+		return truthUnknown
+	case *tNotExpr:
+		return not(eval(typed.expr, row))
+	case *tBoundCompare:
+		ok, result := compareValues(termValue(typed.left, row), termValue(typed.right, row))
+		if !ok {
+			return truthUnknown
+		}
+		switch typed.op {
+		case "=":
+			return truthOf(0 == result)
+		case "<>":
+			return truthOf(0 != result)
+		case "<":
+			return truthOf(result < 0)
+		case "<=":
+			return truthOf(result <= 0)
+		case ">":
+			return truthOf(result > 0)
+		}
+		return truthOf(result >= 0)
+	case *tBoundIsNull:
+		return truthOf((nil == termValue(typed.term, row)) != typed.not)
+	case *tBoundLike:
+		value := termValue(typed.term, row)
+		pattern := termValue(typed.pattern, row)
+		if nil == value || nil == pattern {
+			return truthUnknown
+		}
+		compiled := typed.compiled
+		if nil == compiled {
+			compiled = compileLike(valueText(pattern))
+		}
+		return truthOf(compiled.MatchString(valueText(value)) != typed.not)
+	case *tBoundIn:
+		value := termValue(typed.term, row)
+		result := truthFalse
+		for _, term := range typed.values {
+			ok, compared := compareValues(value, termValue(term, row))
+			if !ok {
+				result = truthUnknown
+			} else if 0 == compared {
+				result = truthTrue
+				break
+			}
+		}
+		if typed.not {
+			return not(result)
+		}
+		return result
+	}
+	return truthUnknown
+}
+
+// valueText formats value for LIKE matching
+func valueText(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	case time.Time:
+		if typed.Equal(typed.Truncate(24 * time.Hour)) {
+			return typed.Format("2006-01-02")
+		}
+		return typed.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(value)
+}
+
+// compareValues compares numbers, strings, bools and dates, string operand is converted to type of the other one;
+// ok is false for NULL and incomparable operands
+func compareValues(left interface{}, right interface{}) (bool, int) {
+	if nil == left || nil == right {
+		return false, 0
+	}
+	if text, isText := left.(string); isText {
+		if _, rightIsText := right.(string); !rightIsText {
+			ok, result := compareValues(right, text)
+			return ok, -result
+		}
+	}
+	switch typed := left.(type) {
+	case int64:
+		switch other := right.(type) {
+		case int64:
+			return true, compareOrdered(typed < other, typed > other)
+		case float64:
+			return true, compareOrdered(float64(typed) < other, float64(typed) > other)
+		case string:
+			if integer, err := strconv.ParseInt(strings.TrimSpace(other), 10, 64); nil == err {
+				return true, compareOrdered(typed < integer, typed > integer)
+			}
+			number, err := strconv.ParseFloat(strings.TrimSpace(other), 64)
+			return nil == err, compareOrdered(float64(typed) < number, float64(typed) > number)
+		}
+	case float64:
+		switch other := right.(type) {
+		case int64:
+			return true, compareOrdered(typed < float64(other), typed > float64(other))
+		case float64:
+			return true, compareOrdered(typed < other, typed > other)
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(other), 64)
+			return nil == err, compareOrdered(typed < number, typed > number)
+		}
+	case bool:
+		other, ok := right.(bool)
+		if text, isText := right.(string); isText {
+			var err error
+			other, err = strconv.ParseBool(strings.TrimSpace(text))
+			ok = nil == err
+		}
+		return ok, compareOrdered(!typed && other, typed && !other)
+	case time.Time:
+		other, ok := right.(time.Time)
+		if text, isText := right.(string); isText {
+			for _, layout := range dateLayouts {
+				if parsed, err := time.Parse(layout, strings.TrimSpace(text)); nil == err {
+					other, ok = parsed, true
+					break
+				}
+			}
+		}
+		return ok, compareOrdered(typed.Before(other), typed.After(other))
+	case string:
+		other, ok := right.(string)
+		return ok, strings.Compare(typed, other)
+	}
+	return false, 0
+}
+
+func compareOrdered(less bool, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
+
+func (rows *tRows) Columns() []string {
+	return rows.names
+}
+
+func (rows *tRows) Close() error {
+	return rows.scanner.Close()
+}
+
+func (rows *tRows) Next(dest []driver.Value) error {
+	for {
+		if rows.limit >= 0 && rows.returned >= rows.limit {
+			return io.EOF
+		}
+		if err := rows.ctx.Err(); nil != err {
+			return err
+		}
+		err, row := rows.reader.Next()
+		if nil != err {
+			return err
+		}
+		if rows.skipped < rows.offset {
+			rows.skipped++
+			continue
+		}
+		for i, column := range rows.columns {
+			dest[i] = row[column]
+		}
+		rows.returned++
+		return nil
+	}
+}
+
+func (rows *tRows) columnType(index int) tablescanner.TColumnType {
+	return rows.reader.Columns()[rows.columns[index]].Type
+}
+
+// ColumnTypeDatabaseTypeName returns INTEGER, REAL, BOOLEAN, TIMESTAMP or TEXT
+func (rows *tRows) ColumnTypeDatabaseTypeName(index int) string {
+	switch rows.columnType(index) {
+	case tablescanner.ColumnTypeInt:
+		return "INTEGER"
+	case tablescanner.ColumnTypeFloat:
+		return "REAL"
+	case tablescanner.ColumnTypeBool:
+		return "BOOLEAN"
+	case tablescanner.ColumnTypeDate:
+		return "TIMESTAMP"
+	}
+	return "TEXT"
+}
+
+func (rows *tRows) ColumnTypeScanType(index int) reflect.Type {
+	switch rows.columnType(index) {
+	case tablescanner.ColumnTypeInt:
+		return reflect.TypeOf(int64(0))
+	case tablescanner.ColumnTypeFloat:
+		return reflect.TypeOf(float64(0))
+	case tablescanner.ColumnTypeBool:
+		return reflect.TypeOf(false)
+	case tablescanner.ColumnTypeDate:
+		return reflect.TypeOf(time.Time{})
+	}
+	return reflect.TypeOf("")
+}
+
+// ColumnTypeNullable reports every column as nullable because empty and error cells are NULL
+func (rows *tRows) ColumnTypeNullable(index int) (bool, bool) {
+	return true, true
+}
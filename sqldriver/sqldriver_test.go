@@ -0,0 +1,222 @@
+package sqldriver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+var testColumns = []tablescanner.TTypedColumn{
+	{Name: "id", Type: tablescanner.ColumnTypeInt},
+	{Name: "name", Type: tablescanner.ColumnTypeString},
+	{Name: "score", Type: tablescanner.ColumnTypeFloat},
+	{Name: "active", Type: tablescanner.ColumnTypeBool},
+}
+
+// testEval parses query, binds its WHERE clause to testColumns and evaluates it on row
+func testEval(t *testing.T, query string, row []interface{}, args ...interface{}) tTruth {
+	err, parsed := parseQuery(query)
+	if nil != err {
+		t.Fatalf("%s: %s", query, err)
+	}
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	binder := &binder{columns: testColumns, args: named}
+	err, where := binder.bind(parsed.where)
+	if nil != err {
+		t.Fatalf("%s: %s", query, err)
+	}
+	return eval(where, row)
+}
+
+func TestEval(t *testing.T) {
+	full := []interface{}{int64(3), "Carol", 30.5, true}
+	nulls := []interface{}{int64(4), nil, nil, nil}
+	tests := []struct {
+		where    string
+		row      []interface{}
+		expected tTruth
+	}{
+		{"id = 3", full, truthTrue},
+		{"id <> 3", full, truthFalse},
+		{"score > 30", full, truthTrue},
+		{"score >= '30.5'", full, truthTrue},
+		{"active", full, truthTrue},
+		{"NOT active", full, truthFalse},
+		// comparison with NULL is unknown, NOT keeps it unknown
+		{"score > 30", nulls, truthUnknown},
+		{"NOT score > 30", nulls, truthUnknown},
+		{"score = NULL", full, truthUnknown},
+		{"score IS NULL", nulls, truthTrue},
+		{"score IS NOT NULL", nulls, truthFalse},
+		// AND is false and OR is true when either side decides it
+		{"score > 30 AND id = 3", nulls, truthFalse},
+		{"score > 30 AND id = 4", nulls, truthUnknown},
+		{"score > 30 OR id = 4", nulls, truthTrue},
+		{"score > 30 OR id = 3", nulls, truthUnknown},
+		{"NOT (score > 30 OR id = 3)", nulls, truthUnknown},
+		{"score BETWEEN 30 AND 31", full, truthTrue},
+		{"score BETWEEN 31 AND 40", full, truthFalse},
+		{"score NOT BETWEEN 31 AND 40", full, truthTrue},
+		{"score BETWEEN 30 AND 31", nulls, truthUnknown},
+		{"id IN (1, 2, 3)", full, truthTrue},
+		{"id NOT IN (1, 2, 3)", full, truthFalse},
+		{"id IN (1, NULL)", full, truthUnknown},
+		{"id IN (3, NULL)", full, truthTrue},
+		{"id NOT IN (1, NULL)", full, truthUnknown},
+		{"name LIKE 'c%'", full, truthTrue},
+		{"name LIKE '_aro_'", full, truthTrue},
+		{"name LIKE 'car'", full, truthFalse},
+		{"name NOT LIKE '%ol'", full, truthFalse},
+		{"name LIKE '%'", nulls, truthUnknown},
+		{"id LIKE '3'", full, truthTrue},
+		{"name = 'Carol' AND NOT active OR id = 3", full, truthTrue},
+	}
+	for _, test := range tests {
+		if result := testEval(t, "SELECT * FROM s WHERE "+test.where, test.row); test.expected != result {
+			t.Errorf("WHERE %s on %v is %d, expected %d", test.where, test.row, result, test.expected)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	row := []interface{}{int64(3), "Carol", 30.5, true}
+	err, parsed := parseQuery("SELECT * FROM s WHERE id = ? AND name = ?")
+	if nil != err || 2 != parsed.numInput {
+		t.Fatalf("? placeholders are parsed as %d inputs, error %v", parsed.numInput, err)
+	}
+	if result := testEval(t, "SELECT * FROM s WHERE id = ? AND name = ?", row, int64(3), "Carol"); truthTrue != result {
+		t.Errorf("? placeholders are evaluated to %d", result)
+	}
+	// numbered placeholders may repeat and go in any order, following ? takes the next number
+	err, parsed = parseQuery("SELECT * FROM s WHERE name = $3 OR id = $1 OR id = ?")
+	if nil != err || 3 != parsed.numInput {
+		t.Fatalf("$N placeholders are parsed as %d inputs, error %v", parsed.numInput, err)
+	}
+	if result := testEval(t, "SELECT * FROM s WHERE name = $2 AND id = $1 AND score > $1", row, int64(3), []byte("Carol")); truthTrue != result {
+		t.Errorf("$N placeholders are evaluated to %d", result)
+	}
+	if result := testEval(t, "SELECT * FROM s WHERE score = ?", row, nil); truthUnknown != result {
+		t.Errorf("NULL argument is evaluated to %d", result)
+	}
+	err, parsed = parseQuery("SELECT * FROM s WHERE id = $2")
+	if nil != err {
+		t.Fatal(err)
+	}
+	binder := &binder{columns: testColumns, args: []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}}
+	if err, _ := binder.bind(parsed.where); nil == err {
+		t.Errorf("missing argument is bound")
+	}
+	for _, query := range []string{"SELECT * FROM s WHERE id = $", "SELECT * FROM s WHERE id = $0"} {
+		if err, _ := parseQuery(query); nil == err {
+			t.Errorf("%s is parsed", query)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	err, parsed := parseQuery(`select "Full name" AS n, id FROM [Sheet 1] limit 10 offset 5;`)
+	if nil != err {
+		t.Fatal(err)
+	}
+	expectedColumns := []tSelectColumn{{name: "Full name", alias: "n"}, {name: "id", alias: "id"}}
+	if !reflect.DeepEqual(expectedColumns, parsed.columns) || "Sheet 1" != parsed.table || 10 != parsed.limit || 5 != parsed.offset {
+		t.Errorf("query is parsed as %+v", parsed)
+	}
+	if err, parsed = parseQuery("SELECT * FROM s"); nil != err || nil != parsed.columns || parsed.limit >= 0 || 0 != parsed.offset {
+		t.Errorf("query without LIMIT is parsed as %+v, error %v", parsed, err)
+	}
+	for _, query := range []string{
+		"SELECT * FROM s LIMIT -1",
+		"SELECT * FROM s LIMIT 1.5",
+		"SELECT * FROM s OFFSET 1",
+		"SELECT * FROM s LIMIT 1 OFFSET",
+		"SELECT * FROM s ORDER BY id",
+		"SELECT * FROM s, t",
+		"SELECT * FROM s WHERE id NOT = 1",
+		"SELECT * FROM s WHERE name = 'unterminated",
+		"SELECT FROM s",
+		"DELETE FROM s",
+	} {
+		if err, _ := parseQuery(query); nil == err {
+			t.Errorf("%s is parsed", query)
+		}
+	}
+}
+
+// people.xlsx has header id, name, score, active and 6 rows, score of the last row is text "n/a"
+func queryPeople(t *testing.T, dataSource string, query string, args ...interface{}) [][]interface{} {
+	db, err := sql.Open(DriverName, filepath.Join("testdata", "people.xlsx")+dataSource)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(query, args...)
+	if nil != err {
+		t.Fatalf("%s: %s", query, err)
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	res := [][]interface{}{}
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+		if err := rows.Scan(pointers...); nil != err {
+			t.Fatalf("%s: %s", query, err)
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); nil != err {
+		t.Fatalf("%s: %s", query, err)
+	}
+	return res
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		args     []interface{}
+		expected [][]interface{}
+	}{
+		{"SELECT id FROM People WHERE id <= 3", nil, [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}}},
+		{"SELECT id FROM People WHERE id <= 3 LIMIT 2", nil, [][]interface{}{{int64(1)}, {int64(2)}}},
+		{"SELECT id FROM People WHERE id <= 3 LIMIT 2 OFFSET 2", nil, [][]interface{}{{int64(3)}}},
+		{"SELECT id FROM People WHERE id <= 3 LIMIT 0", nil, [][]interface{}{}},
+		{"SELECT id FROM people WHERE NOT active", nil, [][]interface{}{{int64(2)}, {int64(5)}}},
+		{"SELECT Name FROM People WHERE name LIKE '%off' OR name LIKE 'b%'", nil, [][]interface{}{{"Bob"}, {"50%_off"}}},
+		{"SELECT name, active FROM People WHERE id BETWEEN $1 AND $2 AND active IS NULL", []interface{}{3, 5}, [][]interface{}{{"Dave", nil}}},
+		{"SELECT name FROM People WHERE id IN (?, ?)", []interface{}{1, "5"}, [][]interface{}{{"Alice"}, {"50%_off"}}},
+		{"SELECT score FROM People WHERE id > 3 AND id < 6", nil, [][]interface{}{{int64(40)}, {int64(50)}}},
+	}
+	for _, test := range tests {
+		// sample rows do not reach the last row, so its score would fail conversion when WHERE is not pushed down
+		if rows := queryPeople(t, "?header=1&sample=4", test.query, test.args...); !reflect.DeepEqual(test.expected, rows) {
+			t.Errorf("%s returned %v, expected %v", test.query, rows, test.expected)
+		}
+	}
+	db, err := sql.Open(DriverName, filepath.Join("testdata", "people.xlsx")+"?header=1&sample=4")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT score FROM People WHERE id > 5")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); nil == err || !strings.Contains(err.Error(), "C7") {
+		t.Errorf("matching row with text score returned error %v, expected error about cell C7", err)
+	}
+}
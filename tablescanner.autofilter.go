@@ -0,0 +1,231 @@
+package tablescanner
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TAutoFilter is sheet autofilter, Ref is empty when sheet keeps sort state only
+type TAutoFilter struct {
+	Ref       string
+	Columns   []TAutoFilterColumn
+	SortState *TSortState
+}
+
+// TAutoFilterColumn is criteria of single autofilter column, only one of criteria kinds is set
+type TAutoFilterColumn struct {
+	ColumnId int             // 0-based offset from the first column of autofilter range
+	Values   []string        // cell matches any of listed display values
+	Blank    bool            // empty cells match value filter too
+	Top10    *TTop10Filter   // top/bottom N items or percent
+	Custom   *TCustomFilters // one or two comparison conditions
+	Dynamic  string          // dynamic filter type ("aboveAverage", "today", ...), not evaluated
+}
+
+type TTop10Filter struct {
+	Top       bool
+	Percent   bool
+	Val       float64
+	FilterVal *float64 // threshold stored by excel when filter was applied
+}
+
+type TCustomFilters struct {
+	And     bool
+	Filters []TCustomFilter
+}
+
+// TCustomFilter compares cell with Val, Operator is one of "equal"/"notEqual"/"lessThan"/"lessThanOrEqual"/"greaterThan"/"greaterThanOrEqual",
+// wildcards '*' and '?' are allowed for equal/notEqual
+type TCustomFilter struct {
+	Operator string
+	Val      string
+}
+
+type TSortState struct {
+	Ref           string
+	CaseSensitive bool
+	Conditions    []TSortCondition
+}
+
+type TSortCondition struct {
+	Ref        string
+	Descending bool
+	SortBy     string // "value"/"cellColor"/"fontColor"/"icon"
+	CustomList string
+}
+
+// rowMatches evaluates criteria against row cells, values are display texts and numbers are raw numeric values
+// ("" for non-numeric cells), rows outside of filter range always match
+func (filter *TAutoFilter) rowMatches(rowNum int, values []string, numbers []string, thresholds map[int]float64) bool {
+	err, x1, y1, _, y2 := extractCellRangeCoords(filter.Ref)
+	if nil != err || rowNum <= y1 || rowNum > y2 {
+		// header row and rows outside of range are never filtered
+		return true
+	}
+	for i := range filter.Columns {
+		column := &filter.Columns[i]
+		x := x1 + column.ColumnId - 1
+		text := ""
+		if x < len(values) {
+			text = values[x]
+		}
+		numberStr := ""
+		if x < len(numbers) {
+			numberStr = numbers[x]
+		}
+		number, numberErr := strconv.ParseFloat(numberStr, 64)
+		threshold, thresholdExists := thresholds[column.ColumnId]
+		if !column.matches(text, number, nil == numberErr, threshold, thresholdExists) {
+			return false
+		}
+	}
+	return true
+}
+
+func (column *TAutoFilterColumn) matches(text string, number float64, isNumber bool, threshold float64, thresholdExists bool) bool {
+	switch {
+	case nil != column.Top10:
+		if !isNumber || !thresholdExists {
+			return false
+		}
+		if column.Top10.Top {
+			return number >= threshold
+		}
+		return number <= threshold
+	case nil != column.Custom:
+		if 0 == len(column.Custom.Filters) {
+			return true
+		}
+		for i := range column.Custom.Filters {
+			res := column.Custom.Filters[i].matches(text, number, isNumber)
+			if res != column.Custom.And {
+				return res
+			}
+		}
+		return column.Custom.And
+	case "" != column.Dynamic:
+		return true
+	}
+	if "" == strings.TrimSpace(text) {
+		return column.Blank
+	}
+	for _, value := range column.Values {
+		if strings.EqualFold(value, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *TCustomFilter) matches(text string, number float64, isNumber bool) bool {
+	cmp := 0
+	if "" == strings.TrimSpace(filter.Val) {
+		// " " is stored for blank/non-blank conditions
+		if "" != strings.TrimSpace(text) {
+			cmp = 1
+		}
+	} else if val, err := strconv.ParseFloat(filter.Val, 64); nil == err && isNumber {
+		switch {
+		case number < val:
+			cmp = -1
+		case number > val:
+			cmp = 1
+		}
+	} else {
+		switch filter.Operator {
+		case "", "equal":
+			return wildcardMatch(filter.Val, text)
+		case "notEqual":
+			return !wildcardMatch(filter.Val, text)
+		}
+		if _, err := strconv.ParseFloat(filter.Val, 64); nil == err {
+			// text never passes numeric comparison
+			return false
+		}
+		cmp = strings.Compare(strings.ToLower(text), strings.ToLower(filter.Val))
+	}
+	switch filter.Operator {
+	case "notEqual":
+		return 0 != cmp
+	case "lessThan":
+		return cmp < 0
+	case "lessThanOrEqual":
+		return cmp <= 0
+	case "greaterThan":
+		return cmp > 0
+	case "greaterThanOrEqual":
+		return cmp >= 0
+	}
+	return 0 == cmp
+}
+
+// wildcardMatch matches text by excel pattern case-insensitively, '*' is any sequence, '?' is any char, '~' escapes them
+func wildcardMatch(pattern string, text string) bool {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	pi, ti := 0, 0
+	starP, starT := -1, 0
+	for ti < len(t) {
+		if pi < len(p) {
+			switch {
+			case '*' == p[pi]:
+				starP, starT = pi, ti
+				pi++
+				continue
+			case '?' == p[pi]:
+				pi++
+				ti++
+				continue
+			case '~' == p[pi] && pi+1 < len(p) && ('*' == p[pi+1] || '?' == p[pi+1] || '~' == p[pi+1]):
+				if p[pi+1] == t[ti] {
+					pi += 2
+					ti++
+					continue
+				}
+			case p[pi] == t[ti]:
+				pi++
+				ti++
+				continue
+			}
+		}
+		if -1 == starP {
+			return false
+		}
+		starT++
+		pi, ti = starP+1, starT
+	}
+	for pi < len(p) && '*' == p[pi] {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// threshold returns the least (or the greatest for bottom) numeric value passing top10 filter
+func (filter *TTop10Filter) threshold(numbers []float64) (float64, bool) {
+	if nil != filter.FilterVal {
+		return *filter.FilterVal, true
+	}
+	if 0 == len(numbers) {
+		return 0, false
+	}
+	count := int(filter.Val)
+	if filter.Percent {
+		count = int(math.Ceil(float64(len(numbers)) * filter.Val / 100))
+	}
+	if count < 1 {
+		count = 1
+	}
+	if count > len(numbers) {
+		count = len(numbers)
+	}
+	sorted := make([]float64, len(numbers))
+	copy(sorted, numbers)
+	if filter.Top {
+		sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	} else {
+		sort.Float64s(sorted)
+	}
+	return sorted[count-1], true
+}
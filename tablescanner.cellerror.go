@@ -0,0 +1,52 @@
+package tablescanner
+
+// error codes of cells as they are stored in documents
+const (
+	CellErrorNull        = "#NULL!"
+	CellErrorDivZero     = "#DIV/0!"
+	CellErrorValue       = "#VALUE!"
+	CellErrorRef         = "#REF!"
+	CellErrorName        = "#NAME?"
+	CellErrorNum         = "#NUM!"
+	CellErrorNA          = "#N/A"
+	CellErrorGettingData = "#GETTING_DATA"
+)
+
+// TCellError is error value of cell like #N/A or #DIV/0!, Code is stored english code
+type TCellError struct {
+	Code string
+}
+
+func (cellError *TCellError) Error() string {
+	return cellError.Code
+}
+
+func pickCellErrors(values []*TCellError, ids []int) []*TCellError {
+	if nil == ids {
+		return values
+	}
+	res := make([]*TCellError, 0, len(ids))
+	for _, id := range ids {
+		if id < len(values) {
+			res = append(res, values[id])
+		}
+	}
+	return res
+}
+
+// setScannedError keeps error of 1-based column, shared by xlsx and xlsb readers
+func (xlsx *xlsxStream) setScannedError(columnNum int, code string) {
+	for len(xlsx.iteratorScannedErrors) < columnNum {
+		xlsx.iteratorScannedErrors = append(xlsx.iteratorScannedErrors, nil)
+	}
+	xlsx.iteratorScannedErrors[columnNum-1] = &TCellError{Code: code}
+}
+
+func (xlsx *xlsxStream) GetScannedErrors() []*TCellError {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		return []*TCellError{}
+	}
+	res := make([]*TCellError, len(xlsx.iteratorScannedData))
+	copy(res, xlsx.iteratorScannedErrors)
+	return pickCellErrors(res, xlsx.visibleColumnIds(len(res)))
+}
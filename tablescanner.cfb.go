@@ -0,0 +1,435 @@
+package tablescanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// compound file (OLE2) special sector ids
+const (
+	cfbFreeSect   uint32 = 0xFFFFFFFF
+	cfbEndOfChain uint32 = 0xFFFFFFFE
+	cfbFatSect    uint32 = 0xFFFFFFFD
+	cfbDifSect    uint32 = 0xFFFFFFFC
+	cfbNoStream   uint32 = 0xFFFFFFFF
+)
+
+const (
+	cfbObjectStorage = 1
+	cfbObjectStream  = 2
+	cfbObjectRoot    = 5
+)
+
+var signatureCFB = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+
+type cfbEntry struct {
+	name       string
+	objectType byte
+	left       uint32
+	right      uint32
+	child      uint32
+	start      uint32
+	size       int64
+}
+
+// cfbFile is read-only compound file, only streams placed directly in root storage are looked up
+type cfbFile struct {
+	file           *os.File
+	sectorSize     int64
+	miniSectorSize int64
+	miniCutoff     int64
+	fat            []uint32
+	miniFat        []uint32
+	entries        []cfbEntry
+	miniStream     []byte // root entry stream keeping small streams, nil until needed
+}
+
+func openCFB(fileName string) (error, *cfbFile) {
+	file, err := os.Open(fileName)
+	if nil != err {
+		return err, nil
+	}
+	cfb := &cfbFile{file: file}
+	err = cfb.readHeader()
+	if nil != err {
+		_ = file.Close()
+		return fmt.Errorf("compound file [%s] is broken: %s", fileName, err), nil
+	}
+	return nil, cfb
+}
+
+func (cfb *cfbFile) Close() error {
+	return cfb.file.Close()
+}
+
+func (cfb *cfbFile) readHeader() error {
+	header := make([]byte, 512)
+	if _, err := cfb.file.ReadAt(header, 0); nil != err {
+		return err
+	}
+	if !bytes.Equal(signatureCFB, header[:8]) {
+		return fmt.Errorf("signature mismatch")
+	}
+	sectorShift := binary.LittleEndian.Uint16(header[30:])
+	miniSectorShift := binary.LittleEndian.Uint16(header[32:])
+	if sectorShift < 7 || sectorShift > 16 || miniSectorShift > sectorShift {
+		return fmt.Errorf("invalid sector size")
+	}
+	cfb.sectorSize = 1 << sectorShift
+	cfb.miniSectorSize = 1 << miniSectorShift
+	cfb.miniCutoff = int64(binary.LittleEndian.Uint32(header[56:]))
+	// FAT sector ids are listed by header and DIFAT sector chain
+	fatSectors := []uint32{}
+	for i := 0; i < 109; i++ {
+		if sector := binary.LittleEndian.Uint32(header[76+i*4:]); sector < cfbDifSect {
+			fatSectors = append(fatSectors, sector)
+		}
+	}
+	difatSector := binary.LittleEndian.Uint32(header[68:])
+	for count := binary.LittleEndian.Uint32(header[72:]); difatSector < cfbDifSect && count > 0; count-- {
+		err, data := cfb.readSector(difatSector)
+		if nil != err {
+			return err
+		}
+		for i := 0; i+4 < len(data); i += 4 {
+			if sector := binary.LittleEndian.Uint32(data[i:]); sector < cfbDifSect {
+				fatSectors = append(fatSectors, sector)
+			}
+		}
+		difatSector = binary.LittleEndian.Uint32(data[len(data)-4:])
+	}
+	for _, sector := range fatSectors {
+		err, data := cfb.readSector(sector)
+		if nil != err {
+			return err
+		}
+		for i := 0; i < len(data); i += 4 {
+			cfb.fat = append(cfb.fat, binary.LittleEndian.Uint32(data[i:]))
+		}
+	}
+	err, directory := cfb.readChain(binary.LittleEndian.Uint32(header[48:]), -1, cfb.fat, cfb.sectorSize, cfb.readSector)
+	if nil != err {
+		return err
+	}
+	for i := 0; i+128 <= len(directory); i += 128 {
+		cfb.entries = append(cfb.entries, readCFBEntry(directory[i:i+128]))
+	}
+	if 0 == len(cfb.entries) || cfbObjectRoot != cfb.entries[0].objectType {
+		return fmt.Errorf("root storage not found")
+	}
+	if miniFatSector := binary.LittleEndian.Uint32(header[60:]); miniFatSector < cfbDifSect {
+		err, data := cfb.readChain(miniFatSector, -1, cfb.fat, cfb.sectorSize, cfb.readSector)
+		if nil != err {
+			return err
+		}
+		for i := 0; i+4 <= len(data); i += 4 {
+			cfb.miniFat = append(cfb.miniFat, binary.LittleEndian.Uint32(data[i:]))
+		}
+	}
+	return nil
+}
+
+func readCFBEntry(data []byte) cfbEntry {
+	nameLength := int(binary.LittleEndian.Uint16(data[64:]))/2 - 1
+	if nameLength < 0 || nameLength > 31 {
+		nameLength = 0
+	}
+	name := make([]uint16, nameLength)
+	for i := range name {
+		name[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return cfbEntry{
+		name:       string(utf16.Decode(name)),
+		objectType: data[66],
+		left:       binary.LittleEndian.Uint32(data[68:]),
+		right:      binary.LittleEndian.Uint32(data[72:]),
+		child:      binary.LittleEndian.Uint32(data[76:]),
+		start:      binary.LittleEndian.Uint32(data[116:]),
+		size:       int64(binary.LittleEndian.Uint64(data[120:])),
+	}
+}
+
+func (cfb *cfbFile) readSector(sector uint32) (error, []byte) {
+	data := make([]byte, cfb.sectorSize)
+	_, err := cfb.file.ReadAt(data, (int64(sector)+1)*cfb.sectorSize)
+	if nil != err {
+		return fmt.Errorf("cannot read sector #%d: %s", sector, err), nil
+	}
+	return nil, data
+}
+
+func (cfb *cfbFile) readMiniSector(sector uint32) (error, []byte) {
+	offset := int64(sector) * cfb.miniSectorSize
+	if offset+cfb.miniSectorSize > int64(len(cfb.miniStream)) {
+		return fmt.Errorf("mini sector #%d is out of mini stream", sector), nil
+	}
+	return nil, cfb.miniStream[offset : offset+cfb.miniSectorSize]
+}
+
+// readChain concatenates sectors of chain, size < 0 reads the whole chain
+func (cfb *cfbFile) readChain(sector uint32, size int64, fat []uint32, sectorSize int64, read func(uint32) (error, []byte)) (error, []byte) {
+	res := []byte{}
+	for count := 0; sector < cfbDifSect && (size < 0 || int64(len(res)) < size); count++ {
+		if count > len(fat) || int(sector) >= len(fat) {
+			return fmt.Errorf("sector chain is broken at #%d", sector), nil
+		}
+		err, data := read(sector)
+		if nil != err {
+			return err, nil
+		}
+		res = append(res, data...)
+		sector = fat[sector]
+	}
+	if size >= 0 {
+		if int64(len(res)) < size {
+			return fmt.Errorf("stream is truncated"), nil
+		}
+		res = res[:size]
+	}
+	return nil, res
+}
+
+// findStream looks up stream of root storage by case-insensitive name
+func (cfb *cfbFile) findStream(name string) *cfbEntry {
+	return cfb.findEntry(name, cfbObjectStream)
+}
+
+// findEntry looks up stream or storage placed directly in root storage
+func (cfb *cfbFile) findEntry(name string, objectType byte) *cfbEntry {
+	var found *cfbEntry
+	var walk func(id uint32, depth int)
+	walk = func(id uint32, depth int) {
+		if nil != found || id >= uint32(len(cfb.entries)) || depth > len(cfb.entries) {
+			return
+		}
+		entry := &cfb.entries[id]
+		if objectType == entry.objectType && strings.EqualFold(entry.name, name) {
+			found = entry
+			return
+		}
+		walk(entry.left, depth+1)
+		walk(entry.right, depth+1)
+	}
+	walk(cfb.entries[0].child, 0)
+	return found
+}
+
+// readStream reads whole stream of root storage
+func (cfb *cfbFile) readStream(name string) (error, []byte) {
+	entry := cfb.findStream(name)
+	if nil == entry {
+		return fmt.Errorf("stream [%s] not found", name), nil
+	}
+	if entry.size >= cfb.miniCutoff {
+		return cfb.readChain(entry.start, entry.size, cfb.fat, cfb.sectorSize, cfb.readSector)
+	}
+	if nil == cfb.miniStream {
+		root := &cfb.entries[0]
+		err, miniStream := cfb.readChain(root.start, root.size, cfb.fat, cfb.sectorSize, cfb.readSector)
+		if nil != err {
+			return fmt.Errorf("cannot read mini stream: %s", err), nil
+		}
+		cfb.miniStream = miniStream
+	}
+	return cfb.readChain(entry.start, entry.size, cfb.miniFat, cfb.miniSectorSize, cfb.readMiniSector)
+}
+
+type cfbWriteStream struct {
+	name string
+	data []byte
+}
+
+// writeCFB writes version 3 compound file (512-byte sectors) keeping streams in root storage, version 4 is
+// not used because xls reader package reads 512-byte sectors only
+func writeCFB(w io.Writer, streams []cfbWriteStream) error {
+	const sectorSize = 512
+	const headerSize = 512
+	const headerFatSectors = 109
+	const difatEntries = sectorSize/4 - 1 // last entry of DIFAT sector is next DIFAT sector id
+	const miniSectorSize = 64
+	const miniCutoff = 4096
+	sort.Slice(streams, func(i, j int) bool {
+		// directory siblings are ordered by name length first
+		if len(streams[i].name) != len(streams[j].name) {
+			return len(streams[i].name) < len(streams[j].name)
+		}
+		return strings.ToUpper(streams[i].name) < strings.ToUpper(streams[j].name)
+	})
+	sectors := func(size int, unit int) int {
+		return (size + unit - 1) / unit
+	}
+	// mini stream keeps small streams
+	miniStream := []byte{}
+	miniFat := []uint32{}
+	starts := make([]uint32, len(streams))
+	for i, stream := range streams {
+		if len(stream.data) >= miniCutoff {
+			continue
+		}
+		starts[i] = cfbEndOfChain
+		count := sectors(len(stream.data), miniSectorSize)
+		if count > 0 {
+			starts[i] = uint32(len(miniFat))
+		}
+		for j := 0; j < count; j++ {
+			next := uint32(len(miniFat) + 1)
+			if j == count-1 {
+				next = cfbEndOfChain
+			}
+			miniFat = append(miniFat, next)
+		}
+		miniStream = append(miniStream, stream.data...)
+		miniStream = append(miniStream, make([]byte, count*miniSectorSize-len(stream.data))...)
+	}
+	fat := []uint32{}
+	allocate := func(count int) uint32 {
+		if 0 == count {
+			return cfbEndOfChain
+		}
+		start := uint32(len(fat))
+		for j := 0; j < count; j++ {
+			next := uint32(len(fat) + 1)
+			if j == count-1 {
+				next = cfbEndOfChain
+			}
+			fat = append(fat, next)
+		}
+		return start
+	}
+	directorySectors := sectors((len(streams)+1)*128, sectorSize)
+	directoryStart := allocate(directorySectors)
+	miniFatSectors := sectors(len(miniFat)*4, sectorSize)
+	miniFatStart := allocate(miniFatSectors)
+	miniStreamStart := allocate(sectors(len(miniStream), sectorSize))
+	for i, stream := range streams {
+		if len(stream.data) >= miniCutoff {
+			starts[i] = allocate(sectors(len(stream.data), sectorSize))
+		}
+	}
+	// FAT sectors have to cover themselves and DIFAT sectors listing FAT sectors beyond header
+	fatSectors, difatSectors := 0, 0
+	for ; len(fat)+fatSectors+difatSectors > fatSectors*sectorSize/4; fatSectors++ {
+		difatSectors = 0
+		if fatSectors+1 > headerFatSectors {
+			difatSectors = sectors(fatSectors+1-headerFatSectors, difatEntries)
+		}
+	}
+	fatStart := len(fat)
+	for j := 0; j < fatSectors; j++ {
+		fat = append(fat, cfbFatSect)
+	}
+	difatStart := len(fat)
+	for j := 0; j < difatSectors; j++ {
+		fat = append(fat, cfbDifSect)
+	}
+	for len(fat)%(sectorSize/4) != 0 {
+		fat = append(fat, cfbFreeSect)
+	}
+	// header sector
+	header := make([]byte, headerSize)
+	copy(header, signatureCFB)
+	binary.LittleEndian.PutUint16(header[24:], 0x3E)
+	binary.LittleEndian.PutUint16(header[26:], 3)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], uint32(fatSectors))
+	binary.LittleEndian.PutUint32(header[48:], directoryStart)
+	binary.LittleEndian.PutUint32(header[56:], miniCutoff)
+	binary.LittleEndian.PutUint32(header[60:], miniFatStart)
+	binary.LittleEndian.PutUint32(header[64:], uint32(miniFatSectors))
+	binary.LittleEndian.PutUint32(header[68:], cfbEndOfChain)
+	if difatSectors > 0 {
+		binary.LittleEndian.PutUint32(header[68:], uint32(difatStart))
+	}
+	binary.LittleEndian.PutUint32(header[72:], uint32(difatSectors))
+	difat := make([]uint32, headerFatSectors+difatSectors*difatEntries)
+	for i := range difat {
+		difat[i] = cfbFreeSect
+		if i < fatSectors {
+			difat[i] = uint32(fatStart + i)
+		}
+	}
+	for i := 0; i < headerFatSectors; i++ {
+		binary.LittleEndian.PutUint32(header[76+i*4:], difat[i])
+	}
+	difatData := make([]byte, difatSectors*sectorSize)
+	for j := 0; j < difatSectors; j++ {
+		data := difatData[j*sectorSize : (j+1)*sectorSize]
+		for i := 0; i < difatEntries; i++ {
+			binary.LittleEndian.PutUint32(data[i*4:], difat[headerFatSectors+j*difatEntries+i])
+		}
+		next := cfbEndOfChain
+		if j < difatSectors-1 {
+			next = uint32(difatStart + j + 1)
+		}
+		binary.LittleEndian.PutUint32(data[sectorSize-4:], next)
+	}
+	// directory
+	directory := make([]byte, directorySectors*sectorSize)
+	putEntry := func(id int, name string, objectType byte, child uint32, right uint32, start uint32, size int) {
+		data := directory[id*128 : id*128+128]
+		name16 := utf16.Encode([]rune(name))
+		for i, r := range name16 {
+			binary.LittleEndian.PutUint16(data[i*2:], r)
+		}
+		binary.LittleEndian.PutUint16(data[64:], uint16(len(name16)*2+2))
+		data[66] = objectType
+		data[67] = 1 // black
+		binary.LittleEndian.PutUint32(data[68:], cfbNoStream)
+		binary.LittleEndian.PutUint32(data[72:], right)
+		binary.LittleEndian.PutUint32(data[76:], child)
+		binary.LittleEndian.PutUint32(data[116:], start)
+		binary.LittleEndian.PutUint64(data[120:], uint64(size))
+	}
+	for id := 0; id*128 < len(directory); id++ {
+		binary.LittleEndian.PutUint32(directory[id*128+68:], cfbNoStream)
+		binary.LittleEndian.PutUint32(directory[id*128+72:], cfbNoStream)
+		binary.LittleEndian.PutUint32(directory[id*128+76:], cfbNoStream)
+	}
+	rootChild := cfbNoStream
+	if len(streams) > 0 {
+		rootChild = 1
+	}
+	putEntry(0, "Root Entry", cfbObjectRoot, rootChild, cfbNoStream, miniStreamStart, len(miniStream))
+	for i, stream := range streams {
+		right := uint32(i + 2)
+		if i == len(streams)-1 {
+			right = cfbNoStream
+		}
+		putEntry(i+1, stream.name, cfbObjectStream, cfbNoStream, right, starts[i], len(stream.data))
+	}
+	miniFatData := make([]byte, miniFatSectors*sectorSize)
+	for i := range miniFatData {
+		miniFatData[i] = 0xFF
+	}
+	for i, next := range miniFat {
+		binary.LittleEndian.PutUint32(miniFatData[i*4:], next)
+	}
+	fatData := make([]byte, len(fat)*4)
+	for i, next := range fat {
+		binary.LittleEndian.PutUint32(fatData[i*4:], next)
+	}
+	pad := func(data []byte) []byte {
+		return append(data, make([]byte, sectors(len(data), sectorSize)*sectorSize-len(data))...)
+	}
+	parts := [][]byte{header, directory, miniFatData, pad(miniStream)}
+	for _, stream := range streams {
+		if len(stream.data) >= miniCutoff {
+			parts = append(parts, pad(stream.data))
+		}
+	}
+	parts = append(parts, fatData[:fatSectors*sectorSize], difatData)
+	for _, part := range parts {
+		if _, err := w.Write(part); nil != err {
+			return err
+		}
+	}
+	return nil
+}
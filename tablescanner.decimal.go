@@ -0,0 +1,71 @@
+package tablescanner
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// TDecimal is numeric cell value exactly as it is stored in document, like "1234567890123.45" or "1.5E-3",
+// empty string means cell is not numeric
+type TDecimal string
+
+func (decimal TDecimal) String() string {
+	return string(decimal)
+}
+
+func (decimal TDecimal) IsEmpty() bool {
+	return "" == decimal
+}
+
+// Rat converts stored value to exact fraction without passing through float64
+func (decimal TDecimal) Rat() (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(string(decimal))
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal value [%s]", string(decimal))
+	}
+	return value, nil
+}
+
+// FloatString renders stored value with fixed fraction digits, last digit is rounded half away from zero
+func (decimal TDecimal) FloatString(digits int) (string, error) {
+	value, err := decimal.Rat()
+	if nil != err {
+		return "", err
+	}
+	return value.FloatString(digits), nil
+}
+
+func (decimal TDecimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(decimal), 64)
+}
+
+func pickDecimals(values []TDecimal, ids []int) []TDecimal {
+	if nil == ids {
+		return values
+	}
+	res := make([]TDecimal, 0, len(ids))
+	for _, id := range ids {
+		if id < len(values) {
+			res = append(res, values[id])
+		}
+	}
+	return res
+}
+
+// setScannedDecimal keeps raw numeric value of 1-based column, shared by xlsx and xlsb readers
+func (xlsx *xlsxStream) setScannedDecimal(columnNum int, value string) {
+	for len(xlsx.iteratorScannedDecimals) < columnNum {
+		xlsx.iteratorScannedDecimals = append(xlsx.iteratorScannedDecimals, "")
+	}
+	xlsx.iteratorScannedDecimals[columnNum-1] = TDecimal(value)
+}
+
+func (xlsx *xlsxStream) GetScannedDecimals() []TDecimal {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		return []TDecimal{}
+	}
+	res := make([]TDecimal, len(xlsx.iteratorScannedData))
+	copy(res, xlsx.iteratorScannedDecimals)
+	return pickDecimals(res, xlsx.visibleColumnIds(len(res)))
+}
@@ -0,0 +1,260 @@
+package tablescanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+type TDetectionConfidence byte
+
+const (
+	DetectionConfidenceNone   TDetectionConfidence = 0 // type is unknown
+	DetectionConfidenceLow    TDetectionConfidence = 1 // guessed by file signature or leading tag only
+	DetectionConfidenceMedium TDetectionConfidence = 2 // guessed by package part names or root element
+	DetectionConfidenceHigh   TDetectionConfidence = 3 // declared by package content types, mimetype or document namespace
+)
+
+// TWorkbookKind is declared purpose of workbook package, it does not tell whether macros are really present
+type TWorkbookKind byte
+
+const (
+	WorkbookKindUnknown              TWorkbookKind = 0
+	WorkbookKindWorkbook             TWorkbookKind = 1 // binary workbooks (xls, xlsb) may keep macros as well
+	WorkbookKindTemplate             TWorkbookKind = 2
+	WorkbookKindMacroEnabled         TWorkbookKind = 3
+	WorkbookKindMacroEnabledTemplate TWorkbookKind = 4
+	WorkbookKindAddIn                TWorkbookKind = 5
+)
+
+// TContentDetection is detected file type with the evidence it is based on, Reason explains unknown type as well
+type TContentDetection struct {
+	Type         TExcelWorkbookType
+	Confidence   TDetectionConfidence
+	Reason       string
+	TextEncoding TTextEnconding
+	BOMPresent   []byte
+	Encrypted    bool // workbook is password-protected, it is opened by NewTableStreamWithPassword()
+	Kind         TWorkbookKind
+	WorkbookPart string // main part of zip package, like "xl/workbook.xml"
+	// MacrosPresent tells VBA project, excel 4.0 macro sheets or OpenDocument scripts are found in package,
+	// nothing is ever executed
+	MacrosPresent bool
+}
+
+const (
+	contentTypeXLSXWorkbook = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"
+	contentTypeXLTXWorkbook = "application/vnd.openxmlformats-officedocument.spreadsheetml.template.main+xml"
+	contentTypeXLSMWorkbook = "application/vnd.ms-excel.sheet.macroEnabled.main+xml"
+	contentTypeXLTMWorkbook = "application/vnd.ms-excel.template.macroEnabled.main+xml"
+	contentTypeXLAMWorkbook = "application/vnd.ms-excel.addin.macroEnabled.main+xml"
+	contentTypeVBAProject   = "application/vnd.ms-office.vbaProject"
+	contentTypeMacroSheet   = "application/vnd.ms-excel.macrosheet+xml"
+	contentTypeXLSBWorkbook = "application/vnd.ms-excel.sheet.binary.macroEnabled.main"
+	mimeTypeODS             = "application/vnd.oasis.opendocument.spreadsheet" // templates have "-template" suffix
+	mimeTypeOpenDocument    = "application/vnd.oasis.opendocument."
+	nsSpreadsheetML         = "urn:schemas-microsoft-com:office:spreadsheet"
+)
+
+// workbookContentTypes maps xml workbook main part content types to declared workbook kind
+var workbookContentTypes = map[string]TWorkbookKind{
+	contentTypeXLSXWorkbook: WorkbookKindWorkbook,
+	contentTypeXLSMWorkbook: WorkbookKindMacroEnabled,
+	contentTypeXLTXWorkbook: WorkbookKindTemplate,
+	contentTypeXLTMWorkbook: WorkbookKindMacroEnabledTemplate,
+	contentTypeXLAMWorkbook: WorkbookKindAddIn,
+}
+
+type xmlContentTypes struct {
+	Override []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+	Default []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+}
+
+// detectZipContentType inspects central directory, so local header order, data descriptors and ZIP64 do not matter
+func detectZipContentType(fileName string) TContentDetection {
+	z, err := zip.OpenReader(fileName)
+	if nil != err {
+		return TContentDetection{Reason: fmt.Sprintf("zip signature found, but central directory is unreadable: %s", err)}
+	}
+	defer nowarnCloseCloser(z)
+	entries := make(map[string]*zip.File, len(z.File))
+	for _, file := range z.File {
+		entries[file.Name] = file
+	}
+	res := detectZipPackage(entries)
+	if TypeExcelWorkbookUnknown != res.Type && !res.MacrosPresent {
+		for name := range entries {
+			lowerName := strings.ToLower(name)
+			if "vbaproject.bin" == path.Base(lowerName) || strings.HasPrefix(lowerName, "xl/macrosheets/") ||
+				strings.HasPrefix(name, "Basic/") || strings.HasPrefix(name, "Scripts/") {
+				res.MacrosPresent = true
+				break
+			}
+		}
+	}
+	return res
+}
+
+func detectZipPackage(entries map[string]*zip.File) TContentDetection {
+	res := TContentDetection{TextEncoding: EncodingUTF8}
+	if file, ok := entries["mimetype"]; ok {
+		mimetype := strings.TrimSpace(string(readZipEntryPrefix(file, 256)))
+		switch {
+		case strings.HasPrefix(mimetype, mimeTypeODS):
+			res.Type, res.Confidence, res.Reason = TypeExcelWorkbookODS, DetectionConfidenceHigh, fmt.Sprintf("mimetype entry declares %s", mimetype)
+			res.Kind, res.WorkbookPart = WorkbookKindWorkbook, "content.xml"
+			if strings.HasSuffix(mimetype, "-template") {
+				res.Kind = WorkbookKindTemplate
+			}
+			return res
+		case strings.HasPrefix(mimetype, mimeTypeOpenDocument):
+			return TContentDetection{Confidence: DetectionConfidenceHigh, Reason: fmt.Sprintf("OpenDocument package is not a spreadsheet, mimetype entry declares %s", mimetype)}
+		}
+	}
+	if file, ok := entries["[Content_Types].xml"]; ok {
+		if detection, ok := detectContentTypesPart(file); ok {
+			return detection
+		}
+	}
+	res.Kind = WorkbookKindWorkbook
+	switch {
+	case nil != entries["xl/workbook.xml"]:
+		res.Type, res.Confidence, res.Reason = TypeExcelWorkbookXLSX, DetectionConfidenceMedium, "xl/workbook.xml part found, workbook content type is not declared"
+		res.WorkbookPart = "xl/workbook.xml"
+	case nil != entries["xl/workbook.bin"]:
+		res.Type, res.Confidence, res.Reason = TypeExcelWorkbookXLSB, DetectionConfidenceMedium, "xl/workbook.bin part found, workbook content type is not declared"
+		res.WorkbookPart = "xl/workbook.bin"
+	case nil != entries["content.xml"] && bytes.Contains(readZipEntryPrefix(entries["content.xml"], 4096), []byte(nsODSOffice)):
+		res.Type, res.Confidence, res.Reason = TypeExcelWorkbookODS, DetectionConfidenceLow, "OpenDocument content.xml found without mimetype entry"
+		res.WorkbookPart = "content.xml"
+	case nil != entries["word/document.xml"]:
+		return TContentDetection{Confidence: DetectionConfidenceMedium, Reason: "zip package is a Word document (word/document.xml found)"}
+	case nil != entries["ppt/presentation.xml"]:
+		return TContentDetection{Confidence: DetectionConfidenceMedium, Reason: "zip package is a PowerPoint presentation (ppt/presentation.xml found)"}
+	default:
+		return TContentDetection{Reason: fmt.Sprintf("zip archive of %d entries has no spreadsheet parts", len(entries))}
+	}
+	return res
+}
+
+// detectContentTypesPart classifies OOXML package by [Content_Types].xml, false is returned when no main part is declared
+func detectContentTypesPart(file *zip.File) (TContentDetection, bool) {
+	rc, err := file.Open()
+	if nil != err {
+		return TContentDetection{}, false
+	}
+	defer nowarnCloseCloser(rc)
+	contentTypes := new(xmlContentTypes)
+	if nil != xml.NewDecoder(rc).Decode(contentTypes) {
+		return TContentDetection{}, false
+	}
+	declared := make(map[string]string) // content type to part name or extension
+	for _, item := range contentTypes.Default {
+		declared[item.ContentType] = "*." + item.Extension
+	}
+	for _, item := range contentTypes.Override {
+		declared[item.ContentType] = item.PartName
+	}
+	partName := func(part string) string {
+		if strings.HasPrefix(part, "*.") {
+			// declared by extension default
+			return ""
+		}
+		return strings.TrimPrefix(part, "/")
+	}
+	_, vbaDeclared := declared[contentTypeVBAProject]
+	_, macroSheetDeclared := declared[contentTypeMacroSheet]
+	for _, contentType := range []string{contentTypeXLSXWorkbook, contentTypeXLSMWorkbook, contentTypeXLTXWorkbook, contentTypeXLTMWorkbook, contentTypeXLAMWorkbook} {
+		if part, ok := declared[contentType]; ok {
+			return TContentDetection{
+				Type:          TypeExcelWorkbookXLSX,
+				Confidence:    DetectionConfidenceHigh,
+				Reason:        fmt.Sprintf("[Content_Types].xml declares workbook %s as %s", part, contentType),
+				TextEncoding:  EncodingUTF8,
+				Kind:          workbookContentTypes[contentType],
+				WorkbookPart:  partName(part),
+				MacrosPresent: vbaDeclared || macroSheetDeclared,
+			}, true
+		}
+	}
+	if part, ok := declared[contentTypeXLSBWorkbook]; ok {
+		return TContentDetection{
+			Type:          TypeExcelWorkbookXLSB,
+			Confidence:    DetectionConfidenceHigh,
+			Reason:        fmt.Sprintf("[Content_Types].xml declares binary workbook %s", part),
+			TextEncoding:  EncodingUTF8,
+			Kind:          WorkbookKindWorkbook,
+			WorkbookPart:  partName(part),
+			MacrosPresent: vbaDeclared || macroSheetDeclared,
+		}, true
+	}
+	for contentType, part := range declared {
+		if !strings.HasSuffix(contentType, ".main+xml") {
+			continue
+		}
+		switch {
+		case strings.Contains(contentType, "wordprocessingml.") || strings.Contains(contentType, "ms-word."):
+			return TContentDetection{Confidence: DetectionConfidenceHigh, Reason: fmt.Sprintf("zip package is a Word document, [Content_Types].xml declares %s", part)}, true
+		case strings.Contains(contentType, "presentationml.") || strings.Contains(contentType, "ms-powerpoint."):
+			return TContentDetection{Confidence: DetectionConfidenceHigh, Reason: fmt.Sprintf("zip package is a PowerPoint presentation, [Content_Types].xml declares %s", part)}, true
+		}
+	}
+	return TContentDetection{}, false
+}
+
+// detectXMLContentType tells SpreadsheetML from flat OpenDocument by namespaces of root element
+func detectXMLContentType(signature []byte, detection *TContentDetection) {
+	if bytes.Contains(signature, []byte(nsODSOffice)) {
+		mimetype := ""
+		if pos := bytes.Index(signature, []byte("mimetype=\"")); pos >= 0 {
+			mimetype = string(signature[pos+len("mimetype=\""):])
+			if end := strings.IndexByte(mimetype, '"'); end >= 0 {
+				mimetype = mimetype[:end]
+			}
+		}
+		switch {
+		case strings.HasPrefix(mimetype, mimeTypeODS):
+			detection.Type, detection.Confidence, detection.Reason = TypeExcelWorkbookFODS, DetectionConfidenceHigh, fmt.Sprintf("flat OpenDocument declares %s", mimetype)
+			detection.Kind = WorkbookKindWorkbook
+			if strings.HasSuffix(mimetype, "-template") {
+				detection.Kind = WorkbookKindTemplate
+			}
+		case strings.HasPrefix(mimetype, mimeTypeOpenDocument):
+			detection.Confidence, detection.Reason = DetectionConfidenceHigh, fmt.Sprintf("flat OpenDocument is not a spreadsheet, it declares %s", mimetype)
+		default:
+			detection.Type, detection.Confidence, detection.Reason = TypeExcelWorkbookFODS, DetectionConfidenceMedium, "OpenDocument office namespace found"
+			detection.Kind = WorkbookKindWorkbook
+		}
+		return
+	}
+	detection.Type, detection.Kind = TypeExcelWorkbookXML, WorkbookKindWorkbook
+	if bytes.Contains(signature, []byte(nsSpreadsheetML)) || bytes.Contains(signature, []byte("progid=\"Excel.Sheet\"")) {
+		detection.Confidence, detection.Reason = DetectionConfidenceHigh, "SpreadsheetML namespace found"
+		return
+	}
+	detection.Confidence, detection.Reason = DetectionConfidenceLow, "xml declaration found, SpreadsheetML namespace is not seen in file head"
+}
+
+// readZipEntryPrefix returns up to limit leading bytes of zip entry, nil on read error
+func readZipEntryPrefix(file *zip.File, limit int64) []byte {
+	rc, err := file.Open()
+	if nil != err {
+		return nil
+	}
+	defer nowarnCloseCloser(rc)
+	res, err := io.ReadAll(io.LimitReader(rc, limit))
+	if nil != err {
+		return nil
+	}
+	return res
+}
@@ -0,0 +1,590 @@
+package tablescanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"unicode/utf16"
+)
+
+// ErrPasswordRequired is returned (wrapped) when workbook is encrypted and no valid password is supplied
+var ErrPasswordRequired = errors.New("workbook is encrypted, password required")
+
+// ErrPasswordInvalid is returned (wrapped) when supplied password does not match workbook encryption verifier
+var ErrPasswordInvalid = errors.New("workbook password is invalid")
+
+// excelDefaultPassword is used by excel for workbooks protected against modification only
+const excelDefaultPassword = "VelvetSweatshop"
+
+const (
+	xlsRecordBOF          = 0x0809
+	xlsRecordFilePass     = 0x002F
+	xlsRecordBoundSheet   = 0x0085
+	xlsRecordUsrExcl      = 0x0194
+	xlsRecordFileLock     = 0x0195
+	xlsRecordInterfaceHdr = 0x00E1
+	xlsRecordRRDInfo      = 0x0196
+	xlsRecordRRDHead      = 0x0138
+)
+
+// detectCFBContentType tells encrypted OOXML package from binary workbook by compound file streams
+func detectCFBContentType(fileName string) TContentDetection {
+	err, cfb := openCFB(fileName)
+	if nil != err {
+		return TContentDetection{
+			Type:         TypeExcelWorkbookXLS,
+			Confidence:   DetectionConfidenceLow,
+			Reason:       fmt.Sprintf("compound file signature found, but directory is unreadable: %s", err),
+			TextEncoding: EncodingUTF8,
+		}
+	}
+	defer nowarnCloseCloser(cfb)
+	if nil != cfb.findStream("EncryptionInfo") && nil != cfb.findStream("EncryptedPackage") {
+		res := TContentDetection{Type: TypeExcelWorkbookXLSX, Confidence: DetectionConfidenceHigh, TextEncoding: EncodingUTF8, Encrypted: true}
+		err, info := cfb.readStream("EncryptionInfo")
+		switch {
+		case nil != err || len(info) < 4:
+			res.Reason = "compound file keeps encrypted OOXML package"
+		case 4 == binary.LittleEndian.Uint16(info) && 4 == binary.LittleEndian.Uint16(info[2:]):
+			res.Reason = "compound file keeps OOXML package with agile encryption"
+		default:
+			res.Reason = fmt.Sprintf("compound file keeps OOXML package with standard encryption v%d.%d", binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:]))
+		}
+		return res
+	}
+	for _, name := range []string{"Workbook", "Book"} {
+		if nil == cfb.findStream(name) {
+			continue
+		}
+		res := TContentDetection{Type: TypeExcelWorkbookXLS, Confidence: DetectionConfidenceHigh, TextEncoding: EncodingUTF8, Kind: WorkbookKindWorkbook}
+		res.Reason = fmt.Sprintf("compound file has %s stream", name)
+		res.MacrosPresent = nil != cfb.findEntry("_VBA_PROJECT_CUR", cfbObjectStorage)
+		if err, stream := cfb.readStream(name); nil == err && nil != findXLSFilePass(stream) {
+			res.Encrypted = true
+			res.Reason += ", FILEPASS record found"
+		}
+		return res
+	}
+	return TContentDetection{Confidence: DetectionConfidenceMedium, Reason: "compound file has no Workbook stream"}
+}
+
+// findXLSFilePass returns FILEPASS record payload of workbook globals, nil when workbook is not encrypted
+func findXLSFilePass(stream []byte) []byte {
+	for offset := 0; offset+4 <= len(stream); {
+		recordType := binary.LittleEndian.Uint16(stream[offset:])
+		size := int(binary.LittleEndian.Uint16(stream[offset+2:]))
+		if offset+4+size > len(stream) {
+			return nil
+		}
+		switch recordType {
+		case xlsRecordFilePass:
+			return stream[offset+4 : offset+4+size]
+		case 0x000A: // EOF of workbook globals
+			return nil
+		}
+		offset += 4 + size
+	}
+	return nil
+}
+
+// tempFileScanner removes decrypted copy of workbook on Close()
+type tempFileScanner struct {
+	ITableDocumentScanner
+	fileName string
+}
+
+func (scanner *tempFileScanner) Close() error {
+	err := scanner.ITableDocumentScanner.Close()
+	if removeErr := os.Remove(scanner.fileName); nil == err {
+		err = removeErr
+	}
+	return err
+}
+
+// openEncryptedStream decrypts workbook and opens it by regular backend, OOXML package is read from memory,
+// XLS workbook is written to temporary file because xls reader package opens files by name only
+func openEncryptedStream(fileName string, detection TContentDetection, password string) (error, ITableDocumentScanner) {
+	pwd := password
+	if "" == pwd {
+		pwd = excelDefaultPassword
+	}
+	err, cfb := openCFB(fileName)
+	if nil != err {
+		return err, nil
+	}
+	defer nowarnCloseCloser(cfb)
+	var decrypted []byte
+	if TypeExcelWorkbookXLS == detection.Type {
+		err, decrypted = decryptXLSWorkbook(cfb, pwd)
+	} else {
+		err, decrypted = decryptOOXMLPackage(cfb, pwd)
+	}
+	if errors.Is(err, ErrPasswordInvalid) && "" == password {
+		err = ErrPasswordRequired
+	}
+	if nil != err {
+		return fmt.Errorf("file %s: %w", fileName, err), nil
+	}
+	if TypeExcelWorkbookXLS != detection.Type {
+		err, scanner := openDecryptedPackage(fileName, decrypted)
+		if nil != err {
+			return fmt.Errorf("cannot open decrypted file %s: %s", fileName, err), nil
+		}
+		return nil, scanner
+	}
+	tmp, err := os.CreateTemp("", "tablescanner-*")
+	if nil != err {
+		return err, nil
+	}
+	err = writeCFB(tmp, []cfbWriteStream{{name: "Workbook", data: decrypted}})
+	if closeErr := tmp.Close(); nil == err {
+		err = closeErr
+	}
+	if nil == err {
+		var scanner ITableDocumentScanner
+		err, scanner = newTableStream(tmp.Name(), "")
+		if nil == err {
+			return nil, &tempFileScanner{ITableDocumentScanner: scanner, fileName: tmp.Name()}
+		}
+	}
+	_ = os.Remove(tmp.Name())
+	return fmt.Errorf("cannot open decrypted file %s: %s", fileName, err), nil
+}
+
+// openDecryptedPackage opens zip package of decrypted OOXML workbook, binary workbook is detected by package parts
+func openDecryptedPackage(fileName string, decrypted []byte) (error, ITableDocumentScanner) {
+	z, err := zip.NewReader(bytes.NewReader(decrypted), int64(len(decrypted)))
+	if nil != err {
+		return err, nil
+	}
+	entries := make(map[string]*zip.File, len(z.File))
+	for _, file := range z.File {
+		entries[file.Name] = file
+	}
+	if TypeExcelWorkbookXLSB == detectZipPackage(entries).Type {
+		return newXLSBStreamFromZip(fileName, z, nil)
+	}
+	return newXLSXStreamFromZip(fileName, z, nil)
+}
+
+func passwordUTF16LE(password string) []byte {
+	chars := utf16.Encode([]rune(password))
+	res := make([]byte, len(chars)*2)
+	for i, c := range chars {
+		binary.LittleEndian.PutUint16(res[i*2:], c)
+	}
+	return res
+}
+
+func le32(value uint32) []byte {
+	res := make([]byte, 4)
+	binary.LittleEndian.PutUint32(res, value)
+	return res
+}
+
+func hashBytes(h hash.Hash, parts ...[]byte) []byte {
+	h.Reset()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// fitBytes truncates value or pads it by 0x36 up to size
+func fitBytes(value []byte, size int) []byte {
+	if len(value) >= size {
+		return value[:size]
+	}
+	return append(append([]byte{}, value...), bytes.Repeat([]byte{0x36}, size-len(value))...)
+}
+
+// decryptOOXMLPackage decrypts EncryptedPackage stream to zip package bytes
+func decryptOOXMLPackage(cfb *cfbFile, password string) (error, []byte) {
+	err, info := cfb.readStream("EncryptionInfo")
+	if nil != err {
+		return err, nil
+	}
+	err, encrypted := cfb.readStream("EncryptedPackage")
+	if nil != err {
+		return err, nil
+	}
+	if len(info) < 8 || len(encrypted) < 8 {
+		return fmt.Errorf("encryption info is truncated"), nil
+	}
+	size := binary.LittleEndian.Uint64(encrypted)
+	encrypted = encrypted[8:]
+	major, minor := binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:])
+	var res []byte
+	switch {
+	case 4 == major && 4 == minor:
+		err, res = decryptAgilePackage(info[8:], encrypted, password)
+	case (3 == major || 4 == major) && 2 == minor:
+		err, res = decryptStandardPackage(info[8:], encrypted, password)
+	default:
+		return fmt.Errorf("encryption v%d.%d is not supported", major, minor), nil
+	}
+	if nil != err {
+		return err, nil
+	}
+	if uint64(len(res)) < size {
+		return fmt.Errorf("encrypted package is truncated"), nil
+	}
+	return nil, res[:size]
+}
+
+type agileKeyParams struct {
+	SaltSize        int    `xml:"saltSize,attr"`
+	BlockSize       int    `xml:"blockSize,attr"`
+	KeyBits         int    `xml:"keyBits,attr"`
+	HashSize        int    `xml:"hashSize,attr"`
+	CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+	CipherChaining  string `xml:"cipherChaining,attr"`
+	HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+	SaltValue       string `xml:"saltValue,attr"`
+}
+
+type agileEncryption struct {
+	KeyData       agileKeyParams `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string `xml:"uri,attr"`
+		EncryptedKey struct {
+			agileKeyParams
+			SpinCount                  int    `xml:"spinCount,attr"`
+			EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+			EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+			EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+		} `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+func newAgileHash(name string) hash.Hash {
+	switch name {
+	case "SHA1", "SHA-1":
+		return sha1.New()
+	case "SHA256":
+		return sha256.New()
+	case "SHA384":
+		return sha512.New384()
+	case "SHA512":
+		return sha512.New()
+	}
+	return nil
+}
+
+func decryptAESCBC(key []byte, iv []byte, data []byte) (error, []byte) {
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return err, nil
+	}
+	if 0 != len(data)%block.BlockSize() || len(iv) != block.BlockSize() {
+		return fmt.Errorf("encrypted data is not aligned to cipher block"), nil
+	}
+	res := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(res, data)
+	return nil, res
+}
+
+func decryptAESECB(key []byte, data []byte) (error, []byte) {
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return err, nil
+	}
+	size := block.BlockSize()
+	if 0 != len(data)%size {
+		return fmt.Errorf("encrypted data is not aligned to cipher block"), nil
+	}
+	res := make([]byte, len(data))
+	for i := 0; i < len(data); i += size {
+		block.Decrypt(res[i:i+size], data[i:i+size])
+	}
+	return nil, res
+}
+
+// decryptAgilePackage implements ECMA-376 agile encryption with password key encryptor
+func decryptAgilePackage(descriptor []byte, encrypted []byte, password string) (error, []byte) {
+	params := &agileEncryption{}
+	if err := xml.Unmarshal(descriptor, params); nil != err {
+		return fmt.Errorf("cannot parse agile encryption descriptor: %s", err), nil
+	}
+	for _, encryptor := range params.KeyEncryptors {
+		key := &encryptor.EncryptedKey
+		if "" == key.EncryptedKeyValue {
+			// certificate key encryptor
+			continue
+		}
+		if "AES" != key.CipherAlgorithm || "AES" != params.KeyData.CipherAlgorithm {
+			return fmt.Errorf("agile cipher %s is not supported", key.CipherAlgorithm), nil
+		}
+		if "ChainingModeCBC" != key.CipherChaining || "ChainingModeCBC" != params.KeyData.CipherChaining {
+			return fmt.Errorf("agile cipher chaining %s is not supported", key.CipherChaining), nil
+		}
+		h := newAgileHash(key.HashAlgorithm)
+		dataHash := newAgileHash(params.KeyData.HashAlgorithm)
+		if nil == h || nil == dataHash {
+			return fmt.Errorf("agile hash algorithm %s is not supported", key.HashAlgorithm), nil
+		}
+		salt, err := base64.StdEncoding.DecodeString(key.SaltValue)
+		if nil != err {
+			return err, nil
+		}
+		verifierInput, err := base64.StdEncoding.DecodeString(key.EncryptedVerifierHashInput)
+		if nil != err {
+			return err, nil
+		}
+		verifierValue, err := base64.StdEncoding.DecodeString(key.EncryptedVerifierHashValue)
+		if nil != err {
+			return err, nil
+		}
+		keyValue, err := base64.StdEncoding.DecodeString(key.EncryptedKeyValue)
+		if nil != err {
+			return err, nil
+		}
+		dataSalt, err := base64.StdEncoding.DecodeString(params.KeyData.SaltValue)
+		if nil != err {
+			return err, nil
+		}
+		spun := hashBytes(h, salt, passwordUTF16LE(password))
+		for i := 0; i < key.SpinCount; i++ {
+			spun = hashBytes(h, le32(uint32(i)), spun)
+		}
+		deriveKey := func(blockKey []byte) []byte {
+			return fitBytes(hashBytes(h, spun, blockKey), key.KeyBits/8)
+		}
+		iv := fitBytes(salt, key.BlockSize)
+		err, verifierInput = decryptAESCBC(deriveKey([]byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}), iv, verifierInput)
+		if nil != err {
+			return err, nil
+		}
+		err, verifierValue = decryptAESCBC(deriveKey([]byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}), iv, verifierValue)
+		if nil != err {
+			return err, nil
+		}
+		verifierHash := hashBytes(h, fitBytes(verifierInput, key.SaltSize))
+		if len(verifierValue) < len(verifierHash) || !bytes.Equal(verifierHash, verifierValue[:len(verifierHash)]) {
+			return ErrPasswordInvalid, nil
+		}
+		err, keyValue = decryptAESCBC(deriveKey([]byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}), iv, keyValue)
+		if nil != err {
+			return err, nil
+		}
+		if len(keyValue) < params.KeyData.KeyBits/8 {
+			return fmt.Errorf("agile intermediate key is truncated"), nil
+		}
+		keyValue = keyValue[:params.KeyData.KeyBits/8]
+		// package is encrypted by 4096-byte segments, IV of segment depends on its index
+		const segmentSize = 4096
+		res := make([]byte, 0, len(encrypted))
+		for segment := 0; segment*segmentSize < len(encrypted); segment++ {
+			data := encrypted[segment*segmentSize:]
+			if len(data) > segmentSize {
+				data = data[:segmentSize]
+			}
+			segmentIV := fitBytes(hashBytes(dataHash, dataSalt, le32(uint32(segment))), params.KeyData.BlockSize)
+			err, data = decryptAESCBC(keyValue, segmentIV, data)
+			if nil != err {
+				return err, nil
+			}
+			res = append(res, data...)
+		}
+		return nil, res
+	}
+	return fmt.Errorf("password key encryptor not found"), nil
+}
+
+// decryptStandardPackage implements ECMA-376 standard (AES-ECB) encryption
+func decryptStandardPackage(info []byte, encrypted []byte, password string) (error, []byte) {
+	if len(info) < 4 {
+		return fmt.Errorf("encryption header is truncated"), nil
+	}
+	headerSize := int(binary.LittleEndian.Uint32(info))
+	if len(info) < 4+headerSize+4 || headerSize < 32 {
+		return fmt.Errorf("encryption header is truncated"), nil
+	}
+	header := info[4 : 4+headerSize]
+	algId := binary.LittleEndian.Uint32(header[8:])
+	keyBits := int(binary.LittleEndian.Uint32(header[16:]))
+	if algId < 0x660E || algId > 0x6610 {
+		return fmt.Errorf("standard encryption algorithm %#x is not supported", algId), nil
+	}
+	verifier := info[4+headerSize:]
+	saltSize := int(binary.LittleEndian.Uint32(verifier))
+	if len(verifier) < 4+saltSize+16+4+32 {
+		return fmt.Errorf("encryption verifier is truncated"), nil
+	}
+	salt := verifier[4 : 4+saltSize]
+	encryptedVerifier := verifier[4+saltSize : 4+saltSize+16]
+	encryptedVerifierHash := verifier[4+saltSize+20 : 4+saltSize+20+32]
+	h := sha1.New()
+	spun := hashBytes(h, salt, passwordUTF16LE(password))
+	for i := 0; i < 50000; i++ {
+		spun = hashBytes(h, le32(uint32(i)), spun)
+	}
+	spun = hashBytes(h, spun, le32(0))
+	buf1, buf2 := bytes.Repeat([]byte{0x36}, 64), bytes.Repeat([]byte{0x5c}, 64)
+	for i := range spun {
+		buf1[i] ^= spun[i]
+		buf2[i] ^= spun[i]
+	}
+	key := append(hashBytes(h, buf1), hashBytes(h, buf2)...)[:keyBits/8]
+	err, plainVerifier := decryptAESECB(key, encryptedVerifier)
+	if nil != err {
+		return err, nil
+	}
+	err, plainVerifierHash := decryptAESECB(key, encryptedVerifierHash)
+	if nil != err {
+		return err, nil
+	}
+	if !bytes.Equal(hashBytes(h, plainVerifier), plainVerifierHash[:sha1.Size]) {
+		return ErrPasswordInvalid, nil
+	}
+	return decryptAESECB(key, encrypted[:len(encrypted)-len(encrypted)%aes.BlockSize])
+}
+
+// xlsRC4Key derives RC4 key of 1024-byte stream block
+type xlsRC4Key func(block uint32) []byte
+
+// decryptXLSWorkbook decrypts records of Workbook stream, FILEPASS record is replaced by unknown record of same size
+func decryptXLSWorkbook(cfb *cfbFile, password string) (error, []byte) {
+	err, stream := cfb.readStream("Workbook")
+	if nil != err {
+		return err, nil
+	}
+	filePass := findXLSFilePass(stream)
+	if nil == filePass || len(filePass) < 6 {
+		return fmt.Errorf("FILEPASS record is not found"), nil
+	}
+	if 0 == binary.LittleEndian.Uint16(filePass) {
+		return fmt.Errorf("XOR obfuscation is not supported for XLS format"), nil
+	}
+	major, minor := binary.LittleEndian.Uint16(filePass[2:]), binary.LittleEndian.Uint16(filePass[4:])
+	var deriveKey xlsRC4Key
+	var verifier, verifierHash []byte
+	var h hash.Hash
+	switch {
+	case 1 == major && 1 == minor:
+		// binary RC4 with MD5 key derivation
+		if len(filePass) < 6+48 {
+			return fmt.Errorf("FILEPASS record is truncated"), nil
+		}
+		salt := filePass[6:22]
+		verifier, verifierHash = filePass[22:38], filePass[38:54]
+		h = md5.New()
+		truncated := hashBytes(h, passwordUTF16LE(password))[:5]
+		intermediate := hashBytes(h, bytes.Repeat(append(append([]byte{}, truncated...), salt...), 16))[:5]
+		deriveKey = func(block uint32) []byte {
+			return hashBytes(md5.New(), intermediate, le32(block))
+		}
+	case major >= 2 && major <= 4 && 2 == minor:
+		// RC4 CryptoAPI
+		if len(filePass) < 14 {
+			return fmt.Errorf("FILEPASS record is truncated"), nil
+		}
+		headerSize := int(binary.LittleEndian.Uint32(filePass[10:]))
+		if len(filePass) < 14+headerSize+4 || headerSize < 20 {
+			return fmt.Errorf("FILEPASS record is truncated"), nil
+		}
+		keyBits := int(binary.LittleEndian.Uint32(filePass[14+16:]))
+		if 0 == keyBits {
+			keyBits = 40
+		}
+		encryptionVerifier := filePass[14+headerSize:]
+		saltSize := int(binary.LittleEndian.Uint32(encryptionVerifier))
+		if len(encryptionVerifier) < 4+saltSize+16+4+20 {
+			return fmt.Errorf("FILEPASS record is truncated"), nil
+		}
+		salt := encryptionVerifier[4 : 4+saltSize]
+		verifier = encryptionVerifier[4+saltSize : 4+saltSize+16]
+		verifierHash = encryptionVerifier[4+saltSize+20 : 4+saltSize+40]
+		h = sha1.New()
+		base := hashBytes(h, salt, passwordUTF16LE(password))
+		deriveKey = func(block uint32) []byte {
+			key := hashBytes(sha1.New(), base, le32(block))[:keyBits/8]
+			if 40 == keyBits {
+				key = append(key, make([]byte, 11)...)
+			}
+			return key
+		}
+	default:
+		return fmt.Errorf("RC4 encryption v%d.%d is not supported for XLS format", major, minor), nil
+	}
+	rc, err := rc4.NewCipher(deriveKey(0))
+	if nil != err {
+		return err, nil
+	}
+	plain := make([]byte, len(verifier)+len(verifierHash))
+	rc.XORKeyStream(plain, append(append([]byte{}, verifier...), verifierHash...))
+	if !bytes.Equal(hashBytes(h, plain[:len(verifier)]), plain[len(verifier):]) {
+		return ErrPasswordInvalid, nil
+	}
+	return nil, decryptXLSRecords(stream, deriveKey)
+}
+
+// decryptXLSRecords decrypts record payloads, keystream position is absolute stream offset and key changes every 1024 bytes
+func decryptXLSRecords(stream []byte, deriveKey xlsRC4Key) []byte {
+	const blockSize = 1024
+	res := append([]byte{}, stream...)
+	encrypted := false
+	var rc *rc4.Cipher
+	block := uint32(0)
+	position := 0 // keystream position in current block
+	seek := func(offset int) {
+		if nil == rc || uint32(offset/blockSize) != block || offset%blockSize < position {
+			block, position = uint32(offset/blockSize), 0
+			rc, _ = rc4.NewCipher(deriveKey(block))
+		}
+		skip := make([]byte, offset%blockSize-position)
+		rc.XORKeyStream(skip, skip)
+		position = offset % blockSize
+	}
+	xorAt := func(offset int, data []byte) {
+		for len(data) > 0 {
+			seek(offset)
+			n := blockSize - position
+			if n > len(data) {
+				n = len(data)
+			}
+			rc.XORKeyStream(data[:n], data[:n])
+			offset, data, position = offset+n, data[n:], position+n
+		}
+	}
+	for offset := 0; offset+4 <= len(res); {
+		recordType := binary.LittleEndian.Uint16(res[offset:])
+		size := int(binary.LittleEndian.Uint16(res[offset+2:]))
+		if offset+4+size > len(res) {
+			break
+		}
+		data := res[offset+4 : offset+4+size]
+		switch {
+		case xlsRecordFilePass == recordType && !encrypted:
+			encrypted = true
+			// record is kept in place so stream offsets of sheets stay valid
+			binary.LittleEndian.PutUint16(res[offset:], 0)
+			for i := range data {
+				data[i] = 0
+			}
+		case !encrypted:
+		case xlsRecordBOF == recordType, xlsRecordUsrExcl == recordType, xlsRecordFileLock == recordType,
+			xlsRecordInterfaceHdr == recordType, xlsRecordRRDInfo == recordType, xlsRecordRRDHead == recordType:
+		case xlsRecordBoundSheet == recordType && size > 4:
+			// lbPlyPos is not encrypted
+			xorAt(offset+8, data[4:])
+		default:
+			xorAt(offset+4, data)
+		}
+		offset += 4 + size
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+package tablescanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixtures are encrypted by password "password", XLSX ones keep "SECRET" in cell A1
+const testPassword = "password"
+
+func readEncryptedPackage(t *testing.T, fileName string) (info []byte, encrypted []byte, size uint64) {
+	err, cfb := openCFB(fileName)
+	if nil != err {
+		t.Fatalf("cannot open %s: %s", fileName, err)
+	}
+	defer nowarnCloseCloser(cfb)
+	err, info = cfb.readStream("EncryptionInfo")
+	if nil != err {
+		t.Fatalf("cannot read EncryptionInfo of %s: %s", fileName, err)
+	}
+	err, encrypted = cfb.readStream("EncryptedPackage")
+	if nil != err {
+		t.Fatalf("cannot read EncryptedPackage of %s: %s", fileName, err)
+	}
+	return info[8:], encrypted[8:], binary.LittleEndian.Uint64(encrypted)
+}
+
+func checkDecryptedPackage(t *testing.T, fileName string, decrypted []byte, size uint64) {
+	if uint64(len(decrypted)) < size {
+		t.Fatalf("%s: decrypted package is %d bytes, expected at least %d", fileName, len(decrypted), size)
+	}
+	err, scanner := openDecryptedPackage(fileName, decrypted[:size])
+	if nil != err {
+		t.Fatalf("%s: cannot open decrypted package: %s", fileName, err)
+	}
+	defer nowarnCloseCloser(scanner)
+	if err := scanner.Scan(); nil != err {
+		t.Fatalf("%s: cannot scan decrypted package: %s", fileName, err)
+	}
+	if row := scanner.GetScanned(); 1 != len(row) || "SECRET" != row[0] {
+		t.Errorf("%s: first row is %q, expected [SECRET]", fileName, row)
+	}
+}
+
+func TestDecryptAgilePackage(t *testing.T) {
+	fileName := filepath.Join("testdata", "encrypted_agile.xlsx")
+	info, encrypted, size := readEncryptedPackage(t, fileName)
+	err, decrypted := decryptAgilePackage(info, encrypted, testPassword)
+	if nil != err {
+		t.Fatalf("decryptAgilePackage: %s", err)
+	}
+	checkDecryptedPackage(t, fileName, decrypted, size)
+	if err, _ := decryptAgilePackage(info, encrypted, "wrong"); !errors.Is(err, ErrPasswordInvalid) {
+		t.Errorf("decryptAgilePackage with wrong password returned %v, expected ErrPasswordInvalid", err)
+	}
+}
+
+func TestDecryptStandardPackage(t *testing.T) {
+	fileName := filepath.Join("testdata", "encrypted_standard.xlsx")
+	info, encrypted, size := readEncryptedPackage(t, fileName)
+	err, decrypted := decryptStandardPackage(info, encrypted, testPassword)
+	if nil != err {
+		t.Fatalf("decryptStandardPackage: %s", err)
+	}
+	checkDecryptedPackage(t, fileName, decrypted, size)
+	if err, _ := decryptStandardPackage(info, encrypted, "wrong"); !errors.Is(err, ErrPasswordInvalid) {
+		t.Errorf("decryptStandardPackage with wrong password returned %v, expected ErrPasswordInvalid", err)
+	}
+}
+
+// TestDecryptXLSRecords checks binary RC4 and RC4 CryptoAPI workbooks, sheet "Table" keeps "description1" in C2
+func TestDecryptXLSRecords(t *testing.T) {
+	for _, name := range []string{"encrypted_rc4.xls", "encrypted_rc4_cryptoapi.xls"} {
+		fileName := filepath.Join("testdata", name)
+		err, cfb := openCFB(fileName)
+		if nil != err {
+			t.Fatalf("cannot open %s: %s", fileName, err)
+		}
+		err, decrypted := decryptXLSWorkbook(cfb, testPassword)
+		if nil != err {
+			t.Fatalf("%s: decryptXLSWorkbook: %s", fileName, err)
+		}
+		if err, _ := decryptXLSWorkbook(cfb, "wrong"); !errors.Is(err, ErrPasswordInvalid) {
+			t.Errorf("%s: decryptXLSWorkbook with wrong password returned %v, expected ErrPasswordInvalid", fileName, err)
+		}
+		nowarnCloseCloser(cfb)
+		if nil != findXLSFilePass(decrypted) {
+			t.Errorf("%s: FILEPASS record is left in decrypted stream", fileName)
+		}
+		if !bytes.Contains(decrypted, []byte("description1")) {
+			t.Errorf("%s: shared strings are not decrypted", fileName)
+		}
+		xls := &xlsHandle{stream: decrypted, sheets: []*xlsTableSheetInfo{{Name: "Table", offset: -1}}}
+		err = walkXLSRecords(decrypted, 0, func(record *xlsRecord) error {
+			if xlsRecordBoundSheet == record.recordType {
+				xls.sheets[0].offset = int(binary.LittleEndian.Uint32(record.data))
+			}
+			return nil
+		})
+		if nil != err {
+			t.Fatalf("%s: decrypted globals are broken: %s", fileName, err)
+		}
+		offset := xls.sheets[0].offset
+		if offset < 0 || offset+2 > len(decrypted) || xlsRecordBOF != binary.LittleEndian.Uint16(decrypted[offset:]) {
+			t.Fatalf("%s: BOUNDSHEET offset %d does not point to BOF record", fileName, offset)
+		}
+		err, meta := xls.requireSheetMeta(0)
+		if nil != err {
+			t.Fatalf("%s: decrypted sheet is broken: %s", fileName, err)
+		}
+		if len(meta.styles[2]) < 3 {
+			t.Errorf("%s: row 2 has %d cells, expected 3", fileName, len(meta.styles[2]))
+		}
+	}
+}
+
+func TestWriteCFB(t *testing.T) {
+	small := []byte("mini stream data")
+	// large stream needs more FAT sectors than header lists, so DIFAT sectors are written
+	large := make([]byte, 8<<20)
+	for i := range large {
+		large[i] = byte(i * 7)
+	}
+	fileName := filepath.Join(t.TempDir(), "written.cfb")
+	file, err := os.Create(fileName)
+	if nil != err {
+		t.Fatal(err)
+	}
+	err = writeCFB(file, []cfbWriteStream{{name: "Workbook", data: large}, {name: "Small", data: small}})
+	if closeErr := file.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		t.Fatalf("writeCFB: %s", err)
+	}
+	err, cfb := openCFB(fileName)
+	if nil != err {
+		t.Fatalf("cannot open written file: %s", err)
+	}
+	defer nowarnCloseCloser(cfb)
+	for name, expected := range map[string][]byte{"Workbook": large, "Small": small} {
+		err, data := cfb.readStream(name)
+		if nil != err {
+			t.Errorf("cannot read stream %s: %s", name, err)
+		} else if !bytes.Equal(expected, data) {
+			t.Errorf("stream %s differs from written one", name)
+		}
+	}
+}
@@ -1,6 +1,5 @@
 package tablescanner
 
-// @todo: detect xlsx additionally by required subfile /xl/workbook.xml to prevent false positive on common ZIPs
 // @todo: implement csv support
 // @todo: implement excel-xml support
 // @todo: implement html support
@@ -12,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 	"unicode/utf16"
 	"unicode/utf8"
 )
@@ -45,11 +45,43 @@ const (
 	TypeExcelWorkbookXLS        TExcelWorkbookType = 2
 	TypeExcelWorkbookXML        TExcelWorkbookType = 3
 	TypeExcelWorkbookSingleHTML TExcelWorkbookType = 4
+	TypeExcelWorkbookXLSB       TExcelWorkbookType = 5
+	TypeExcelWorkbookODS        TExcelWorkbookType = 6
+	TypeExcelWorkbookFODS       TExcelWorkbookType = 7
 )
 
 type ITableSheetInfo interface {
 	GetName() string
 	GetHideLevel() TSheetHideLevel
+	GetTables() []ITableInfo
+}
+
+// ITableInfo describes excel table (ListObject) placed on sheet
+type ITableInfo interface {
+	GetName() string
+	GetSheetId() int
+	GetRef() string
+	GetHeaderRowCount() int
+	GetTotalsRowCount() int
+	GetColumns() []string
+}
+
+// TCellComment is a cell note, threaded comment replies are kept in Replies
+type TCellComment struct {
+	Ref     string
+	Author  string
+	Text    string
+	Replies []TCellComment
+}
+
+// TRichTextRun is formatted fragment of rich text cell, Color is ARGB hex or "theme:N"/"indexed:N" palette reference
+type TRichTextRun struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Strike    bool
+	Underline string // "" when not underlined, otherwise "single"/"double"/"singleAccounting"/"doubleAccounting"
+	Color     string
 }
 
 type IExcelFormatter interface {
@@ -58,11 +90,26 @@ type IExcelFormatter interface {
 	AllowScientific()
 	DenyScientific()
 	SetDateFixedFormat(value string)
+	// SetLocation sets zone of DateValue() results, nil means UTC
+	SetLocation(location *time.Location)
+	// SetLotusCompatibility counts phantom 1900-02-29 for serials below 61 like excel displays them
+	SetLotusCompatibility(enabled bool)
 	SetDecimalSeparator(value string)
 	SetThousandSeparator(value string)
+	// SetGeneralFormatMode selects rendering of General formatted numbers, default is GeneralFormatExact
+	SetGeneralFormatMode(mode TGeneralFormatMode)
+	// SetBoolRendering selects text of bool cells, default is BoolRenderingExcel, SpreadsheetML cells keep stored
+	// "1"/"0" unless rendering is set explicitly
+	SetBoolRendering(rendering TBoolRendering)
+	// SetErrorRendering selects text of error cells, default is ErrorRenderingText
+	SetErrorRendering(rendering TErrorRendering)
 	SetTrimOn()
 	SetTrimOff()
 	FormatValue(cellValue string, cellType string, fullFormat *parsedNumberFormat) (string, error)
+	// DateValue converts raw serial ("n") or ISO 8601 ("d") cell value to time, see DisableFormatting()
+	DateValue(cellValue string, cellType string) (time.Time, error)
+	// CivilDateValue converts raw serial ("n") or ISO 8601 ("d") cell value to calendar date without time zone
+	CivilDateValue(cellValue string, cellType string) (TCivilDate, error)
 }
 
 type ITableDocumentScanner interface {
@@ -73,9 +120,51 @@ type ITableDocumentScanner interface {
 	GetSheets() []ITableSheetInfo
 	GetCurrentSheetId() int
 	SetSheetId(id int) error
+	// GetActiveSheetId returns id of sheet which is active in saved workbook, the sheet is selected on open
+	GetActiveSheetId() int
+	// SetSheetByName selects the first sheet which name matches pattern
+	SetSheetByName(pattern string, match TSheetMatch) error
+	SetFirstVisibleSheet() error
+	SetActiveSheet() error
 	Scan() error
 	GetLastScanError() error
 	GetScanned() []string
+	// GetScannedRowNum returns 1-based sheet row number of scanned row, it differs from count of Scan() calls
+	// when rows are skipped by visibility filter
+	GetScannedRowNum() int
+	// GetScannedColumnIds returns 0-based sheet column indexes of scanned row cells, they differ from cell
+	// positions when hidden columns are dropped by visibility filter
+	GetScannedColumnIds() []int
+	// GetScannedHyperlinks returns hyperlink targets of scanned row cells (empty string for cells without link),
+	// internal document locations are prefixed with '#'
+	GetScannedHyperlinks() []string
+	// GetComments reads cell comments of sheet keyed by cell reference (A1), rows iteration is not affected
+	GetComments(sheetId int) (error, map[string]TCellComment)
+	// SetPhoneticRuns includes phonetic reading runs (furigana) into cell text, they are excluded by default
+	SetPhoneticRuns(enabled bool) error
+	// SetRichText enables collecting of formatted runs returned by GetScannedRichText()
+	SetRichText(enabled bool) error
+	// GetScannedRichText returns formatted runs of scanned row cells, nil for plain text cells
+	GetScannedRichText() [][]TRichTextRun
+	// GetScannedStyles returns style ids of scanned row cells which are resolved by GetStyle()
+	GetScannedStyles() []int
+	// GetScannedErrors returns errors of scanned row cells, nil for cells without error
+	GetScannedErrors() []*TCellError
+	// GetScannedDecimals returns numeric values of scanned row cells as they are stored, empty for other cells
+	GetScannedDecimals() []TDecimal
+	GetStyle(styleId int) (error, *TCellStyle)
+	GetScannedRowInfo() TRowInfo
+	// GetColumnsInfo returns visibility of sheet columns, index 0 is column A
+	GetColumnsInfo(sheetId int) (error, []TColumnInfo)
+	SetVisibilityFilter(filter TVisibilityFilter) error
+	GetVisibilityFilter() TVisibilityFilter
+	// GetAutoFilter returns autofilter range, criteria and sort state of sheet, nil when sheet has no autofilter
+	GetAutoFilter(sheetId int) (error, *TAutoFilter)
+	// SetAutoFilterEvaluation makes Scan() re-evaluate autofilter criteria instead of trusting stored hidden row flags
+	SetAutoFilterEvaluation(enabled bool) error
+	// GetFreezePane returns frozen or split panes of sheet, nil when sheet is not split
+	GetFreezePane(sheetId int) (error, *TFreezePane)
+	GetDataValidations(sheetId int) (error, []TDataValidation)
 }
 
 func NewXLSXStream(fileName string) (error, ITableDocumentScanner) {
@@ -86,75 +175,113 @@ func NewXLSStream(fileName string) (error, ITableDocumentScanner) {
 	return newXLSStream(fileName)
 }
 
+func NewXLSBStream(fileName string) (error, ITableDocumentScanner) {
+	return newXLSBStream(fileName)
+}
+
+// NewODSStream opens OpenDocument spreadsheet, flat is true for single xml document (.fods)
+func NewODSStream(fileName string, flat bool) (error, ITableDocumentScanner) {
+	return newODSStream(fileName, flat)
+}
+
 func NewTableStream(fileName string) (error, ITableDocumentScanner) {
-	err, excelType, textEncoding,bomPresent := DetectExcelContentType(fileName)
+	return newTableStream(fileName, "")
+}
+
+// NewTableStreamWithPassword opens workbook which may be encrypted, ErrPasswordInvalid is returned on password mismatch.
+// Decrypted XLSX/XLSB package is kept in memory, decrypted XLS workbook is written to temporary file which is
+// removed on Close()
+func NewTableStreamWithPassword(fileName string, password string) (error, ITableDocumentScanner) {
+	return newTableStream(fileName, password)
+}
+
+func newTableStream(fileName string, password string) (error, ITableDocumentScanner) {
+	err, detection := DetectContentType(fileName)
 	if nil != err {
 		return err, nil
 	}
-	switch excelType {
+	if detection.Encrypted {
+		return openEncryptedStream(fileName, detection, password)
+	}
+	textEncoding, bomPresent := detection.TextEncoding, detection.BOMPresent
+	switch detection.Type {
 	case TypeExcelWorkbookXLSX:
 		return NewXLSXStream(fileName)
+	case TypeExcelWorkbookXLSB:
+		return NewXLSBStream(fileName)
 	case TypeExcelWorkbookXLS:
 		return NewXLSStream(fileName)
 	case TypeExcelWorkbookXML:
-		return newXMLStream(fileName,textEncoding,bomPresent)
+		return newXMLStream(fileName, textEncoding, bomPresent)
+	case TypeExcelWorkbookODS:
+		return NewODSStream(fileName, false)
+	case TypeExcelWorkbookFODS:
+		return NewODSStream(fileName, true)
 	}
-	return fmt.Errorf("file %s has unsupported format", fileName), nil
+	return fmt.Errorf("file %s has unsupported format: %s", fileName, detection.Reason), nil
 }
 
+// DetectExcelContentType is kept for compatibility, DetectContentType additionally reports confidence and reason
 func DetectExcelContentType(fileName string) (err error, bookType TExcelWorkbookType, textEncoding TTextEnconding, BOMPresent []byte) {
-	signatureXLSX := []byte("\x50\x4B\x03\x04\x14")
-	signatureXLS := []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	err, detection := DetectContentType(fileName)
+	return err, detection.Type, detection.TextEncoding, detection.BOMPresent
+}
+
+// DetectContentType detects workbook type by file signature, zip packages are detected by central directory entries
+func DetectContentType(fileName string) (err error, detection TContentDetection) {
+	signatureZIP := []byte("\x50\x4B") // local file header, empty archive or spanning marker follows
+	signatureXLS := signatureCFB
 	signatureXML := []byte("<?xml")
 	signatureHTML := []byte("<html")
 	//
-	bookType = TypeExcelWorkbookUnknown
-	textEncoding = EncodingUnknown
 	file, err := os.Open(fileName)
 	if nil != err {
-		bookType = TypeExcelWorkbookUnknown
 		return
 	}
 	defer nowarnCloseCloser(file)
-	signature := make([]byte, 64)
-	_, err = file.Read(signature)
+	signature := make([]byte, 4096) // flat OpenDocument and SpreadsheetML declare namespaces of root element first
+	n, err := file.Read(signature)
 	if err != nil {
 		err = fmt.Errorf("cannot detect content type of file %s: %s", fileName, err)
 		return
 	}
-	if len(signature) >= len(signatureXLSX) && bytes.Equal(signatureXLSX, signature[0:len(signatureXLSX)]) {
-		bookType = TypeExcelWorkbookXLSX
-		textEncoding = EncodingUTF8
-		return
+	signature = signature[:n]
+	if len(signature) >= len(signatureZIP) && bytes.Equal(signatureZIP, signature[0:len(signatureZIP)]) {
+		return nil, detectZipContentType(fileName)
 	}
 	if len(signature) >= len(signatureXLS) && bytes.Equal(signatureXLS, signature[0:len(signatureXLS)]) {
-		bookType = TypeExcelWorkbookXLS
-		textEncoding = EncodingUTF8
-		return
+		return nil, detectCFBContentType(fileName)
 	}
 	// text-based formats allowed below this point only
 	if len(signature) >= len(signatureBOMUTF8) && bytes.Equal(signatureBOMUTF8, signature[0:len(signatureBOMUTF8)]) {
-		BOMPresent = signatureBOMUTF8
-		textEncoding = EncodingUTF8
+		detection.BOMPresent = signatureBOMUTF8
+		detection.TextEncoding = EncodingUTF8
 		signature = signature[len(signatureBOMUTF8):]
 	} else if len(signature) >= len(signatureBOMUTF16BE) && bytes.Equal(signatureBOMUTF16BE, signature[0:len(signatureBOMUTF16BE)]) {
-		BOMPresent = signatureBOMUTF16BE
-		textEncoding = EncodingUTF16BE
+		detection.BOMPresent = signatureBOMUTF16BE
+		detection.TextEncoding = EncodingUTF16BE
 		signature = signature[len(signatureBOMUTF16BE):]
 		signature = UTF16BytesToUTF8Bytes(signature, binary.BigEndian)
 	} else if len(signature) >= len(signatureBOMUTF16LE) && bytes.Equal(signatureBOMUTF16LE, signature[0:len(signatureBOMUTF16LE)]) {
-		BOMPresent = signatureBOMUTF16LE
-		textEncoding = EncodingUTF16LE
+		detection.BOMPresent = signatureBOMUTF16LE
+		detection.TextEncoding = EncodingUTF16LE
 		signature = signature[len(signatureBOMUTF16LE):]
 		signature = UTF16BytesToUTF8Bytes(signature, binary.LittleEndian)
 	}
 	if len(signature) >= len(signatureXML) && bytes.Equal(signatureXML, signature[0:len(signatureXML)]) {
-		return nil, TypeExcelWorkbookXML, textEncoding, BOMPresent
+		detectXMLContentType(signature, &detection)
+		return nil, detection
 	}
 	if len(signature) >= len(signatureHTML) && bytes.Equal(signatureHTML, signature[0:len(signatureHTML)]) {
-		return nil, TypeExcelWorkbookSingleHTML, textEncoding, BOMPresent
+		detection.Type = TypeExcelWorkbookSingleHTML
+		detection.Confidence = DetectionConfidenceLow
+		detection.Reason = "<html> tag found"
+		return nil, detection
+	}
+	if len(signature) > 8 {
+		signature = signature[:8]
 	}
-	return nil, TypeExcelWorkbookUnknown, EncodingUnknown, BOMPresent
+	return nil, TContentDetection{Reason: fmt.Sprintf("unknown file signature %q", signature), BOMPresent: detection.BOMPresent}
 }
 
 func UTF16BytesToUTF8Bytes(b []byte, o binary.ByteOrder) []byte {
@@ -0,0 +1,147 @@
+package tablescanner
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// TFreezePane is frozen (or split) panes position, Rows and Columns are counts of rows/columns kept above/left of scrollable pane
+type TFreezePane struct {
+	Frozen      bool // false for split panes which are scrolled independently
+	Rows        int
+	Columns     int
+	TopLeftCell string // first visible cell of bottom-right pane, "" when unknown
+}
+
+type THeaderReason string
+
+const (
+	HeaderReasonNone       THeaderReason = ""
+	HeaderReasonFreezePane THeaderReason = "freezePane" // rows above frozen pane
+	HeaderReasonBold       THeaderReason = "bold"       // leading rows with all cells bold
+	HeaderReasonStringOnly THeaderReason = "stringOnly" // text row followed by row containing numbers or dates
+	HeaderReasonDense      THeaderReason = "dense"      // first row filled at least as half of the widest row
+)
+
+// THeaderRows is guessed header position, row numbers are 1-based, zero LastRow means header was not detected
+type THeaderRows struct {
+	FirstRow int
+	LastRow  int
+	Reason   THeaderReason
+}
+
+type headerSampleRow struct {
+	rowNum   int
+	filled   int  // non-empty cells count
+	bold     bool // all non-empty cells are bold
+	textOnly bool // no numeric-like non-empty cells
+}
+
+// DetectHeader guesses header rows of sheet by frozen panes, falling back to heuristics over first sampleRows rows:
+// bold row, string-only row followed by data and first dense row; row numbers are sheet rows of GetScannedRowNum(),
+// scanner is rewound to the sheet start afterwards
+func DetectHeader(scanner ITableDocumentScanner, sheetId int, sampleRows int) (error, THeaderRows) {
+	err, pane := scanner.GetFreezePane(sheetId)
+	if nil == err && nil != pane && pane.Frozen && pane.Rows > 0 {
+		return nil, THeaderRows{FirstRow: 1, LastRow: pane.Rows, Reason: HeaderReasonFreezePane}
+	}
+	err = scanner.SetSheetId(sheetId)
+	if nil != err {
+		return err, THeaderRows{}
+	}
+	defer func() { _ = scanner.SetSheetId(sheetId) }()
+	styleBold := make(map[int]bool)
+	rows := make([]headerSampleRow, 0, sampleRows)
+	widest := 0
+	for sampled := 0; sampled < sampleRows; sampled++ {
+		err = scanner.Scan()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err, THeaderRows{}
+		}
+		values := scanner.GetScanned()
+		styles := scanner.GetScannedStyles()
+		row := headerSampleRow{rowNum: scanner.GetScannedRowNum(), bold: true, textOnly: true}
+		for i, value := range values {
+			if "" == strings.TrimSpace(value) {
+				continue
+			}
+			row.filled++
+			if isDataLikeValue(value) {
+				row.textOnly = false
+			}
+			styleId := 0
+			if i < len(styles) {
+				styleId = styles[i]
+			}
+			bold, ok := styleBold[styleId]
+			if !ok {
+				styleErr, style := scanner.GetStyle(styleId)
+				bold = nil == styleErr && style.Font.Bold
+				styleBold[styleId] = bold
+			}
+			row.bold = row.bold && bold
+		}
+		if row.filled > widest {
+			widest = row.filled
+		}
+		rows = append(rows, row)
+	}
+	dense := func(row *headerSampleRow) bool {
+		return row.filled > 0 && row.filled*2 >= widest
+	}
+	for i := range rows {
+		if !dense(&rows[i]) || !rows[i].bold {
+			continue
+		}
+		last := i
+		for last+1 < len(rows) && rows[last+1].filled > 0 && rows[last+1].bold {
+			last++
+		}
+		if last+1 == len(rows) {
+			// whole sample is bold, so boldness tells nothing
+			break
+		}
+		return nil, THeaderRows{FirstRow: rows[i].rowNum, LastRow: rows[last].rowNum, Reason: HeaderReasonBold}
+	}
+	for i := range rows {
+		if !dense(&rows[i]) || !rows[i].textOnly {
+			continue
+		}
+		last := i
+		for j := i + 1; j < len(rows); j++ {
+			if 0 == rows[j].filled {
+				continue
+			}
+			if !rows[j].textOnly {
+				return nil, THeaderRows{FirstRow: rows[i].rowNum, LastRow: rows[last].rowNum, Reason: HeaderReasonStringOnly}
+			}
+			last = j
+		}
+		break
+	}
+	for i := range rows {
+		if dense(&rows[i]) {
+			return nil, THeaderRows{FirstRow: rows[i].rowNum, LastRow: rows[i].rowNum, Reason: HeaderReasonDense}
+		}
+	}
+	return nil, THeaderRows{}
+}
+
+// isDataLikeValue tells if formatted value looks like number, percent, date or time
+func isDataLikeValue(value string) bool {
+	hasDigit := false
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case strings.ContainsRune(" \u00a0.,-+/:%()$€£¥", r):
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
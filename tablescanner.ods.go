@@ -0,0 +1,940 @@
+package tablescanner
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nsODSOffice  = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsODSTable   = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsODSText    = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsODSStyle   = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	nsODSNumber  = "urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"
+	nsODSConfig  = "urn:oasis:names:tc:opendocument:xmlns:config:1.0"
+	nsODSXlink   = "http://www.w3.org/1999/xlink"
+	nsODSCalcExt = "urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0"
+)
+
+// odsMaxColumns limits materializing of repeated non-empty cells and columns
+const odsMaxColumns = 16384
+
+type odsTableSheetInfo struct {
+	Name         string
+	HideLevel    TSheetHideLevel
+	columns      []TColumnInfo // <table:table-column> declarations, nil until sheet scanning or columns reading
+	columnStyles []string      // column default cell style names, trailing columns with default style are omitted
+}
+
+// odsStream reads content.xml of OpenDocument spreadsheet or the whole flat .fods document,
+// empty repeated rows and cells are never materialized
+type odsStream struct {
+	formatter                 excelFormatter
+	i18n                      *tI18n   // reference to selected i18n config
+	fmtI18n                   []string // excel built-in number formats depending on system locale
+	flat                      bool     // single xml document (.fods) instead of zip package
+	fileName                  string
+	z                         *zip.ReadCloser
+	contentFile               *zip.File
+	sheets                    []*odsTableSheetInfo
+	sheetSelected             int                            // default-opening sheet id
+	activeTable               string                         // settings ActiveTable value
+	tableHidden               map[string]bool                // table style name to table:display="false"
+	dataStyles                map[string]*odsDataStyle       // data style name to converted number format
+	cellStyles                map[string]*odsCellStyle       // table-cell style name to raw formatting
+	styleNames                []string                       // style id to cell style name, id 0 is the default style
+	styleIds                  map[string]int                 // cell style name to style id
+	styleCache                []*TCellStyle                  // style id to resolved style, nil until requested
+	numFmtCache               map[string]*parsedNumberFormat // number format code to parsed one
+	iteratorLastError         error                          // error which caused last Scan() failed
+	iteratorRowNum            int                            // row number that Scan() implies
+	iteratorScannedRowNum     int                            // current row number fetched by reading, starting with 1
+	iteratorNextRowNum        int                            // row number of next <table:table-row>, repeated rows included
+	iteratorRowRepeat         int                            // count of copies of scanned row which are not yet returned
+	iteratorScannedData       []string                       // current row-iterating row data
+	iteratorScannedStyles     []int                          // current row-iterating row style ids
+	iteratorScannedHyperlinks []string                       // current row-iterating row text:a targets
+	iteratorScannedErrors     []*TCellError                  // current row-iterating row error cells
+	iteratorScannedDecimals   []TDecimal                     // current row-iterating row office:value of numeric cells
+	iteratorScannedRowInfo    TRowInfo                       // current row-iterating row visibility
+	iteratorOutlineLevel      int                            // depth of <table:table-row-group> and <table:table-column-group>
+	iteratorColumnNum         int                            // count of declared columns, repeated ones included
+	visibilityFilter          TVisibilityFilter              // rows and columns to be skipped while scanning
+	iteratorSheetId           int                            // current row-iterating sheet id
+	iteratorStream            io.ReadCloser                  // current row-iterating xml stream
+	iteratorDecoder           *xml.Decoder                   // statefull decoder object for iterator
+	iteratorCapacity          int                            // default result slice capacity, synchronizes while Scan()
+}
+
+func newODSStream(fileName string, flat bool) (error, ITableDocumentScanner) {
+	var err error
+	ods := &odsStream{
+		fileName:    fileName,
+		flat:        flat,
+		tableHidden: make(map[string]bool),
+		dataStyles:  make(map[string]*odsDataStyle),
+		cellStyles:  make(map[string]*odsCellStyle),
+		styleNames:  []string{""},
+		styleIds:    map[string]int{"": 0},
+	}
+	err = ods.SetI18n("en")
+	if nil != err {
+		return err, nil
+	}
+	if flat {
+		err = ods.readDocumentPart(fileName)
+		if nil != err {
+			return err, nil
+		}
+	} else {
+		ods.z, err = zip.OpenReader(fileName)
+		if err != nil {
+			return err, nil
+		}
+		for _, path := range []string{"styles.xml", "settings.xml", "content.xml"} {
+			err = ods.readDocumentPart(path)
+			if nil != err {
+				_ = ods.z.Close()
+				return err, nil
+			}
+		}
+		if nil == ods.contentFile {
+			_ = ods.z.Close()
+			return fmt.Errorf("content.xml not found in [%s]", fileName), nil
+		}
+	}
+	for i, sheet := range ods.sheets {
+		if sheet.Name == ods.activeTable {
+			ods.sheetSelected = i
+		}
+	}
+	if ods.sheetSelected < len(ods.sheets) && TableSheetVisible != ods.sheets[ods.sheetSelected].HideLevel {
+		ods.sheetSelected = 0
+	}
+	_ = ods.SetSheetId(ods.sheetSelected)
+	return nil, ods
+}
+
+// odsAttr returns attribute value by namespace and local name, "" when absent
+func odsAttr(tok *xml.StartElement, space string, local string) string {
+	for _, attr := range tok.Attr {
+		if attr.Name.Local == local && attr.Name.Space == space {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// odsAttrInt returns positive integer attribute value, 1 when absent or invalid
+func odsAttrInt(tok *xml.StartElement, space string, local string) int {
+	value, err := strconv.Atoi(odsAttr(tok, space, local))
+	if nil != err || value < 1 {
+		return 1
+	}
+	return value
+}
+
+// openPart opens zip package part or the flat document itself
+func (ods *odsStream) openPart(path string) (io.ReadCloser, error) {
+	if ods.flat {
+		return os.Open(ods.fileName)
+	}
+	for _, file := range ods.z.File {
+		if file.Name == path {
+			if "content.xml" == path {
+				ods.contentFile = file
+			}
+			return file.Open()
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// readDocumentPart collects data styles, cell styles, active table and table list of document part,
+// table contents are skipped
+func (ods *odsStream) readDocumentPart(path string) error {
+	rc, err := ods.openPart(path)
+	if os.ErrNotExist == err {
+		return nil
+	}
+	if nil != err {
+		return fmt.Errorf("file stream [%s] Open() failed: %s", path, err)
+	}
+	defer nowarnCloseCloser(rc)
+	decoder := xml.NewDecoder(rc)
+	for {
+		tok, err := decoder.Token()
+		if io.EOF == err {
+			return nil
+		}
+		if nil != err {
+			return fmt.Errorf("xml token read error in [%s] at pos %d: %s", path, decoder.InputOffset(), err)
+		}
+		startTok, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case nsODSNumber == startTok.Name.Space && strings.HasSuffix(startTok.Name.Local, "-style"):
+			err, style := readDataStyle(decoder, &startTok)
+			if nil != err {
+				return fmt.Errorf("cannot read data style in [%s]: %s", path, err)
+			}
+			ods.dataStyles[odsAttr(&startTok, nsODSStyle, "name")] = style
+		case nsODSStyle == startTok.Name.Space && "style" == startTok.Name.Local:
+			name := odsAttr(&startTok, nsODSStyle, "name")
+			switch odsAttr(&startTok, nsODSStyle, "family") {
+			case "table-cell":
+				err, style := readCellStyle(decoder, &startTok)
+				if nil != err {
+					return fmt.Errorf("cannot read cell style in [%s]: %s", path, err)
+				}
+				ods.cellStyles[name] = style
+			case "table":
+				err, style := readCellStyle(decoder, &startTok)
+				if nil != err {
+					return fmt.Errorf("cannot read table style in [%s]: %s", path, err)
+				}
+				ods.tableHidden[name] = "false" == style.attrs["display"]
+			default:
+				_ = decoder.Skip()
+			}
+		case nsODSConfig == startTok.Name.Space && "config-item" == startTok.Name.Local:
+			if "ActiveTable" != odsAttr(&startTok, nsODSConfig, "name") {
+				_ = decoder.Skip()
+				continue
+			}
+			err, ods.activeTable = readXmlElementText(decoder)
+			if nil != err {
+				return fmt.Errorf("cannot read ActiveTable in [%s]: %s", path, err)
+			}
+		case nsODSTable == startTok.Name.Space && "table" == startTok.Name.Local:
+			sheet := &odsTableSheetInfo{Name: odsAttr(&startTok, nsODSTable, "name")}
+			if ods.tableHidden[odsAttr(&startTok, nsODSTable, "style-name")] {
+				sheet.HideLevel = TableSheetHidden
+			}
+			ods.sheets = append(ods.sheets, sheet)
+			_ = decoder.Skip()
+		}
+	}
+}
+
+// openTable positions decoder right after <table:table> start of sheet
+func (ods *odsStream) openTable(sheetId int) (error, io.ReadCloser, *xml.Decoder) {
+	rc, err := ods.openPart("content.xml")
+	if nil != err {
+		return fmt.Errorf("sheet #%d not found: %s", sheetId, err), nil, nil
+	}
+	decoder := xml.NewDecoder(rc)
+	tableId := 0
+	for {
+		tok, err := decoder.Token()
+		if nil != err {
+			_ = rc.Close()
+			return fmt.Errorf("sheet #%d not found: %s", sheetId, err), nil, nil
+		}
+		startTok, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case nsODSTable == startTok.Name.Space && "table" == startTok.Name.Local:
+			if tableId == sheetId {
+				return nil, rc, decoder
+			}
+			tableId++
+			_ = decoder.Skip()
+		case nsODSOffice == startTok.Name.Space && "body" != startTok.Name.Local && "spreadsheet" != startTok.Name.Local && "document" != startTok.Name.Local && "document-content" != startTok.Name.Local:
+			// styles, settings and metadata
+			_ = decoder.Skip()
+		}
+	}
+}
+
+func (sheet *odsTableSheetInfo) GetName() string {
+	return sheet.Name
+}
+
+func (sheet *odsTableSheetInfo) GetHideLevel() TSheetHideLevel {
+	return sheet.HideLevel
+}
+
+func (sheet *odsTableSheetInfo) GetTables() []ITableInfo {
+	// tables (ListObjects) are not supported by this format
+	return []ITableInfo{}
+}
+
+// addColumn stores <table:table-column> declaration, columnNum is count of previously declared columns
+func (sheet *odsTableSheetInfo) addColumn(tok *xml.StartElement, columnNum int, outlineLevel int) int {
+	repeat := odsAttrInt(tok, nsODSTable, "number-columns-repeated")
+	if columnNum+repeat > odsMaxColumns {
+		repeat = odsMaxColumns - columnNum
+	}
+	visibility := odsAttr(tok, nsODSTable, "visibility")
+	info := TColumnInfo{Hidden: "collapse" == visibility || "filter" == visibility, OutlineLevel: outlineLevel}
+	if info.Hidden || outlineLevel > 0 {
+		sheet.columns = setColumnsInfo(sheet.columns, columnNum+1, columnNum+repeat, info)
+	}
+	if style := odsAttr(tok, nsODSTable, "default-cell-style-name"); "" != style && "Default" != style {
+		for len(sheet.columnStyles) < columnNum {
+			sheet.columnStyles = append(sheet.columnStyles, "")
+		}
+		for i := 0; i < repeat; i++ {
+			sheet.columnStyles = append(sheet.columnStyles, style)
+		}
+	}
+	return columnNum + repeat
+}
+
+func (ods *odsStream) Close() error {
+	if nil != ods.iteratorStream {
+		_ = ods.iteratorStream.Close()
+		ods.iteratorStream = nil
+	}
+	if nil != ods.z {
+		return ods.z.Close()
+	}
+	return nil
+}
+
+func (ods *odsStream) FormatterAvailable() bool {
+	return false
+}
+
+func (ods *odsStream) Formatter() IExcelFormatter {
+	return &ods.formatter
+}
+
+func (ods *odsStream) SetI18n(code string) error {
+	if _, ok := numFmtI18n[code]; !ok {
+		return fmt.Errorf("Unknown i18n[%s]", code)
+	}
+	ods.fmtI18n = []string{}
+	for id, numFmt := range numFmtI18n[strings.ToLower(code)].numFmtDefaults {
+		for len(ods.fmtI18n) < id+1 {
+			ods.fmtI18n = append(ods.fmtI18n, "")
+		}
+		ods.fmtI18n[id] = numFmt
+	}
+	ods.i18n = numFmtI18n[code]
+	ods.formatter.setI18n(ods.i18n)
+	ods.numFmtCache = make(map[string]*parsedNumberFormat)
+	return nil
+}
+
+func (ods *odsStream) GetSheets() []ITableSheetInfo {
+	res := make([]ITableSheetInfo, len(ods.sheets))
+	for i, sheet := range ods.sheets {
+		res[i] = sheet
+	}
+	return res
+}
+
+func (ods *odsStream) GetCurrentSheetId() int {
+	return ods.iteratorSheetId
+}
+
+func (ods *odsStream) SetSheetId(id int) error {
+	if err := checkSheetId(id, len(ods.sheets)); nil != err {
+		return err
+	}
+	ods.iteratorLastError = nil
+	ods.iteratorCapacity = 0
+	ods.iteratorRowNum = 0
+	ods.iteratorScannedRowNum = 0
+	ods.iteratorNextRowNum = 1
+	ods.iteratorRowRepeat = 0
+	ods.iteratorOutlineLevel = 0
+	ods.iteratorColumnNum = 0
+	ods.iteratorScannedData = []string{}
+	ods.iteratorScannedStyles = []int{}
+	ods.iteratorScannedHyperlinks = []string{}
+	ods.iteratorScannedErrors = nil
+	ods.iteratorScannedDecimals = nil
+	if nil != ods.iteratorStream {
+		_ = ods.iteratorStream.Close()
+		ods.iteratorStream = nil // force rewind
+	}
+	ods.iteratorSheetId = id
+	return nil
+}
+
+func (ods *odsStream) GetLastScanError() error {
+	return ods.iteratorLastError
+}
+
+func (ods *odsStream) requireScanStream() error {
+	if nil == ods.iteratorStream {
+		err, rc, decoder := ods.openTable(ods.iteratorSheetId)
+		if nil != err {
+			return err
+		}
+		ods.iteratorStream = rc
+		ods.iteratorDecoder = decoder
+		sheet := ods.sheets[ods.iteratorSheetId]
+		sheet.columns = []TColumnInfo{}
+		sheet.columnStyles = nil
+	}
+	return nil
+}
+
+func (ods *odsStream) Scan() (err error) {
+	for {
+		// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
+		if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+			ods.iteratorRowNum++
+		} else {
+			err = ods.scanInternal()
+			if nil == err {
+				ods.iteratorRowNum++
+			}
+		}
+		if nil != err || !ods.visibilityFilter.isRowFiltered(ods.GetScannedRowInfo()) {
+			break
+		}
+	}
+	ods.iteratorLastError = err
+	return err
+}
+
+// scanInternal reads the next non-empty row, empty rows are only counted
+func (ods *odsStream) scanInternal() error {
+	if ods.iteratorRowRepeat > 0 {
+		ods.iteratorRowRepeat--
+		ods.iteratorScannedRowNum++
+		return nil
+	}
+	err := ods.requireScanStream()
+	if nil != err {
+		return err
+	}
+	sheet := ods.sheets[ods.iteratorSheetId]
+	for {
+		tok, err := ods.iteratorDecoder.Token()
+		if nil != err {
+			offset := ods.iteratorDecoder.InputOffset()
+			_ = ods.SetSheetId(ods.iteratorSheetId)
+			return fmt.Errorf("xml token read error in sheet [%s] at pos %d: %s", sheet.Name, offset, err)
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			if nsODSTable != tok.Name.Space {
+				continue
+			}
+			switch tok.Name.Local {
+			case "table":
+				_ = ods.SetSheetId(ods.iteratorSheetId)
+				return io.EOF
+			case "table-row-group", "table-column-group":
+				ods.iteratorOutlineLevel--
+			}
+		case xml.StartElement:
+			if nsODSTable != tok.Name.Space {
+				_ = ods.iteratorDecoder.Skip()
+				continue
+			}
+			switch tok.Name.Local {
+			case "table-row-group", "table-column-group":
+				ods.iteratorOutlineLevel++
+			case "table-header-rows", "table-rows", "table-header-columns", "table-columns":
+				// transparent containers
+			case "table-column":
+				ods.iteratorColumnNum = sheet.addColumn(&tok, ods.iteratorColumnNum, ods.iteratorOutlineLevel)
+				_ = ods.iteratorDecoder.Skip()
+			case "table-row":
+				err = ods.readRow(&tok)
+				if nil != err {
+					offset := ods.iteratorDecoder.InputOffset()
+					_ = ods.SetSheetId(ods.iteratorSheetId)
+					return fmt.Errorf("cannot parse row #%d of sheet [%s] at pos %d: %s", ods.iteratorNextRowNum, sheet.Name, offset, err)
+				}
+				if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+					return nil
+				}
+			default:
+				_ = ods.iteratorDecoder.Skip()
+			}
+		}
+	}
+}
+
+// readRow reads <table:table-row>, scanned data is replaced only when row has non-empty cells
+func (ods *odsStream) readRow(tok *xml.StartElement) error {
+	rowNum := ods.iteratorNextRowNum
+	repeat := odsAttrInt(tok, nsODSTable, "number-rows-repeated")
+	ods.iteratorNextRowNum += repeat
+	rowStyle := odsAttr(tok, nsODSTable, "default-cell-style-name")
+	data := make([]string, 0, ods.iteratorCapacity)
+	styles := make([]int, 0, ods.iteratorCapacity)
+	hyperlinks := []string{}
+	var cellErrors []*TCellError
+	var decimals []TDecimal
+	pending := 0 // empty cells not yet appended
+	for {
+		cellTok, err := ods.iteratorDecoder.Token()
+		if nil != err {
+			return err
+		}
+		if endTok, ok := cellTok.(xml.EndElement); ok && nsODSTable == endTok.Name.Space && "table-row" == endTok.Name.Local {
+			break
+		}
+		startTok, ok := cellTok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if nsODSTable != startTok.Name.Space || ("table-cell" != startTok.Name.Local && "covered-cell" != startTok.Name.Local) {
+			_ = ods.iteratorDecoder.Skip()
+			continue
+		}
+		cellRepeat := odsAttrInt(&startTok, nsODSTable, "number-columns-repeated")
+		err, value, styleId, hyperlink, cellError := ods.readCell(&startTok, len(data)+pending, rowStyle)
+		if nil != err {
+			return err
+		}
+		decimal := odsCellDecimal(&startTok)
+		if "" == value && "" == hyperlink && nil == cellError && "" == decimal {
+			pending += cellRepeat
+			continue
+		}
+		for ; pending > 0 && len(data) < odsMaxColumns; pending-- {
+			data = append(data, "")
+			styles = append(styles, 0)
+		}
+		pending = 0
+		for i := 0; i < cellRepeat && len(data) < odsMaxColumns; i++ {
+			if "" != hyperlink {
+				for len(hyperlinks) < len(data) {
+					hyperlinks = append(hyperlinks, "")
+				}
+				hyperlinks = append(hyperlinks, hyperlink)
+			}
+			if nil != cellError {
+				for len(cellErrors) < len(data) {
+					cellErrors = append(cellErrors, nil)
+				}
+				cellErrors = append(cellErrors, cellError)
+			}
+			if "" != decimal {
+				for len(decimals) < len(data) {
+					decimals = append(decimals, "")
+				}
+				decimals = append(decimals, decimal)
+			}
+			data = append(data, value)
+			styles = append(styles, styleId)
+		}
+	}
+	if 0 == len(data) {
+		return nil
+	}
+	if len(data) > ods.iteratorCapacity {
+		ods.iteratorCapacity = len(data)
+	}
+	visibility := odsAttr(tok, nsODSTable, "visibility")
+	ods.iteratorScannedRowNum = rowNum
+	ods.iteratorRowRepeat = repeat - 1
+	ods.iteratorScannedData = data
+	ods.iteratorScannedStyles = styles
+	ods.iteratorScannedHyperlinks = hyperlinks
+	ods.iteratorScannedErrors = cellErrors
+	ods.iteratorScannedDecimals = decimals
+	ods.iteratorScannedRowInfo = TRowInfo{Hidden: "collapse" == visibility || "filter" == visibility, OutlineLevel: ods.iteratorOutlineLevel}
+	return nil
+}
+
+// odsCellDecimal returns office:value of float, percentage and currency cells
+func odsCellDecimal(tok *xml.StartElement) TDecimal {
+	switch odsAttr(tok, nsODSOffice, "value-type") {
+	case "float", "percentage", "currency":
+		return TDecimal(strings.TrimSpace(odsAttr(tok, nsODSOffice, "value")))
+	}
+	return ""
+}
+
+// readCell reads cell up to its end and formats typed value by data style, text paragraphs are used as fallback,
+// error cells are marked by calcext:value-type and keep error code as text
+func (ods *odsStream) readCell(tok *xml.StartElement, column int, rowStyle string) (err error, value string, styleId int, hyperlink string, cellError *TCellError) {
+	err, text, hyperlink := readODSCellText(ods.iteratorDecoder)
+	if nil != err {
+		return err, "", 0, "", nil
+	}
+	styleName := odsAttr(tok, nsODSTable, "style-name")
+	if "" == styleName {
+		styleName = rowStyle
+	}
+	if columnStyles := ods.sheets[ods.iteratorSheetId].columnStyles; "" == styleName && column < len(columnStyles) {
+		styleName = columnStyles[column]
+	}
+	styleId = ods.getStyleId(styleName)
+	if "error" == odsAttr(tok, nsODSCalcExt, "value-type") && "" != text {
+		formatted, _ := ods.formatter.FormatValue(text, strCellTypeError, nil)
+		return nil, formatted, styleId, hyperlink, &TCellError{Code: text}
+	}
+	valueType := odsAttr(tok, nsODSOffice, "value-type")
+	cellType := strCellTypeNumeric
+	cellValue := ""
+	switch valueType {
+	case "float", "percentage", "currency":
+		cellValue = odsAttr(tok, nsODSOffice, "value")
+	case "date":
+		if serial, ok := parseODSDate(odsAttr(tok, nsODSOffice, "date-value")); ok {
+			cellValue = strconv.FormatFloat(serial, 'f', -1, 64)
+		}
+	case "time":
+		if serial, ok := parseODSDuration(odsAttr(tok, nsODSOffice, "time-value")); ok {
+			cellValue = strconv.FormatFloat(serial, 'f', -1, 64)
+		}
+	case "boolean":
+		cellType = strCellTypeBool
+		cellValue = "0"
+		if "true" == odsAttr(tok, nsODSOffice, "boolean-value") {
+			cellValue = "1"
+		}
+	case "string":
+		cellType = strCellTypeInline
+		cellValue = text
+		for _, attr := range tok.Attr {
+			if nsODSOffice == attr.Name.Space && "string-value" == attr.Name.Local {
+				cellValue = attr.Value
+			}
+		}
+	}
+	if "" == cellValue {
+		return nil, text, styleId, hyperlink, nil
+	}
+	formatted, err := ods.formatter.FormatValue(cellValue, cellType, ods.getParsedNumFmt(styleId, valueType, cellValue))
+	if nil != err {
+		return nil, text, styleId, hyperlink, nil
+	}
+	return nil, formatted, styleId, hyperlink, nil
+}
+
+// readODSCellText concatenates cell paragraphs by line feeds, annotations (comments) are skipped
+func readODSCellText(decoder *xml.Decoder) (err error, text string, hyperlink string) {
+	var res strings.Builder
+	paragraphs := 0
+	for depth := 1; depth > 0; {
+		tok, err := decoder.Token()
+		if nil != err {
+			return err, "", ""
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if nsODSOffice == tok.Name.Space && "annotation" == tok.Name.Local {
+				_ = decoder.Skip()
+				continue
+			}
+			depth++
+			if nsODSText != tok.Name.Space {
+				continue
+			}
+			switch tok.Name.Local {
+			case "p", "h":
+				if paragraphs > 0 {
+					res.WriteString("\n")
+				}
+				paragraphs++
+			case "s":
+				res.WriteString(strings.Repeat(" ", odsAttrInt(&tok, nsODSText, "c")))
+			case "tab":
+				res.WriteString("\t")
+			case "line-break":
+				res.WriteString("\n")
+			case "a":
+				if "" == hyperlink {
+					hyperlink = odsAttr(&tok, nsODSXlink, "href")
+				}
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if paragraphs > 0 && depth > 1 {
+				res.Write(tok)
+			}
+		}
+	}
+	return nil, res.String(), hyperlink
+}
+
+// parseODSDate converts office:date-value to excel serial date
+func parseODSDate(value string) (float64, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02", "2006-01-02T15:04:05.999999999Z07:00"} {
+		date, err := time.Parse(layout, value)
+		if nil == err {
+			base := time.Date(1899, 12, 30, 0, 0, 0, 0, date.Location())
+			return float64(date.Sub(base)) / float64(24*time.Hour), true
+		}
+	}
+	return 0, false
+}
+
+// parseODSDuration converts office:time-value like "PT12H30M15.5S" to fraction of day
+func parseODSDuration(value string) (float64, bool) {
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, false
+	}
+	days := 0.0
+	isTime := false
+	number := ""
+	for _, r := range value[1:] {
+		if 'T' == r {
+			isTime = true
+			continue
+		}
+		if ('0' <= r && r <= '9') || '.' == r {
+			number += string(r)
+			continue
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if nil != err {
+			return 0, false
+		}
+		number = ""
+		switch {
+		case 'D' == r:
+			days += n
+		case 'H' == r && isTime:
+			days += n / 24
+		case 'M' == r && isTime:
+			days += n / 1440
+		case 'S' == r && isTime:
+			days += n / 86400
+		default:
+			// years and months have no fixed length
+			return 0, false
+		}
+	}
+	if "" != number {
+		return 0, false
+	}
+	if negative {
+		days = -days
+	}
+	return days, true
+}
+
+// getStyleId assigns style ids to cell style names in order of appearance
+func (ods *odsStream) getStyleId(name string) int {
+	if styleId, ok := ods.styleIds[name]; ok {
+		return styleId
+	}
+	ods.styleIds[name] = len(ods.styleNames)
+	ods.styleNames = append(ods.styleNames, name)
+	return len(ods.styleNames) - 1
+}
+
+func (ods *odsStream) requireStyle(styleId int) *TCellStyle {
+	for len(ods.styleCache) < len(ods.styleNames) {
+		ods.styleCache = append(ods.styleCache, nil)
+	}
+	if nil == ods.styleCache[styleId] {
+		name := ods.styleNames[styleId]
+		if "" == name {
+			name = "Default"
+		}
+		ods.styleCache[styleId] = ods.resolveCellStyle(name)
+	}
+	return ods.styleCache[styleId]
+}
+
+// getParsedNumFmt returns number format of style, date and time values without data style get locale defaults
+func (ods *odsStream) getParsedNumFmt(styleId int, valueType string, cellValue string) *parsedNumberFormat {
+	numFmt := ods.requireStyle(styleId).NumFmt
+	if "" == numFmt {
+		switch valueType {
+		case "date":
+			numFmt = ods.fmtI18n[14]
+			if strings.Contains(cellValue, ".") {
+				numFmt = ods.fmtI18n[22]
+			}
+		case "time":
+			numFmt = ods.fmtI18n[21]
+		}
+	}
+	if parsed, ok := ods.numFmtCache[numFmt]; ok {
+		return parsed
+	}
+	ods.numFmtCache[numFmt] = parseNumFmt(numFmt)
+	return ods.numFmtCache[numFmt]
+}
+
+func (ods *odsStream) GetScannedRowNum() int {
+	return ods.iteratorRowNum
+}
+
+func (ods *odsStream) GetScannedColumnIds() []int {
+	return scannedColumnIds(ods.visibleColumnIds(len(ods.iteratorScannedData)), len(ods.GetScanned()))
+}
+
+func (ods *odsStream) GetScanned() []string {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return []string{}
+	}
+	return pickStrings(ods.iteratorScannedData, ods.visibleColumnIds(len(ods.iteratorScannedData)))
+}
+
+func (ods *odsStream) GetScannedHyperlinks() []string {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return []string{}
+	}
+	res := make([]string, len(ods.iteratorScannedData))
+	copy(res, ods.iteratorScannedHyperlinks)
+	return pickStrings(res, ods.visibleColumnIds(len(res)))
+}
+
+func (ods *odsStream) GetScannedErrors() []*TCellError {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return []*TCellError{}
+	}
+	res := make([]*TCellError, len(ods.iteratorScannedData))
+	copy(res, ods.iteratorScannedErrors)
+	return pickCellErrors(res, ods.visibleColumnIds(len(res)))
+}
+
+func (ods *odsStream) GetScannedDecimals() []TDecimal {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return []TDecimal{}
+	}
+	res := make([]TDecimal, len(ods.iteratorScannedData))
+	copy(res, ods.iteratorScannedDecimals)
+	return pickDecimals(res, ods.visibleColumnIds(len(res)))
+}
+
+func (ods *odsStream) GetScannedStyles() []int {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return []int{}
+	}
+	return pickInts(ods.iteratorScannedStyles, ods.visibleColumnIds(len(ods.iteratorScannedStyles)))
+}
+
+// GetStyle resolves style id returned by GetScannedStyles(), only font, fill, alignment and number format are filled
+func (ods *odsStream) GetStyle(styleId int) (error, *TCellStyle) {
+	if styleId < 0 || styleId >= len(ods.styleNames) {
+		return fmt.Errorf("style #%d not found", styleId), nil
+	}
+	style := *ods.requireStyle(styleId)
+	return nil, &style
+}
+
+func (ods *odsStream) GetScannedRowInfo() TRowInfo {
+	if ods.iteratorScannedRowNum > ods.iteratorRowNum {
+		return TRowInfo{}
+	}
+	return ods.iteratorScannedRowInfo
+}
+
+// GetColumnsInfo reads column declarations preceding the first row of sheet
+func (ods *odsStream) GetColumnsInfo(sheetId int) (error, []TColumnInfo) {
+	if sheetId < 0 || sheetId >= len(ods.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	sheet := ods.sheets[sheetId]
+	if nil != sheet.columns {
+		return nil, sheet.columns
+	}
+	err, rc, decoder := ods.openTable(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	defer nowarnCloseCloser(rc)
+	columns := &odsTableSheetInfo{columns: []TColumnInfo{}}
+	columnNum := 0
+	outlineLevel := 0
+	for declarations := true; declarations; {
+		tok, err := decoder.Token()
+		if nil != err {
+			return fmt.Errorf("xml token read error in sheet [%s] at pos %d: %s", sheet.Name, decoder.InputOffset(), err), nil
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			if nsODSTable == tok.Name.Space && "table-column-group" == tok.Name.Local {
+				outlineLevel--
+			}
+			declarations = nsODSTable != tok.Name.Space || "table" != tok.Name.Local
+		case xml.StartElement:
+			if nsODSTable != tok.Name.Space {
+				_ = decoder.Skip()
+				continue
+			}
+			switch tok.Name.Local {
+			case "table-column-group":
+				outlineLevel++
+			case "table-column":
+				columnNum = columns.addColumn(&tok, columnNum, outlineLevel)
+				_ = decoder.Skip()
+			case "table-header-columns", "table-columns":
+				// transparent containers
+			case "table-row", "table-row-group", "table-header-rows", "table-rows":
+				// columns are declared before rows
+				declarations = false
+			default:
+				_ = decoder.Skip()
+			}
+		}
+	}
+	sheet.columns = columns.columns
+	sheet.columnStyles = columns.columnStyles
+	return nil, sheet.columns
+}
+
+func (ods *odsStream) SetVisibilityFilter(filter TVisibilityFilter) error {
+	ods.visibilityFilter = filter
+	return nil
+}
+
+func (ods *odsStream) GetVisibilityFilter() TVisibilityFilter {
+	return ods.visibilityFilter
+}
+
+func (ods *odsStream) visibleColumnIds(rowLength int) []int {
+	return ods.visibilityFilter.visibleColumnIds(ods.sheets[ods.iteratorSheetId].columns, rowLength)
+}
+
+func (ods *odsStream) GetComments(sheetId int) (error, map[string]TCellComment) {
+	return fmt.Errorf("comments are not supported for ODS format"), nil
+}
+
+func (ods *odsStream) SetPhoneticRuns(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("phonetic runs are not supported for ODS format")
+	}
+	return nil
+}
+
+func (ods *odsStream) SetRichText(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("rich text is not supported for ODS format")
+	}
+	return nil
+}
+
+func (ods *odsStream) GetScannedRichText() [][]TRichTextRun {
+	return make([][]TRichTextRun, len(ods.GetScanned()))
+}
+
+func (ods *odsStream) GetAutoFilter(sheetId int) (error, *TAutoFilter) {
+	return fmt.Errorf("autofilter is not supported for ODS format"), nil
+}
+
+func (ods *odsStream) SetAutoFilterEvaluation(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("autofilter evaluation is not supported for ODS format")
+	}
+	return nil
+}
+
+func (ods *odsStream) GetFreezePane(sheetId int) (error, *TFreezePane) {
+	return fmt.Errorf("freeze panes are not supported for ODS format"), nil
+}
+
+func (ods *odsStream) GetDataValidations(sheetId int) (error, []TDataValidation) {
+	return fmt.Errorf("data validations are not supported for ODS format"), nil
+}
@@ -0,0 +1,300 @@
+package tablescanner
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// odsDataStyle is number:*-style converted to excel number format code
+type odsDataStyle struct {
+	kind   string // "number"/"percentage"/"currency"/"date"/"time"/"boolean"/"text"
+	format string
+	maps   []odsDataStyleMap
+}
+
+type odsDataStyleMap struct {
+	condition string // like "value()>=0"
+	styleName string
+}
+
+// odsCellStyle keeps raw formatting attributes of style:style family="table-cell", inherited ones are resolved on request
+type odsCellStyle struct {
+	parent string
+	attrs  map[string]string // attribute local name to value, data style is kept as "data-style-name"
+}
+
+// readDataStyle converts children of number:*-style element to excel format code
+func readDataStyle(decoder *xml.Decoder, tok *xml.StartElement) (error, *odsDataStyle) {
+	style := &odsDataStyle{kind: strings.TrimSuffix(tok.Name.Local, "-style")}
+	truncateHours := "false" != odsAttr(tok, nsODSNumber, "truncate-on-overflow")
+	var format strings.Builder
+	for {
+		childTok, err := decoder.Token()
+		if nil != err {
+			return err, nil
+		}
+		switch childTok := childTok.(type) {
+		case xml.EndElement:
+			if childTok.Name == tok.Name {
+				style.format = format.String()
+				return nil, style
+			}
+		case xml.StartElement:
+			if nsODSStyle == childTok.Name.Space && "map" == childTok.Name.Local {
+				style.maps = append(style.maps, odsDataStyleMap{
+					condition: strings.Replace(odsAttr(&childTok, nsODSStyle, "condition"), " ", "", -1),
+					styleName: odsAttr(&childTok, nsODSStyle, "apply-style-name"),
+				})
+				_ = decoder.Skip()
+				continue
+			}
+			if nsODSNumber != childTok.Name.Space {
+				_ = decoder.Skip()
+				continue
+			}
+			long := "long" == odsAttr(&childTok, nsODSNumber, "style")
+			part := ""
+			switch childTok.Name.Local {
+			case "text", "currency-symbol":
+				err, text := readXmlElementText(decoder)
+				if nil != err {
+					return err, nil
+				}
+				format.WriteString(odsFormatLiteral(text, style.kind, "text" == childTok.Name.Local))
+				continue
+			case "number":
+				part = odsNumberPart(&childTok, true)
+			case "scientific-number":
+				exponentDigits, _ := strconv.Atoi(odsAttr(&childTok, nsODSNumber, "min-exponent-digits"))
+				if exponentDigits < 2 {
+					exponentDigits = 2
+				}
+				part = odsNumberPart(&childTok, false) + "E+" + strings.Repeat("0", exponentDigits)
+			case "fraction":
+				if integerDigits, _ := strconv.Atoi(odsAttr(&childTok, nsODSNumber, "min-integer-digits")); integerDigits > 0 {
+					part = "# "
+				}
+				numeratorDigits, _ := strconv.Atoi(odsAttr(&childTok, nsODSNumber, "min-numerator-digits"))
+				denominatorDigits, _ := strconv.Atoi(odsAttr(&childTok, nsODSNumber, "min-denominator-digits"))
+				part += strings.Repeat("?", maxInt(numeratorDigits, 1)) + "/"
+				if denominator := odsAttr(&childTok, nsODSNumber, "denominator-value"); "" != denominator {
+					part += denominator
+				} else {
+					part += strings.Repeat("?", maxInt(denominatorDigits, 1))
+				}
+			case "text-content":
+				part = "@"
+			case "year":
+				part = odsLongPart(long, "yyyy", "yy")
+			case "month":
+				if "true" == odsAttr(&childTok, nsODSNumber, "textual") {
+					part = odsLongPart(long, "mmmm", "mmm")
+				} else {
+					part = odsLongPart(long, "mm", "m")
+				}
+			case "day":
+				part = odsLongPart(long, "dd", "d")
+			case "day-of-week":
+				part = odsLongPart(long, "dddd", "ddd")
+			case "hours":
+				part = odsLongPart(long, "hh", "h")
+				if "time" == style.kind && !truncateHours {
+					// elapsed time is not wrapped by 24 hours
+					part = "[" + part + "]"
+					truncateHours = true
+				}
+			case "minutes":
+				part = odsLongPart(long, "mm", "m")
+			case "seconds":
+				part = odsLongPart(long, "ss", "s")
+				if decimals, _ := strconv.Atoi(odsAttr(&childTok, nsODSNumber, "decimal-places")); decimals > 0 {
+					part += "." + strings.Repeat("0", decimals)
+				}
+			case "am-pm":
+				part = "am/pm"
+			}
+			format.WriteString(part)
+			_ = decoder.Skip()
+		}
+	}
+}
+
+// odsNumberPart converts number:number (or mantissa of number:scientific-number) attributes to digit placeholders
+func odsNumberPart(tok *xml.StartElement, groupingAllowed bool) string {
+	integerDigits := 1
+	if value := odsAttr(tok, nsODSNumber, "min-integer-digits"); "" != value {
+		integerDigits, _ = strconv.Atoi(value)
+	}
+	integerPart := strings.Repeat("0", integerDigits)
+	if groupingAllowed && "true" == odsAttr(tok, nsODSNumber, "grouping") {
+		for len(integerPart) < 4 {
+			integerPart = "#" + integerPart
+		}
+		integerPart = integerPart[:1] + "," + integerPart[1:]
+	} else if "" == integerPart {
+		integerPart = "#"
+	}
+	decimalDigits, err := strconv.Atoi(odsAttr(tok, nsODSNumber, "decimal-places"))
+	if nil != err {
+		// precision is not limited by style
+		return "general"
+	}
+	minDecimalDigits := decimalDigits
+	if value := odsAttr(tok, nsODSNumber, "min-decimal-places"); "" != value {
+		minDecimalDigits, _ = strconv.Atoi(value)
+	}
+	if decimalDigits <= 0 {
+		return integerPart
+	}
+	if minDecimalDigits > decimalDigits {
+		minDecimalDigits = decimalDigits
+	}
+	return integerPart + "." + strings.Repeat("0", minDecimalDigits) + strings.Repeat("#", decimalDigits-minDecimalDigits)
+}
+
+func odsLongPart(long bool, longPart string, shortPart string) string {
+	if long {
+		return longPart
+	}
+	return shortPart
+}
+
+// odsFormatLiteral escapes literal text of data style, date separators are kept unquoted because
+// date formatting does not support quoted literals
+func odsFormatLiteral(text string, kind string, percentAllowed bool) string {
+	if "date" == kind || "time" == kind {
+		if "" == strings.Trim(text, " .,-/:") {
+			return text
+		}
+	}
+	var res strings.Builder
+	for _, chunk := range strings.SplitAfter(text, "%") {
+		literal := chunk
+		percent := percentAllowed && "percentage" == kind && strings.HasSuffix(chunk, "%")
+		if percent {
+			literal = strings.TrimSuffix(chunk, "%")
+		}
+		if "" != literal {
+			res.WriteString("\"" + strings.Replace(literal, "\"", "\"\\\"\"", -1) + "\"")
+		}
+		if percent {
+			res.WriteString("%")
+		}
+	}
+	return res.String()
+}
+
+// dataStyleFormat returns excel format code of data style, the first sign condition map is turned into format section
+func (ods *odsStream) dataStyleFormat(name string) string {
+	style, ok := ods.dataStyles[name]
+	if !ok {
+		return ""
+	}
+	for _, styleMap := range style.maps {
+		mapped, ok := ods.dataStyles[styleMap.styleName]
+		if !ok {
+			continue
+		}
+		switch styleMap.condition {
+		case "value()>=0", "value()>0":
+			return mapped.format + ";" + style.format
+		case "value()<0", "value()<=0":
+			return style.format + ";" + mapped.format
+		}
+	}
+	return style.format
+}
+
+// readCellStyle collects formatting attributes of table-cell (or table) style and its property elements
+func readCellStyle(decoder *xml.Decoder, tok *xml.StartElement) (error, *odsCellStyle) {
+	style := &odsCellStyle{parent: odsAttr(tok, nsODSStyle, "parent-style-name"), attrs: make(map[string]string)}
+	if dataStyle := odsAttr(tok, nsODSStyle, "data-style-name"); "" != dataStyle {
+		style.attrs["data-style-name"] = dataStyle
+	}
+	for depth := 1; depth > 0; {
+		childTok, err := decoder.Token()
+		if nil != err {
+			return err, nil
+		}
+		switch childTok := childTok.(type) {
+		case xml.StartElement:
+			depth++
+			switch childTok.Name.Local {
+			case "text-properties", "table-cell-properties", "paragraph-properties", "table-properties":
+				for _, attr := range childTok.Attr {
+					style.attrs[attr.Name.Local] = attr.Value
+				}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil, style
+}
+
+// resolveCellStyle merges attributes of style and its ancestors, nearest definition wins
+func (ods *odsStream) resolveCellStyle(name string) *TCellStyle {
+	attrs := make(map[string]string)
+	for depth := 0; depth < 16; depth++ {
+		style, ok := ods.cellStyles[name]
+		if !ok {
+			break
+		}
+		for key, value := range style.attrs {
+			if _, exists := attrs[key]; !exists {
+				attrs[key] = value
+			}
+		}
+		name = style.parent
+	}
+	res := &TCellStyle{NumFmt: ods.dataStyleFormat(attrs["data-style-name"])}
+	res.Font.Name = attrs["font-name"]
+	res.Font.Size, _ = strconv.ParseFloat(strings.TrimSuffix(attrs["font-size"], "pt"), 64)
+	res.Font.Bold = "bold" == attrs["font-weight"] || "700" == attrs["font-weight"] || "800" == attrs["font-weight"] || "900" == attrs["font-weight"]
+	res.Font.Italic = "italic" == attrs["font-style"] || "oblique" == attrs["font-style"]
+	res.Font.Strike = "" != attrs["text-line-through-style"] && "none" != attrs["text-line-through-style"]
+	if underline := attrs["text-underline-style"]; "" != underline && "none" != underline {
+		res.Font.Underline = "single"
+		if "double" == attrs["text-underline-type"] {
+			res.Font.Underline = "double"
+		}
+	}
+	res.Font.Color = odsColor(attrs["color"])
+	if background := odsColor(attrs["background-color"]); "" != background {
+		res.Fill = TCellFill{PatternType: "solid", FgColor: background}
+	}
+	switch attrs["text-align"] {
+	case "start", "left":
+		res.Alignment.Horizontal = "left"
+	case "center":
+		res.Alignment.Horizontal = "center"
+	case "end", "right":
+		res.Alignment.Horizontal = "right"
+	case "justify":
+		res.Alignment.Horizontal = "justify"
+	}
+	switch attrs["vertical-align"] {
+	case "top", "bottom":
+		res.Alignment.Vertical = attrs["vertical-align"]
+	case "middle":
+		res.Alignment.Vertical = "center"
+	}
+	res.Alignment.WrapText = "wrap" == attrs["wrap-option"]
+	return res
+}
+
+// odsColor converts "#rrggbb" to ARGB hex, "transparent" and other keywords are dropped
+func odsColor(value string) string {
+	if 7 != len(value) || '#' != value[0] {
+		return ""
+	}
+	return "FF" + strings.ToUpper(value[1:])
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,61 @@
+package tablescanner
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// sample.ods and sample.fods keep the same table: header, typed row with data styles, two repeated empty rows,
+// row with single number and trailing rows and columns repeated up to the sheet limits
+func TestODSScan(t *testing.T) {
+	expected := [][]string{
+		{"Name", "Amount", "Pct", "Date", "Time", "Bool"},
+		{"a", "-1,234.50", "12.5%", "31.01.2021", "12:30", "FALSE"},
+		{},
+		{},
+		{"", "", "42"},
+	}
+	for _, fileName := range []string{"sample.ods", "sample.fods"} {
+		err, scanner := NewTableStream(filepath.Join("testdata", fileName))
+		if nil != err {
+			t.Fatalf("%s: %s", fileName, err)
+		}
+		scanned := [][]string{}
+		for nil == scanner.Scan() {
+			scanned = append(scanned, scanner.GetScanned())
+			if 2 != scanner.GetScannedRowNum() {
+				continue
+			}
+			decimals := scanner.GetScannedDecimals()
+			if "-1234.5" != decimals[1].String() || "0.125" != decimals[2].String() {
+				t.Errorf("%s: row 2 decimals are %q", fileName, decimals)
+			}
+			err, style := scanner.GetStyle(scanner.GetScannedStyles()[1])
+			if nil != err || "#,##0.00" != style.NumFmt {
+				t.Errorf("%s: amount style is %+v, error %v", fileName, style, err)
+			}
+		}
+		if io.EOF != scanner.GetLastScanError() {
+			t.Errorf("%s: scan is finished by %v", fileName, scanner.GetLastScanError())
+		}
+		if !reflect.DeepEqual(expected, scanned) {
+			t.Errorf("%s: scanned rows are %q, expected %q", fileName, scanned, expected)
+		}
+		nowarnCloseCloser(scanner)
+	}
+}
+
+func TestDetectFODS(t *testing.T) {
+	err, detection := DetectContentType(filepath.Join("testdata", "sample.fods"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if TypeExcelWorkbookFODS != detection.Type || DetectionConfidenceHigh != detection.Confidence || WorkbookKindWorkbook != detection.Kind {
+		t.Errorf("flat OpenDocument is detected as %+v", detection)
+	}
+	if err, detection = DetectContentType(filepath.Join("testdata", "sample.ods")); nil != err || TypeExcelWorkbookODS != detection.Type {
+		t.Errorf("OpenDocument package is detected as %+v, error %v", detection, err)
+	}
+}
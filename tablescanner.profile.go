@@ -0,0 +1,412 @@
+package tablescanner
+
+import (
+	"container/heap"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TColumnType string
+
+const (
+	ColumnTypeEmpty  TColumnType = "empty"
+	ColumnTypeInt    TColumnType = "int"
+	ColumnTypeFloat  TColumnType = "float"
+	ColumnTypeDate   TColumnType = "date"
+	ColumnTypeBool   TColumnType = "bool"
+	ColumnTypeString TColumnType = "string"
+)
+
+// TColumnProfile is statistics of column values, Min and Max are rendered for dominant type:
+// numbers as stored, dates as RFC 3339 and strings compared bytewise
+type TColumnProfile struct {
+	Column           int                 `json:"column"` // 0-based index in GetScanned() row
+	Name             string              `json:"name,omitempty"`
+	Type             TColumnType         `json:"type"`
+	TypeCounts       map[TColumnType]int `json:"typeCounts"`
+	NullCount        int                 `json:"nullCount"`
+	NullRatio        float64             `json:"nullRatio"`
+	ErrorCount       int                 `json:"errorCount"`
+	Min              string              `json:"min,omitempty"`
+	Max              string              `json:"max,omitempty"`
+	DistinctEstimate int                 `json:"distinctEstimate"`
+	Samples          []string            `json:"samples,omitempty"`
+}
+
+// TSheetProfile is schema report of sheet which is ready for json.Marshal
+type TSheetProfile struct {
+	SheetId   int              `json:"sheetId"`
+	SheetName string           `json:"sheetName"`
+	Header    THeaderRows      `json:"header"`
+	Rows      int              `json:"rows"` // profiled rows, header excluded
+	Columns   []TColumnProfile `json:"columns"`
+}
+
+type TProfileOptions struct {
+	HeaderRows int // sheet rows 1..HeaderRows are column names, negative value detects them by DetectHeader()
+	MaxRows    int // profiled rows limit, 0 means whole sheet
+	Samples    int // distinct sample values kept per column, 0 means 5, negative value disables samples
+}
+
+const (
+	profileDefaultSamples = 5
+	profileHeaderSample   = 20   // rows inspected by DetectHeader()
+	profileDistinctHashes = 1024 // k of k-minimum-values distinct estimator
+)
+
+// profileDateLayouts are tried for formatted values of cells without typed date
+var profileDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"02.01.2006",
+	"02.01.2006 15:04",
+	"02.01.2006 15:04:05",
+	"1/2/2006",
+	"1/2/2006 15:04",
+	"1/2/06",
+	"2-Jan-06",
+	"02-Jan-06",
+	"2 January 2006",
+	"January 2, 2006",
+}
+
+type columnProfiler struct {
+	profile    TColumnProfile
+	numberMin  *big.Rat
+	numberMax  *big.Rat
+	numberText [2]string // stored text of min and max
+	dateMin    time.Time
+	dateMax    time.Time
+	dateSeen   bool
+	stringMin  string
+	stringMax  string
+	stringSeen bool
+	distinct   distinctEstimator
+	samples    map[string]bool
+}
+
+// ProfileSheet scans sheet and infers type and statistics of every column; typed errors, decimals and number
+// formats are used when backend provides them, formatted strings are inspected otherwise;
+// scanner is rewound to the sheet start afterwards
+func ProfileSheet(scanner ITableDocumentScanner, sheetId int, options TProfileOptions) (error, *TSheetProfile) {
+	if sheetId < 0 || sheetId >= len(scanner.GetSheets()) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	if 0 == options.Samples {
+		options.Samples = profileDefaultSamples
+	}
+	result := &TSheetProfile{SheetId: sheetId, SheetName: scanner.GetSheets()[sheetId].GetName()}
+	if options.HeaderRows < 0 {
+		err, header := DetectHeader(scanner, sheetId, profileHeaderSample)
+		if nil != err {
+			return err, nil
+		}
+		result.Header = header
+	} else if options.HeaderRows > 0 {
+		result.Header = THeaderRows{FirstRow: 1, LastRow: options.HeaderRows}
+	}
+	err := scanner.SetSheetId(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	defer func() { _ = scanner.SetSheetId(sheetId) }()
+	classifier := newCellClassifier(scanner)
+	columns := []*columnProfiler{}
+	names := []string{}
+	for 0 == options.MaxRows || result.Rows < options.MaxRows {
+		err = scanner.Scan()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err, nil
+		}
+		values := scanner.GetScanned()
+		if rowNum := scanner.GetScannedRowNum(); rowNum <= result.Header.LastRow {
+			if rowNum >= result.Header.FirstRow {
+				names = values
+			}
+			continue
+		}
+		result.Rows++
+		cellErrors := scanner.GetScannedErrors()
+		decimals := scanner.GetScannedDecimals()
+		styles := scanner.GetScannedStyles()
+		for len(columns) < len(values) {
+			column := &columnProfiler{samples: make(map[string]bool)}
+			column.profile.Column = len(columns)
+			column.profile.TypeCounts = make(map[TColumnType]int)
+			column.profile.NullCount = result.Rows - 1 // rows preceding first cell of column
+			columns = append(columns, column)
+		}
+		for i, column := range columns {
+			value := ""
+			if i < len(values) {
+				value = values[i]
+			}
+			if i < len(cellErrors) && nil != cellErrors[i] {
+				column.profile.ErrorCount++
+				column.profile.NullCount++
+				continue
+			}
+			if "" == strings.TrimSpace(value) {
+				column.profile.NullCount++
+				continue
+			}
+			column.add(value, classifier.classify(i, value, decimals, styles), options.Samples)
+		}
+	}
+	result.Columns = make([]TColumnProfile, len(columns))
+	for i, column := range columns {
+		if i < len(names) {
+			column.profile.Name = strings.TrimSpace(names[i])
+		}
+		result.Columns[i] = column.finish(result.Rows)
+	}
+	return nil, result
+}
+
+// tTypedCell is non-empty cell classified by stored number, number format or formatted value
+type tTypedCell struct {
+	columnType TColumnType
+	number     *big.Rat // int and float cells
+	text       string   // stored or formatted number text, formatted value of string cells
+	date       time.Time
+	flag       bool // bool cells
+}
+
+// tCellClassifier classifies cells of scanned rows, date formats of styles are cached
+type tCellClassifier struct {
+	scanner     ITableDocumentScanner
+	formatter   IExcelFormatter // requested by first date cell, XLS backend warns when formatter is requested
+	styleIsDate map[int]bool
+}
+
+func newCellClassifier(scanner ITableDocumentScanner) *tCellClassifier {
+	return &tCellClassifier{scanner: scanner, styleIsDate: make(map[int]bool)}
+}
+
+// classify types non-empty cell of scanned row, typed decimal has priority over formatted value heuristics
+func (classifier *tCellClassifier) classify(column int, value string, decimals []TDecimal, styles []int) tTypedCell {
+	if column < len(decimals) && "" != decimals[column] {
+		decimal := decimals[column]
+		if column < len(styles) && classifier.isDateStyle(styles[column]) {
+			if nil == classifier.formatter {
+				classifier.formatter = classifier.scanner.Formatter()
+			}
+			if date, err := classifier.formatter.DateValue(string(decimal), strCellTypeNumeric); nil == err {
+				return tTypedCell{columnType: ColumnTypeDate, date: date, text: value}
+			}
+		}
+		if number, err := decimal.Rat(); nil == err {
+			return newNumberCell(number, string(decimal))
+		}
+	}
+	trimmed := strings.TrimSpace(value)
+	if flag, ok := parseProfileBool(trimmed); ok {
+		return tTypedCell{columnType: ColumnTypeBool, flag: flag, text: value}
+	}
+	if number, ok := parseProfileNumber(trimmed); ok {
+		return newNumberCell(number, trimmed)
+	}
+	for _, layout := range profileDateLayouts {
+		if date, err := time.Parse(layout, trimmed); nil == err {
+			return tTypedCell{columnType: ColumnTypeDate, date: date, text: value}
+		}
+	}
+	return tTypedCell{columnType: ColumnTypeString, text: value}
+}
+
+func (classifier *tCellClassifier) isDateStyle(styleId int) bool {
+	isDate, ok := classifier.styleIsDate[styleId]
+	if !ok {
+		err, style := classifier.scanner.GetStyle(styleId)
+		isDate = nil == err && nil != style && isTimeFormat(style.NumFmt)
+		classifier.styleIsDate[styleId] = isDate
+	}
+	return isDate
+}
+
+func newNumberCell(number *big.Rat, text string) tTypedCell {
+	if number.IsInt() {
+		return tTypedCell{columnType: ColumnTypeInt, number: number, text: text}
+	}
+	return tTypedCell{columnType: ColumnTypeFloat, number: number, text: text}
+}
+
+func (column *columnProfiler) add(value string, cell tTypedCell, samples int) {
+	column.distinct.add(value)
+	if len(column.samples) < samples && !column.samples[value] {
+		column.samples[value] = true
+		column.profile.Samples = append(column.profile.Samples, value)
+	}
+	column.profile.TypeCounts[cell.columnType]++
+	switch cell.columnType {
+	case ColumnTypeInt, ColumnTypeFloat:
+		column.addNumber(cell.number, cell.text)
+	case ColumnTypeDate:
+		column.addDate(cell.date)
+	case ColumnTypeString:
+		if !column.stringSeen || value < column.stringMin {
+			column.stringMin = value
+		}
+		if !column.stringSeen || value > column.stringMax {
+			column.stringMax = value
+		}
+		column.stringSeen = true
+	}
+}
+
+func (column *columnProfiler) addNumber(number *big.Rat, text string) {
+	if nil == column.numberMin || number.Cmp(column.numberMin) < 0 {
+		column.numberMin = number
+		column.numberText[0] = text
+	}
+	if nil == column.numberMax || number.Cmp(column.numberMax) > 0 {
+		column.numberMax = number
+		column.numberText[1] = text
+	}
+}
+
+func (column *columnProfiler) addDate(date time.Time) {
+	if !column.dateSeen || date.Before(column.dateMin) {
+		column.dateMin = date
+	}
+	if !column.dateSeen || date.After(column.dateMax) {
+		column.dateMax = date
+	}
+	column.dateSeen = true
+}
+
+// finish picks the most frequent type, integers are widened to floats when both are present
+func (column *columnProfiler) finish(rows int) TColumnProfile {
+	profile := column.profile
+	profile.Type = ColumnTypeEmpty
+	best := 0
+	for _, columnType := range []TColumnType{ColumnTypeString, ColumnTypeInt, ColumnTypeFloat, ColumnTypeDate, ColumnTypeBool} {
+		count := profile.TypeCounts[columnType]
+		if ColumnTypeFloat == columnType {
+			count += profile.TypeCounts[ColumnTypeInt]
+		}
+		if count > best {
+			best = count
+			profile.Type = columnType
+		}
+	}
+	if ColumnTypeInt == profile.Type && profile.TypeCounts[ColumnTypeFloat] > 0 {
+		profile.Type = ColumnTypeFloat
+	}
+	switch profile.Type {
+	case ColumnTypeInt, ColumnTypeFloat:
+		profile.Min, profile.Max = column.numberText[0], column.numberText[1]
+	case ColumnTypeDate:
+		profile.Min, profile.Max = column.dateMin.Format(time.RFC3339), column.dateMax.Format(time.RFC3339)
+	case ColumnTypeString:
+		profile.Min, profile.Max = column.stringMin, column.stringMax
+	}
+	if rows > 0 {
+		profile.NullRatio = float64(profile.NullCount) / float64(rows)
+	}
+	profile.DistinctEstimate = column.distinct.estimate()
+	return profile
+}
+
+// parseProfileBool parses bool rendered by any i18n
+func parseProfileBool(value string) (bool, bool) {
+	for _, i18n := range numFmtI18n {
+		for id, name := range i18n.boolNames {
+			if "" != name && strings.EqualFold(name, value) {
+				return 1 == id, true
+			}
+		}
+	}
+	return false, false
+}
+
+var (
+	reProfileCommaGrouping = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d*)?$`) // 1,234,567.89
+	reProfileDotGrouping   = regexp.MustCompile(`^[+-]?\d{1,3}(\.\d{3})+(,\d*)?$`) // 1.234.567,89
+)
+
+// parseProfileNumber parses formatted number, space, comma and dot thousand separators and single decimal comma
+// are accepted, "1,234" is taken as grouped integer and "1.234" as fraction
+func parseProfileNumber(value string) (*big.Rat, bool) {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(value)
+	switch {
+	case reProfileCommaGrouping.MatchString(value):
+		value = strings.Replace(value, ",", "", -1)
+	case reProfileDotGrouping.MatchString(value) && (strings.Contains(value, ",") || strings.Count(value, ".") > 1):
+		value = strings.Replace(strings.Replace(value, ".", "", -1), ",", ".", 1)
+	case strings.Count(value, ",") == 1 && !strings.Contains(value, "."):
+		value = strings.Replace(value, ",", ".", 1)
+	case strings.Contains(value, ","):
+		return nil, false
+	}
+	if "" == value || strings.ContainsAny(value, "xXpP_") { // hex floats and underscores are not spreadsheet numbers
+		return nil, false
+	}
+	if f, err := strconv.ParseFloat(value, 64); nil != err || math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, false
+	}
+	number, ok := new(big.Rat).SetString(value)
+	return number, ok
+}
+
+// distinctEstimator counts distinct values by k minimum hash values, count is exact below k values
+type distinctEstimator struct {
+	hashes hashMaxHeap
+	known  map[uint64]bool
+}
+
+func (estimator *distinctEstimator) add(value string) {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(value))
+	hash := hasher.Sum64()
+	if nil == estimator.known {
+		estimator.known = make(map[uint64]bool)
+	}
+	if estimator.known[hash] {
+		return
+	}
+	if len(estimator.hashes) < profileDistinctHashes {
+		estimator.known[hash] = true
+		heap.Push(&estimator.hashes, hash)
+		return
+	}
+	if hash >= estimator.hashes[0] {
+		return
+	}
+	delete(estimator.known, estimator.hashes[0])
+	estimator.known[hash] = true
+	estimator.hashes[0] = hash
+	heap.Fix(&estimator.hashes, 0)
+}
+
+func (estimator *distinctEstimator) estimate() int {
+	if len(estimator.hashes) < profileDistinctHashes {
+		return len(estimator.hashes)
+	}
+	return int(float64(profileDistinctHashes-1) / (float64(estimator.hashes[0]) / math.MaxUint64))
+}
+
+type hashMaxHeap []uint64
+
+func (h hashMaxHeap) Len() int            { return len(h) }
+func (h hashMaxHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h hashMaxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hashMaxHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *hashMaxHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,45 @@
+package tablescanner
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseProfileNumber(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string // "" when value is not a number
+	}{
+		{"1234", "1234"},
+		{"-12.5", "-12.5"},
+		{"1,5", "1.5"},
+		{"1,2345", "1.2345"},
+		{"1,234", "1234"},
+		{"1,234,567", "1234567"},
+		{"-1,234,567.89", "-1234567.89"},
+		{"1.234", "1.234"},
+		{"1.234.567", "1234567"},
+		{"1.234.567,89", "1234567.89"},
+		{"1 234 567,5", "1234567.5"},
+		{"1 234", "1234"},
+		{"1,23,4", ""},
+		{"12,34.5", ""},
+		{"1,2,3", ""},
+		{"0x10", ""},
+		{"", ""},
+		{"abc", ""},
+	}
+	for _, test := range tests {
+		number, ok := parseProfileNumber(test.value)
+		if "" == test.expected {
+			if ok {
+				t.Errorf("parseProfileNumber(%q) = %s, expected not a number", test.value, number.RatString())
+			}
+			continue
+		}
+		expected, _ := new(big.Rat).SetString(test.expected)
+		if !ok || 0 != number.Cmp(expected) {
+			t.Errorf("parseProfileNumber(%q) = %v %v, expected %s", test.value, number, ok, test.expected)
+		}
+	}
+}
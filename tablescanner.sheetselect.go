@@ -0,0 +1,202 @@
+package tablescanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type TSheetMatch byte
+
+const (
+	SheetMatchExact      TSheetMatch = 0 // name is equal to pattern
+	SheetMatchIgnoreCase TSheetMatch = 1 // name is equal to pattern ignoring case
+	SheetMatchGlob       TSheetMatch = 2 // case-insensitive wildcards: * is any string, ? is any character, [a-z] and [!a-z] are classes
+	SheetMatchRegexp     TSheetMatch = 3 // regular expression matches part of name, use ^...$ to match whole name
+)
+
+// checkSheetId is bounds check shared by SetSheetId() of all formats
+func checkSheetId(id int, sheetsCount int) error {
+	if id < 0 || id >= sheetsCount {
+		return fmt.Errorf("sheet #%d not found", id)
+	}
+	return nil
+}
+
+// FindSheet returns id of the first sheet which name matches pattern
+func FindSheet(sheets []ITableSheetInfo, pattern string, match TSheetMatch) (error, int) {
+	var expr *regexp.Regexp
+	var err error
+	switch match {
+	case SheetMatchExact, SheetMatchIgnoreCase:
+	case SheetMatchGlob:
+		expr, err = regexp.Compile(globToRegexp(pattern))
+	case SheetMatchRegexp:
+		expr, err = regexp.Compile(pattern)
+	default:
+		return fmt.Errorf("sheet match mode %d is not supported", match), -1
+	}
+	if nil != err {
+		return fmt.Errorf("invalid sheet name pattern %q: %s", pattern, err), -1
+	}
+	for id, sheet := range sheets {
+		name := sheet.GetName()
+		if (SheetMatchExact == match && name == pattern) || (SheetMatchIgnoreCase == match && strings.EqualFold(name, pattern)) || (nil != expr && expr.MatchString(name)) {
+			return nil, id
+		}
+	}
+	return fmt.Errorf("sheet %q not found", pattern), -1
+}
+
+// globToRegexp converts wildcard pattern to anchored case-insensitive regular expression
+func globToRegexp(pattern string) string {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := i + 1
+			if end < len(runes) && '!' == runes[end] {
+				end++
+			}
+			if end < len(runes) && ']' == runes[end] {
+				end++
+			}
+			for end < len(runes) && ']' != runes[end] {
+				end++
+			}
+			if end >= len(runes) {
+				// unterminated class is literal bracket
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := runes[i+1 : end]
+			expr.WriteByte('[')
+			if '!' == class[0] {
+				expr.WriteByte('^')
+				class = class[1:]
+			}
+			for _, r := range class {
+				if '\\' == r || '[' == r || ']' == r || '^' == r {
+					expr.WriteByte('\\')
+				}
+				expr.WriteRune(r)
+			}
+			expr.WriteByte(']')
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	expr.WriteString("$")
+	return expr.String()
+}
+
+// firstVisibleSheetId returns id of the first sheet which is not hidden
+func firstVisibleSheetId(sheets []ITableSheetInfo) (error, int) {
+	for id, sheet := range sheets {
+		if TableSheetVisible == sheet.GetHideLevel() {
+			return nil, id
+		}
+	}
+	return fmt.Errorf("visible sheet not found"), -1
+}
+
+// setSheetByName is SetSheetByName() shared by all formats, scanner is passed as interface
+// so formats embedding xlsxStream select sheet by their own SetSheetId()
+func setSheetByName(scanner ITableDocumentScanner, pattern string, match TSheetMatch) error {
+	err, id := FindSheet(scanner.GetSheets(), pattern, match)
+	if nil != err {
+		return err
+	}
+	return scanner.SetSheetId(id)
+}
+
+func setFirstVisibleSheet(scanner ITableDocumentScanner) error {
+	err, id := firstVisibleSheetId(scanner.GetSheets())
+	if nil != err {
+		return err
+	}
+	return scanner.SetSheetId(id)
+}
+
+func (xlsx *xlsxStream) GetActiveSheetId() int {
+	return xlsx.sheetSelected
+}
+
+func (xlsx *xlsxStream) SetSheetByName(pattern string, match TSheetMatch) error {
+	return setSheetByName(xlsx, pattern, match)
+}
+
+func (xlsx *xlsxStream) SetFirstVisibleSheet() error {
+	return setFirstVisibleSheet(xlsx)
+}
+
+func (xlsx *xlsxStream) SetActiveSheet() error {
+	return xlsx.SetSheetId(xlsx.sheetSelected)
+}
+
+func (xlsb *xlsbStream) SetSheetByName(pattern string, match TSheetMatch) error {
+	return setSheetByName(xlsb, pattern, match)
+}
+
+func (xlsb *xlsbStream) SetFirstVisibleSheet() error {
+	return setFirstVisibleSheet(xlsb)
+}
+
+func (xlsb *xlsbStream) SetActiveSheet() error {
+	return xlsb.SetSheetId(xlsb.sheetSelected)
+}
+
+func (xls *xlsHandle) GetActiveSheetId() int {
+	return xls.sheetSelected
+}
+
+func (xls *xlsHandle) SetSheetByName(pattern string, match TSheetMatch) error {
+	return setSheetByName(xls, pattern, match)
+}
+
+func (xls *xlsHandle) SetFirstVisibleSheet() error {
+	return setFirstVisibleSheet(xls)
+}
+
+func (xls *xlsHandle) SetActiveSheet() error {
+	return xls.SetSheetId(xls.sheetSelected)
+}
+
+func (xls *xmlHandle) GetActiveSheetId() int {
+	return xls.sheetSelected
+}
+
+func (xls *xmlHandle) SetSheetByName(pattern string, match TSheetMatch) error {
+	return setSheetByName(xls, pattern, match)
+}
+
+func (xls *xmlHandle) SetFirstVisibleSheet() error {
+	return setFirstVisibleSheet(xls)
+}
+
+func (xls *xmlHandle) SetActiveSheet() error {
+	return xls.SetSheetId(xls.sheetSelected)
+}
+
+func (ods *odsStream) GetActiveSheetId() int {
+	return ods.sheetSelected
+}
+
+func (ods *odsStream) SetSheetByName(pattern string, match TSheetMatch) error {
+	return setSheetByName(ods, pattern, match)
+}
+
+func (ods *odsStream) SetFirstVisibleSheet() error {
+	return setFirstVisibleSheet(ods)
+}
+
+func (ods *odsStream) SetActiveSheet() error {
+	return ods.SetSheetId(ods.sheetSelected)
+}
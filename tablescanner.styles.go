@@ -0,0 +1,66 @@
+package tablescanner
+
+// TCellStyle is resolved cell formatting, colors are ARGB hex or "theme:N"/"indexed:N" palette references
+type TCellStyle struct {
+	NumFmtId   int
+	NumFmt     string
+	Font       TCellFont
+	Fill       TCellFill
+	Border     TCellBorder
+	Alignment  TCellAlignment
+	Protection TCellProtection
+}
+
+type TCellFont struct {
+	Name      string
+	Size      float64
+	Bold      bool
+	Italic    bool
+	Strike    bool
+	Underline string // "" when not underlined, otherwise "single"/"double"/"singleAccounting"/"doubleAccounting"
+	Color     string
+}
+
+type TCellFill struct {
+	PatternType string // "none"/"solid"/"gray125"/... or "gradient"
+	FgColor     string
+	BgColor     string
+}
+
+type TCellBorderEdge struct {
+	Style string // "" when edge has no line, otherwise "thin"/"medium"/"dashed"/...
+	Color string
+}
+
+type TCellBorder struct {
+	Left         TCellBorderEdge
+	Right        TCellBorderEdge
+	Top          TCellBorderEdge
+	Bottom       TCellBorderEdge
+	Diagonal     TCellBorderEdge
+	DiagonalUp   bool
+	DiagonalDown bool
+}
+
+type TCellAlignment struct {
+	Horizontal   string
+	Vertical     string
+	WrapText     bool
+	ShrinkToFit  bool
+	Indent       int
+	TextRotation int
+}
+
+type TCellProtection struct {
+	Locked bool
+	Hidden bool
+}
+
+// IsHighlighted reports whether cell has visible background fill
+func (style *TCellStyle) IsHighlighted() bool {
+	switch style.Fill.PatternType {
+	case "", "none":
+		return false
+	}
+	return true
+}
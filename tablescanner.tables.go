@@ -0,0 +1,131 @@
+package tablescanner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TTableScanner iterates data rows of excel table (ListObject), header rows are skipped
+type TTableScanner struct {
+	scanner       ITableDocumentScanner
+	table         ITableInfo
+	columnFirst   int // 1-based
+	columnLast    int // 1-based
+	rowFirst      int // first data row number, 1-based
+	rowLast       int // last data row number, 1-based
+	rowNum        int // sheet row number fetched by last Scan()
+	scannedData   []string
+	lastScanError error
+}
+
+// FindTable searches table by name through all sheets, table names are case-insensitive like in excel
+func FindTable(scanner ITableDocumentScanner, tableName string) (error, ITableInfo) {
+	for _, sheet := range scanner.GetSheets() {
+		for _, table := range sheet.GetTables() {
+			if strings.EqualFold(table.GetName(), tableName) {
+				return nil, table
+			}
+		}
+	}
+	return fmt.Errorf("table [%s] not found", tableName), nil
+}
+
+// NewTableScanner switches scanner to the sheet containing table and limits iteration to table data rows
+func NewTableScanner(scanner ITableDocumentScanner, tableName string, excludeTotals bool) (error, *TTableScanner) {
+	err, table := FindTable(scanner, tableName)
+	if nil != err {
+		return err, nil
+	}
+	err, x1, y1, x2, y2 := extractCellRangeCoords(table.GetRef())
+	if nil != err {
+		return fmt.Errorf("table [%s] has invalid ref [%s]: %s", tableName, table.GetRef(), err), nil
+	}
+	ts := &TTableScanner{
+		scanner:     scanner,
+		table:       table,
+		columnFirst: x1,
+		columnLast:  x2,
+		rowFirst:    y1 + table.GetHeaderRowCount(),
+		rowLast:     y2,
+	}
+	if excludeTotals {
+		ts.rowLast -= table.GetTotalsRowCount()
+	}
+	err = scanner.SetSheetId(table.GetSheetId())
+	if nil != err {
+		return err, nil
+	}
+	return nil, ts
+}
+
+func (ts *TTableScanner) GetTable() ITableInfo {
+	return ts.table
+}
+
+func (ts *TTableScanner) GetColumns() []string {
+	return ts.table.GetColumns()
+}
+
+func (ts *TTableScanner) GetLastScanError() error {
+	return ts.lastScanError
+}
+
+func (ts *TTableScanner) Scan() error {
+	ts.lastScanError = ts.scanInternal()
+	return ts.lastScanError
+}
+
+func (ts *TTableScanner) scanInternal() error {
+	ts.scannedData = []string{}
+	if ts.rowNum >= ts.rowLast || ts.rowFirst > ts.rowLast {
+		return io.EOF
+	}
+	// header rows are skipped while fetching first data row, rows are matched by sheet row number
+	// because visibility filter of scanner may skip rows
+	for {
+		err := ts.scanner.Scan()
+		if nil != err {
+			return err
+		}
+		ts.rowNum = ts.scanner.GetScannedRowNum()
+		if ts.rowNum > ts.rowLast {
+			return io.EOF
+		}
+		if ts.rowNum >= ts.rowFirst {
+			break
+		}
+	}
+	// cells are placed by sheet column index, so columns dropped by visibility filter are kept empty
+	row := ts.scanner.GetScanned()
+	columnIds := ts.scanner.GetScannedColumnIds()
+	ts.scannedData = make([]string, ts.columnLast-ts.columnFirst+1)
+	for i, value := range row {
+		if i < len(columnIds) {
+			column := columnIds[i] - (ts.columnFirst - 1)
+			if column >= 0 && column < len(ts.scannedData) {
+				ts.scannedData[column] = value
+			}
+		}
+	}
+	return nil
+}
+
+// GetScanned returns table row cells, one per table column
+func (ts *TTableScanner) GetScanned() []string {
+	return ts.scannedData
+}
+
+// GetScannedMap returns table row keyed by declared column names
+func (ts *TTableScanner) GetScannedMap() map[string]string {
+	columns := ts.table.GetColumns()
+	res := make(map[string]string, len(columns))
+	for i, name := range columns {
+		if i < len(ts.scannedData) {
+			res[name] = ts.scannedData[i]
+		} else {
+			res[name] = ""
+		}
+	}
+	return res
+}
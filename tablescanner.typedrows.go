@@ -0,0 +1,187 @@
+package tablescanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TTypedColumn is column of typed rows, Name is unique and non-empty
+type TTypedColumn struct {
+	Name string
+	Type TColumnType // never ColumnTypeEmpty, empty columns are read as strings
+}
+
+type TTypedRowOptions struct {
+	HeaderRows int // leading rows used as column names, negative value detects them by DetectHeader()
+	SampleRows int // rows profiled to infer column types, 0 means 1000, negative value profiles whole sheet
+}
+
+const typedRowsDefaultSample = 1000
+
+// TTypedRowReader streams sheet rows as values of types inferred by ProfileSheet() over leading rows
+type TTypedRowReader struct {
+	scanner       ITableDocumentScanner
+	classifier    *tCellClassifier
+	columns       []TTypedColumn
+	headerLastRow int
+	selected      []bool // nil selects all columns
+	filtered      []bool // columns converted before filter
+	filter        func(row []interface{}) bool
+}
+
+// NewTypedRowReader profiles sample rows of sheet to build schema, then rewinds the sheet for Next();
+// schema is not changed afterwards, so Next() fails on cells which do not fit it
+func NewTypedRowReader(scanner ITableDocumentScanner, sheetId int, options TTypedRowOptions) (error, *TTypedRowReader) {
+	maxRows := options.SampleRows
+	if 0 == maxRows {
+		maxRows = typedRowsDefaultSample
+	} else if maxRows < 0 {
+		maxRows = 0
+	}
+	err, profile := ProfileSheet(scanner, sheetId, TProfileOptions{HeaderRows: options.HeaderRows, MaxRows: maxRows, Samples: -1})
+	if nil != err {
+		return err, nil
+	}
+	reader := &TTypedRowReader{
+		scanner:       scanner,
+		classifier:    newCellClassifier(scanner),
+		columns:       make([]TTypedColumn, len(profile.Columns)),
+		headerLastRow: profile.Header.LastRow,
+	}
+	used := make(map[string]bool)
+	for i, column := range profile.Columns {
+		name := column.Name
+		if "" == name {
+			name = ColumnName(i)
+		}
+		for suffix := 2; used[strings.ToLower(name)]; suffix++ {
+			name = column.Name + "_" + strconv.Itoa(suffix)
+			if "" == column.Name {
+				name = ColumnName(i) + "_" + strconv.Itoa(suffix)
+			}
+		}
+		used[strings.ToLower(name)] = true
+		reader.columns[i] = TTypedColumn{Name: name, Type: column.Type}
+		if ColumnTypeEmpty == column.Type {
+			reader.columns[i].Type = ColumnTypeString
+		}
+	}
+	return nil, reader
+}
+
+func (reader *TTypedRowReader) Columns() []TTypedColumn {
+	return reader.columns
+}
+
+// SelectColumns limits values converted by Next() to listed 0-based columns, other values are nil;
+// nil columns select all columns back
+func (reader *TTypedRowReader) SelectColumns(columns []int) {
+	if nil == columns {
+		reader.selected = nil
+		return
+	}
+	reader.selected = make([]bool, len(reader.columns))
+	for _, column := range columns {
+		if column >= 0 && column < len(reader.selected) {
+			reader.selected[column] = true
+		}
+	}
+}
+
+// SetFilter makes Next() skip rows rejected by filter; listed 0-based columns are converted before filter is
+// called and other selected columns are converted only for accepted rows, so rejected rows do not fail on them;
+// nil filter accepts all rows
+func (reader *TTypedRowReader) SetFilter(columns []int, filter func(row []interface{}) bool) {
+	reader.filter = filter
+	reader.filtered = make([]bool, len(reader.columns))
+	for _, column := range columns {
+		if column >= 0 && column < len(reader.filtered) {
+			reader.filtered[column] = true
+		}
+	}
+}
+
+// Next reads the next data row, values are nil for empty and error cells, int64, float64, bool, time.Time
+// or string otherwise; io.EOF is returned after the last row. Error is returned when selected cell does not fit
+// column type or when row has non-empty cells beyond schema columns, larger SampleRows fixes both
+func (reader *TTypedRowReader) Next() (error, []interface{}) {
+	for {
+		if err := reader.scanner.Scan(); nil != err {
+			return err, nil
+		}
+		if reader.scanner.GetScannedRowNum() <= reader.headerLastRow {
+			continue
+		}
+		values := reader.scanner.GetScanned()
+		cellErrors := reader.scanner.GetScannedErrors()
+		decimals := reader.scanner.GetScannedDecimals()
+		styles := reader.scanner.GetScannedStyles()
+		isEmpty := func(i int) bool {
+			return (i < len(cellErrors) && nil != cellErrors[i]) || "" == strings.TrimSpace(values[i])
+		}
+		row := make([]interface{}, len(reader.columns))
+		if nil != reader.filter {
+			if err := reader.convertRow(row, values, decimals, styles, isEmpty, reader.filtered); nil != err {
+				return err, nil
+			}
+			if !reader.filter(row) {
+				continue
+			}
+		}
+		for i := len(reader.columns); i < len(values); i++ {
+			if !isEmpty(i) {
+				return fmt.Errorf("cell %s value [%s] is beyond %d columns of schema inferred from sample rows", reader.cellAddr(i), values[i], len(reader.columns)), nil
+			}
+		}
+		if err := reader.convertRow(row, values, decimals, styles, isEmpty, reader.selected); nil != err {
+			return err, nil
+		}
+		return nil, row
+	}
+}
+
+// convertRow sets values of listed columns which are not converted yet, nil columns list all columns
+func (reader *TTypedRowReader) convertRow(row []interface{}, values []string, decimals []TDecimal, styles []int, isEmpty func(i int) bool, columns []bool) error {
+	for i, column := range reader.columns {
+		if (nil != columns && !columns[i]) || nil != row[i] || i >= len(values) || isEmpty(i) {
+			continue
+		}
+		if ColumnTypeString == column.Type {
+			row[i] = values[i]
+			continue
+		}
+		cell := reader.classifier.classify(i, values[i], decimals, styles)
+		switch {
+		case ColumnTypeInt == column.Type && ColumnTypeInt == cell.columnType && cell.number.Num().IsInt64():
+			row[i] = cell.number.Num().Int64()
+		case ColumnTypeFloat == column.Type && (ColumnTypeInt == cell.columnType || ColumnTypeFloat == cell.columnType):
+			row[i], _ = cell.number.Float64()
+		case ColumnTypeDate == column.Type && ColumnTypeDate == cell.columnType:
+			row[i] = cell.date
+		case ColumnTypeBool == column.Type && ColumnTypeBool == cell.columnType:
+			row[i] = cell.flag
+		default:
+			return fmt.Errorf("cell %s value [%s] does not fit %s column [%s] inferred from sample rows", reader.cellAddr(i), values[i], column.Type, column.Name)
+		}
+	}
+	return nil
+}
+
+// cellAddr returns sheet address of scanned cell, column indexes are shifted by visibility filter
+func (reader *TTypedRowReader) cellAddr(i int) string {
+	columnIds := reader.scanner.GetScannedColumnIds()
+	if i < len(columnIds) {
+		i = columnIds[i]
+	}
+	return makeCellAddr(i+1, reader.scanner.GetScannedRowNum())
+}
+
+// ColumnName returns spreadsheet name of 0-based column index, like "A", "Z" or "AA"
+func ColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
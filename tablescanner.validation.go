@@ -0,0 +1,349 @@
+package tablescanner
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TDataValidation is sheet data validation rule
+type TDataValidation struct {
+	Ref        string   // space-separated list of cell ranges in A1 notation
+	Type       string   // "any"/"list"/"whole"/"decimal"/"date"/"time"/"textLength"/"custom"
+	Operator   string   // "between"/"notBetween"/"equal"/"notEqual"/"lessThan"/"lessThanOrEqual"/"greaterThan"/"greaterThanOrEqual"
+	Formula1   string   // raw formula without leading '='
+	Formula2   string   // raw formula without leading '=', used by between/notBetween only
+	List       []string // inline list items, nil when list items are taken from cells (see ListSource)
+	ListSource string   // cell range (optionally sheet-qualified) or defined name of list items, "" for inline lists
+	Min        *float64 // numeric bound derived from operator and formulas, nil when absent or not a constant
+	Max        *float64
+	AllowBlank bool
+	ErrorTitle string
+	Error      string
+}
+
+// TValidationViolation is a cell value failed data validation
+type TValidationViolation struct {
+	Ref        string
+	Value      string
+	Validation *TDataValidation
+}
+
+// TDataValidator checks cell values against sheet data validations, date/time/custom rules and lists referring
+// defined names are not evaluated
+type TDataValidator struct {
+	scanner     ITableDocumentScanner
+	sheetId     int
+	validations []TDataValidation
+	ranges      [][][4]int       // validation id to list of x1,y1,x2,y2 ranges
+	lists       map[int][]string // validation id to resolved list items
+}
+
+// NewDataValidator reads validations of sheet, list items referring cells are read by scanning source sheet,
+// so scanner is switched to the sheet start afterwards
+func NewDataValidator(scanner ITableDocumentScanner, sheetId int) (error, *TDataValidator) {
+	err, validations := scanner.GetDataValidations(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	validator := &TDataValidator{
+		scanner:     scanner,
+		sheetId:     sheetId,
+		validations: validations,
+		ranges:      make([][][4]int, len(validations)),
+		lists:       make(map[int][]string),
+	}
+	for i := range validations {
+		validation := &validations[i]
+		for _, ref := range strings.Fields(validation.Ref) {
+			err, x1, y1, x2, y2 := extractCellRangeCoords(ref)
+			if nil != err {
+				err, x1, y1 = extractCellCoords(ref)
+				if nil != err {
+					continue
+				}
+				x2, y2 = x1, y1
+			}
+			validator.ranges[i] = append(validator.ranges[i], [4]int{x1, y1, x2, y2})
+		}
+		if "list" != validation.Type {
+			continue
+		}
+		if nil != validation.List {
+			validator.lists[i] = validation.List
+			continue
+		}
+		err, items := validator.readListSource(validation.ListSource)
+		if nil != err {
+			return err, nil
+		}
+		if nil != items {
+			validator.lists[i] = items
+		}
+	}
+	err = scanner.SetSheetId(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, validator
+}
+
+// readListSource reads values of range like "$A$1:$A$5" or "'Sheet 2'!$A$1:$A$5", nil is returned for defined names
+func (validator *TDataValidator) readListSource(source string) (error, []string) {
+	sheetId := validator.sheetId
+	ref := source
+	if pos := strings.LastIndex(source, "!"); pos >= 0 {
+		sheetName := strings.Trim(source[:pos], "'")
+		sheetName = strings.Replace(sheetName, "''", "'", -1)
+		sheetId = -1
+		for i, sheet := range validator.scanner.GetSheets() {
+			if strings.EqualFold(sheet.GetName(), sheetName) {
+				sheetId = i
+				break
+			}
+		}
+		if -1 == sheetId {
+			return nil, nil
+		}
+		ref = source[pos+1:]
+	}
+	ref = strings.Replace(ref, "$", "", -1)
+	err, x1, y1, x2, y2 := extractCellRangeCoords(ref)
+	if nil != err {
+		err, x1, y1 = extractCellCoords(ref)
+		if nil != err {
+			// defined name
+			return nil, nil
+		}
+		x2, y2 = x1, y1
+	}
+	err = validator.scanner.SetSheetId(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	// list items are often kept in hidden rows or columns, so they are read with visibility filter turned off
+	res := []string{}
+	err = validator.withoutVisibilityFilter(func() error {
+		for {
+			err := validator.scanner.Scan()
+			if io.EOF == err {
+				return nil
+			}
+			if nil != err {
+				return err
+			}
+			rowNum := validator.scanner.GetScannedRowNum()
+			if rowNum > y2 {
+				return nil
+			}
+			if rowNum < y1 {
+				continue
+			}
+			row := validator.scanner.GetScanned()
+			for x := x1; x <= x2 && x <= len(row); x++ {
+				if "" != row[x-1] {
+					res = append(res, row[x-1])
+				}
+			}
+		}
+	})
+	if nil != err {
+		return err, nil
+	}
+	return nil, res
+}
+
+// withoutVisibilityFilter calls scan with visibility filter of scanner turned off, so cells are addressed by
+// sheet coordinates, previous filter is restored afterwards
+func (validator *TDataValidator) withoutVisibilityFilter(scan func() error) error {
+	filter := validator.scanner.GetVisibilityFilter()
+	err := validator.scanner.SetVisibilityFilter(VisibilityFilterNone)
+	if nil != err {
+		return err
+	}
+	err = scan()
+	if restoreErr := validator.scanner.SetVisibilityFilter(filter); nil == err {
+		err = restoreErr
+	}
+	return err
+}
+
+func (validator *TDataValidator) GetValidations() []TDataValidation {
+	return validator.validations
+}
+
+// ValidateRow checks row cells, rowNum is 1-based sheet row number and values start from column A;
+// numbers are parsed from formatted text, so thousand separators and decimal comma are accepted
+func (validator *TDataValidator) ValidateRow(rowNum int, values []string) []TValidationViolation {
+	return validator.validateRow(rowNum, values, nil)
+}
+
+// validateRow checks row cells, numeric rules use stored values of decimals when cell has one
+func (validator *TDataValidator) validateRow(rowNum int, values []string, decimals []TDecimal) []TValidationViolation {
+	res := []TValidationViolation{}
+	for i := range validator.validations {
+		for _, coords := range validator.ranges[i] {
+			if rowNum < coords[1] || rowNum > coords[3] {
+				continue
+			}
+			for x := coords[0]; x <= coords[2]; x++ {
+				value := ""
+				if x <= len(values) {
+					value = values[x-1]
+				}
+				decimal := TDecimal("")
+				if x <= len(decimals) {
+					decimal = decimals[x-1]
+				}
+				if !validator.isValid(i, value, decimal) {
+					res = append(res, TValidationViolation{Ref: makeCellAddr(x, rowNum), Value: value, Validation: &validator.validations[i]})
+				}
+			}
+		}
+	}
+	return res
+}
+
+// ValidateSheet scans whole sheet from the start and collects violations of all rows, hidden rows and columns
+// are validated too because visibility filter is turned off for the duration of the call
+func (validator *TDataValidator) ValidateSheet() (error, []TValidationViolation) {
+	err := validator.scanner.SetSheetId(validator.sheetId)
+	if nil != err {
+		return err, nil
+	}
+	res := []TValidationViolation{}
+	err = validator.withoutVisibilityFilter(func() error {
+		for {
+			err := validator.scanner.Scan()
+			if io.EOF == err {
+				return nil
+			}
+			if nil != err {
+				return err
+			}
+			res = append(res, validator.validateRow(validator.scanner.GetScannedRowNum(), validator.scanner.GetScanned(), validator.scanner.GetScannedDecimals())...)
+		}
+	})
+	if nil != err {
+		return err, nil
+	}
+	return nil, res
+}
+
+func (validator *TDataValidator) isValid(validationId int, value string, decimal TDecimal) bool {
+	validation := &validator.validations[validationId]
+	if "" == value {
+		return validation.AllowBlank
+	}
+	switch validation.Type {
+	case "list":
+		items, ok := validator.lists[validationId]
+		if !ok {
+			return true
+		}
+		for _, item := range items {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+		return false
+	case "whole", "decimal":
+		number, ok := 0.0, false
+		if decimal.IsEmpty() {
+			number, ok = parseValidationNumber(value)
+		} else {
+			var err error
+			number, err = decimal.Float64()
+			ok = nil == err
+		}
+		if !ok || ("whole" == validation.Type && number != math.Trunc(number)) {
+			return false
+		}
+		return validation.isInBounds(number)
+	case "textLength":
+		return validation.isInBounds(float64(utf8.RuneCountInString(value)))
+	}
+	return true
+}
+
+// isInBounds applies operator to value, rules with non-constant formulas are treated as passed
+func (validation *TDataValidation) isInBounds(value float64) bool {
+	switch validation.Operator {
+	case "", "between", "notBetween":
+		if nil == validation.Min || nil == validation.Max {
+			return true
+		}
+		inside := value >= *validation.Min && value <= *validation.Max
+		return inside == ("notBetween" != validation.Operator)
+	case "greaterThan", "greaterThanOrEqual":
+		if nil == validation.Min {
+			return true
+		}
+		return value > *validation.Min || ("greaterThanOrEqual" == validation.Operator && value == *validation.Min)
+	case "lessThan", "lessThanOrEqual":
+		if nil == validation.Max {
+			return true
+		}
+		return value < *validation.Max || ("lessThanOrEqual" == validation.Operator && value == *validation.Max)
+	case "equal", "notEqual":
+		if nil == validation.Min {
+			return true
+		}
+		return (value == *validation.Min) == ("equal" == validation.Operator)
+	}
+	return true
+}
+
+// parseValidationNumber parses formatted number by separator rules of parseProfileNumber()
+func parseValidationNumber(value string) (float64, bool) {
+	number, ok := parseProfileNumber(strings.TrimSpace(value))
+	if !ok {
+		return 0, false
+	}
+	res, _ := number.Float64()
+	return res, true
+}
+
+// setFormulas fills formulas, inline list and numeric bounds of validation by raw formulas
+func (validation *TDataValidation) setFormulas(formula1 string, formula2 string) {
+	validation.Formula1 = strings.TrimPrefix(formula1, "=")
+	validation.Formula2 = strings.TrimPrefix(formula2, "=")
+	if "list" == validation.Type {
+		if strings.HasPrefix(validation.Formula1, "\"") && strings.HasSuffix(validation.Formula1, "\"") && len(validation.Formula1) >= 2 {
+			list := strings.Replace(validation.Formula1[1:len(validation.Formula1)-1], "\"\"", "\"", -1)
+			validation.List = strings.Split(list, ",")
+			for i := range validation.List {
+				validation.List[i] = strings.TrimSpace(validation.List[i])
+			}
+		} else {
+			validation.ListSource = validation.Formula1
+		}
+		return
+	}
+	parse := func(formula string) *float64 {
+		number, err := strconv.ParseFloat(formula, 64)
+		if nil != err {
+			return nil
+		}
+		return &number
+	}
+	switch validation.Operator {
+	case "", "between", "notBetween":
+		validation.Min = parse(validation.Formula1)
+		validation.Max = parse(validation.Formula2)
+	case "greaterThan", "greaterThanOrEqual":
+		validation.Min = parse(validation.Formula1)
+	case "lessThan", "lessThanOrEqual":
+		validation.Max = parse(validation.Formula1)
+	case "equal", "notEqual":
+		validation.Min = parse(validation.Formula1)
+		validation.Max = validation.Min
+	}
+}
+
+func (violation TValidationViolation) String() string {
+	return fmt.Sprintf("%s: value [%s] violates %s validation", violation.Ref, violation.Value, violation.Validation.Type)
+}
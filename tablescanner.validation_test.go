@@ -0,0 +1,37 @@
+package tablescanner
+
+import "testing"
+
+func TestValidateFormattedNumbers(t *testing.T) {
+	low, high := 1000.0, 2000000.0
+	validator := &TDataValidator{
+		validations: []TDataValidation{{Ref: "A1:A9", Type: "whole", Operator: "between", Min: &low, Max: &high}},
+		ranges:      [][][4]int{{{1, 1, 1, 9}}},
+	}
+	tests := []struct {
+		value    string
+		decimal  TDecimal
+		expected bool
+	}{
+		{"1,234", "", true},
+		{"1,000", "", true},
+		{"1,000,000", "", true},
+		{"1 000 000", "", true},
+		{"1.234,00", "", true},
+		{"1,5", "", false},
+		{"2,000,001", "", false},
+		{"1,23,4", "", false},
+		// stored value wins over formatted text
+		{"1.2K", "1200", true},
+		{"1,234", "1234.5", false},
+		{"50%", "0.5", false},
+	}
+	for _, test := range tests {
+		if result := validator.isValid(0, test.value, test.decimal); test.expected != result {
+			t.Errorf("value %q stored as %q is valid: %v, expected %v", test.value, test.decimal, result, test.expected)
+		}
+	}
+	if violations := validator.ValidateRow(1, []string{"1,234"}); 0 != len(violations) {
+		t.Errorf("ValidateRow reported %v", violations)
+	}
+}
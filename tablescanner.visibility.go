@@ -0,0 +1,136 @@
+package tablescanner
+
+import "strconv"
+
+// TVisibilityFilter is a set of flags making Scan() skip rows and GetScanned*() drop columns
+type TVisibilityFilter byte
+
+const (
+	VisibilityFilterNone              TVisibilityFilter = 0
+	VisibilityFilterSkipHiddenRows    TVisibilityFilter = 1 // rows hidden by user, autofilter or collapsed outline
+	VisibilityFilterSkipHiddenColumns TVisibilityFilter = 2 // hidden columns are removed from row data, so column indexes are shifted
+	VisibilityFilterTopLevelRows      TVisibilityFilter = 4 // only rows with zero outline level (grouped detail rows are skipped)
+)
+
+// TRowInfo is row visibility and outline (grouping) state, absent rows are visible with zero outline level
+type TRowInfo struct {
+	Hidden       bool
+	OutlineLevel int
+	Collapsed    bool
+}
+
+// TColumnInfo is column visibility and outline (grouping) state
+type TColumnInfo struct {
+	Hidden       bool
+	OutlineLevel int
+	Collapsed    bool
+}
+
+// isRowFiltered tells if Scan() have to skip row
+func (filter TVisibilityFilter) isRowFiltered(row TRowInfo) bool {
+	if 0 != filter&VisibilityFilterSkipHiddenRows && row.Hidden {
+		return true
+	}
+	if 0 != filter&VisibilityFilterTopLevelRows && row.OutlineLevel > 0 {
+		return true
+	}
+	return false
+}
+
+// visibleColumnIds returns 0-based ids of row cells kept by filter, nil means row is kept as is
+func (filter TVisibilityFilter) visibleColumnIds(columns []TColumnInfo, rowLength int) []int {
+	if 0 == filter&VisibilityFilterSkipHiddenColumns {
+		return nil
+	}
+	hasHidden := false
+	for i := 0; i < len(columns) && i < rowLength; i++ {
+		if columns[i].Hidden {
+			hasHidden = true
+			break
+		}
+	}
+	if !hasHidden {
+		return nil
+	}
+	res := make([]int, 0, rowLength)
+	for i := 0; i < rowLength; i++ {
+		if i >= len(columns) || !columns[i].Hidden {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// scannedColumnIds returns sheet column indexes of scanned row cells: ids kept by filter or 0..scannedLength-1
+func scannedColumnIds(ids []int, scannedLength int) []int {
+	if nil != ids {
+		if len(ids) > scannedLength {
+			return ids[:scannedLength]
+		}
+		return ids
+	}
+	res := make([]int, scannedLength)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+func pickStrings(values []string, ids []int) []string {
+	if nil == ids {
+		return values
+	}
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id < len(values) {
+			res = append(res, values[id])
+		}
+	}
+	return res
+}
+
+func pickInts(values []int, ids []int) []int {
+	if nil == ids {
+		return values
+	}
+	res := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id < len(values) {
+			res = append(res, values[id])
+		}
+	}
+	return res
+}
+
+func pickRichText(values [][]TRichTextRun, ids []int) [][]TRichTextRun {
+	if nil == ids {
+		return values
+	}
+	res := make([][]TRichTextRun, 0, len(ids))
+	for _, id := range ids {
+		if id < len(values) {
+			res = append(res, values[id])
+		}
+	}
+	return res
+}
+
+// setColumnsInfo fills 1-based column interval [min;max] of columns info list
+func setColumnsInfo(columns []TColumnInfo, min int, max int, info TColumnInfo) []TColumnInfo {
+	if min < 1 || max < min {
+		return columns
+	}
+	for len(columns) < max {
+		columns = append(columns, TColumnInfo{})
+	}
+	for i := min; i <= max; i++ {
+		columns[i-1] = info
+	}
+	return columns
+}
+
+// parseXmlBool accepts "1"/"true" as true and anything else as false
+func parseXmlBool(value string) bool {
+	res, err := strconv.ParseBool(value)
+	return nil == err && res
+}
@@ -0,0 +1,168 @@
+package tablescanner
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// tokens of BIFF8 parsed formulas, class bits of operand tokens (0x20/0x40/0x60) are folded to reference class
+const (
+	xlsPtgUplus   = 0x12
+	xlsPtgUminus  = 0x13
+	xlsPtgPercent = 0x14
+	xlsPtgParen   = 0x15
+	xlsPtgMissArg = 0x16
+	xlsPtgStr     = 0x17
+	xlsPtgAttr    = 0x19
+	xlsPtgErr     = 0x1C
+	xlsPtgBool    = 0x1D
+	xlsPtgInt     = 0x1E
+	xlsPtgNum     = 0x1F
+	xlsPtgName    = 0x23
+	xlsPtgRef     = 0x24
+	xlsPtgArea    = 0x25
+	xlsPtgRef3d   = 0x3A
+	xlsPtgArea3d  = 0x3B
+)
+
+var xlsFormulaOperators = map[byte]string{0x03: "+", 0x04: "-", 0x05: "*", 0x06: "/", 0x07: "^", 0x08: "&", 0x09: "<",
+	0x0A: "<=", 0x0B: "=", 0x0C: ">=", 0x0D: ">", 0x0E: "<>", 0x0F: " ", 0x10: ",", 0x11: ":"}
+
+// built-in defined names are stored as single character code
+var xlsBuiltinNames = []string{"Consolidate_Area", "Auto_Open", "Auto_Close", "Extract", "Database", "Criteria",
+	"Print_Area", "Print_Titles", "Recorder", "Data_Form", "Auto_Activate", "Auto_Deactivate", "Sheet_Title", "_FilterDatabase"}
+
+// decodeFormula converts parsed formula to text without leading '=', false is returned for unsupported tokens
+// (function calls, array constants, shared formulas)
+func (xls *xlsHandle) decodeFormula(rgce []byte) (string, bool) {
+	reader := &xlsRecordReader{data: rgce}
+	stack := []string{}
+	pop := func() (string, bool) {
+		if 0 == len(stack) {
+			return "", false
+		}
+		res := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return res, true
+	}
+	for reader.offset < len(rgce) && nil == reader.err {
+		ptg := reader.u8()
+		if ptg >= 0x20 {
+			ptg = ptg&0x1F | 0x20
+		}
+		if operator, ok := xlsFormulaOperators[ptg]; ok {
+			right, ok1 := pop()
+			left, ok2 := pop()
+			if !ok1 || !ok2 {
+				return "", false
+			}
+			stack = append(stack, left+operator+right)
+			continue
+		}
+		switch ptg {
+		case xlsPtgUplus, xlsPtgUminus, xlsPtgPercent, xlsPtgParen:
+			operand, ok := pop()
+			if !ok {
+				return "", false
+			}
+			switch ptg {
+			case xlsPtgUplus:
+				operand = "+" + operand
+			case xlsPtgUminus:
+				operand = "-" + operand
+			case xlsPtgPercent:
+				operand += "%"
+			case xlsPtgParen:
+				operand = "(" + operand + ")"
+			}
+			stack = append(stack, operand)
+		case xlsPtgMissArg:
+			stack = append(stack, "")
+		case xlsPtgStr:
+			// explicit list items of data validation are separated by zero characters
+			value := strings.Replace(reader.shortString(), "\x00", ",", -1)
+			stack = append(stack, `"`+strings.Replace(value, `"`, `""`, -1)+`"`)
+		case xlsPtgAttr:
+			flags := reader.u8()
+			data := int(reader.u16())
+			switch {
+			case 0 != flags&0x04:
+				// CHOOSE jump table
+				reader.bytes(2 * (data + 1))
+			case 0 != flags&0x10:
+				operand, ok := pop()
+				if !ok {
+					return "", false
+				}
+				stack = append(stack, "SUM("+operand+")")
+			}
+		case xlsPtgErr:
+			stack = append(stack, biff12ErrorCodes[reader.u8()])
+		case xlsPtgBool:
+			if 0 == reader.u8() {
+				stack = append(stack, "FALSE")
+			} else {
+				stack = append(stack, "TRUE")
+			}
+		case xlsPtgInt:
+			stack = append(stack, strconv.Itoa(int(reader.u16())))
+		case xlsPtgNum:
+			data := reader.bytes(8)
+			if nil == data {
+				return "", false
+			}
+			stack = append(stack, strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)), 'f', -1, 64))
+		case xlsPtgName:
+			nameId := int(reader.u32())
+			if nameId < 1 || nameId > len(xls.definedNames) {
+				return "", false
+			}
+			stack = append(stack, xls.definedNames[nameId-1])
+		case xlsPtgRef, xlsPtgRef3d:
+			prefix := ""
+			if xlsPtgRef3d == ptg {
+				prefix = xls.externSheetPrefix(int(reader.u16()))
+			}
+			row, column := reader.u16(), reader.u16()
+			stack = append(stack, prefix+xlsCellRef(row, column))
+		case xlsPtgArea, xlsPtgArea3d:
+			prefix := ""
+			if xlsPtgArea3d == ptg {
+				prefix = xls.externSheetPrefix(int(reader.u16()))
+			}
+			rowFirst, rowLast := reader.u16(), reader.u16()
+			columnFirst, columnLast := reader.u16(), reader.u16()
+			stack = append(stack, prefix+xlsCellRef(rowFirst, columnFirst)+":"+xlsCellRef(rowLast, columnLast))
+		default:
+			return "", false
+		}
+	}
+	if nil != reader.err || 1 != len(stack) {
+		return "", false
+	}
+	return stack[0], true
+}
+
+// xlsCellRef makes A1 reference, column field keeps relative flags of column (bit 14) and row (bit 15)
+func xlsCellRef(row uint16, column uint16) string {
+	res := ""
+	if 0 == column&0x4000 {
+		res += "$"
+	}
+	res += ColumnName(int(column & 0x3FFF))
+	if 0 == column&0x8000 {
+		res += "$"
+	}
+	return res + strconv.Itoa(int(row)+1)
+}
+
+// externSheetPrefix returns quoted sheet name with '!' by EXTERNSHEET index, references to other workbooks are "#REF!"
+func (xls *xlsHandle) externSheetPrefix(externSheetId int) string {
+	if externSheetId >= len(xls.externSheets) || xls.externSheets[externSheetId] < 0 || xls.externSheets[externSheetId] >= len(xls.sheetNames) {
+		return "#REF!"
+	}
+	name := xls.sheetNames[xls.externSheets[externSheetId]]
+	return "'" + strings.Replace(name, "'", "''", -1) + "'!"
+}
@@ -11,6 +11,8 @@ type xlsTableSheetInfo struct {
 	Name      string
 	HideLevel TSheetHideLevel
 	sheet     *exls.WorkSheet
+	offset    int           // BOF record position of sheet substream in Workbook stream, -1 when not found
+	meta      *xlsSheetMeta // nil until requireSheetMeta() is called
 }
 
 type xlsHandle struct {
@@ -23,6 +25,14 @@ type xlsHandle struct {
 	iteratorSheetId     int      // current row-iterating sheet id
 	closer              io.Closer
 	workbook            *exls.WorkBook
+	stream              []byte // Workbook stream for records which are not exposed by xls reader package
+	fonts               []TCellFont
+	numFormats          map[int]string // custom number formats by id
+	cellStyles          []TCellStyle   // XF records, cells refer them by index
+	visibilityFilter    TVisibilityFilter
+	sheetNames          []string // BOUNDSHEET names including sheets skipped by xls reader package
+	externSheets        []int    // EXTERNSHEET index to sheet index, -1 for other workbooks
+	definedNames        []string // NAME records, formulas refer them by 1-based index
 }
 
 func newXLSStream(fileName string) (error, ITableDocumentScanner) {
@@ -43,7 +53,7 @@ func newXLSStream(fileName string) (error, ITableDocumentScanner) {
 	foundSelected := false
 	for i := 0; i < numSheets; i++ {
 		xsheet := xls.workbook.GetSheet(i)
-		xls.sheets[i] = &xlsTableSheetInfo{Name: xsheet.Name, sheet: xsheet, HideLevel: TSheetHideLevel(xsheet.Visibility)}
+		xls.sheets[i] = &xlsTableSheetInfo{Name: xsheet.Name, sheet: xsheet, HideLevel: TSheetHideLevel(xsheet.Visibility), offset: -1}
 		if xsheet.Selected {
 			if foundSelected {
 				_, _ = os.Stderr.WriteString(fmt.Sprintf("WARNING: more than one `selected` sheets found in file %s\n", fileName))
@@ -54,6 +64,11 @@ func newXLSStream(fileName string) (error, ITableDocumentScanner) {
 			}
 		}
 	}
+	err = xls.readRecords(fileName)
+	if nil != err {
+		_ = xls.closer.Close()
+		return fmt.Errorf("cannot read records of file %s: %s", fileName, err), nil
+	}
 	return nil, xls
 }
 
@@ -65,6 +80,11 @@ func (sheet *xlsTableSheetInfo) GetHideLevel() TSheetHideLevel {
 	return sheet.HideLevel
 }
 
+func (sheet *xlsTableSheetInfo) GetTables() []ITableInfo {
+	// tables (ListObjects) are not supported by this format
+	return []ITableInfo{}
+}
+
 func (xls *xlsHandle) Close() error {
 	return xls.closer.Close()
 }
@@ -96,12 +116,12 @@ func (xls *xlsHandle) GetCurrentSheetId() int {
 }
 
 func (xls *xlsHandle) SetSheetId(id int) error {
+	if err := checkSheetId(id, len(xls.sheets)); nil != err {
+		return err
+	}
 	xls.iteratorLastError = nil
 	xls.iteratorRowNum = 0
 	xls.iteratorScannedData = []string{}
-	if id < 0 || id > len(xls.sheets) {
-		return fmt.Errorf("sheet #%d not found", id)
-	}
 	xls.iteratorSheetId = id
 	return nil
 }
@@ -111,13 +131,139 @@ func (xls *xlsHandle) GetLastScanError() error {
 }
 
 func (xls *xlsHandle) Scan() error {
-	xls.iteratorRowNum++
-	xls.iteratorLastError = xls.scanInternal()
-	return xls.iteratorLastError
+	for {
+		xls.iteratorRowNum++
+		xls.iteratorLastError = xls.scanInternal()
+		if nil != xls.iteratorLastError || VisibilityFilterNone == xls.visibilityFilter || !xls.visibilityFilter.isRowFiltered(xls.GetScannedRowInfo()) {
+			return xls.iteratorLastError
+		}
+	}
 }
 
 func (xls *xlsHandle) GetScanned() []string {
-	return xls.iteratorScannedData
+	return pickStrings(xls.iteratorScannedData, xls.visibleColumnIds(len(xls.iteratorScannedData)))
+}
+
+func (xls *xlsHandle) GetScannedRowNum() int {
+	return xls.iteratorRowNum
+}
+
+func (xls *xlsHandle) GetScannedColumnIds() []int {
+	return scannedColumnIds(xls.visibleColumnIds(len(xls.iteratorScannedData)), len(xls.GetScanned()))
+}
+
+func (xls *xlsHandle) GetScannedHyperlinks() []string {
+	res := make([]string, len(xls.iteratorScannedData))
+	err, meta := xls.requireSheetMeta(xls.iteratorSheetId)
+	if nil != err {
+		return res
+	}
+	for _, hyperlink := range meta.hyperlinks[xls.iteratorRowNum] {
+		for len(res) < hyperlink.columnLast {
+			res = append(res, "")
+		}
+		for x := hyperlink.columnFirst; x <= hyperlink.columnLast; x++ {
+			res[x-1] = hyperlink.target
+		}
+	}
+	return pickStrings(res, xls.visibleColumnIds(len(res)))
+}
+
+func (xls *xlsHandle) GetComments(sheetId int) (error, map[string]TCellComment) {
+	err, meta := xls.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	res := make(map[string]TCellComment, len(meta.comments))
+	for ref, comment := range meta.comments {
+		res[ref] = comment
+	}
+	return nil, res
+}
+
+func (xls *xlsHandle) SetPhoneticRuns(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("phonetic runs are not supported for XLS format")
+	}
+	return nil
+}
+
+func (xls *xlsHandle) SetRichText(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("rich text is not supported for XLS format")
+	}
+	return nil
+}
+
+func (xls *xlsHandle) GetScannedRichText() [][]TRichTextRun {
+	return make([][]TRichTextRun, len(xls.GetScanned()))
+}
+
+// cell values are returned as text by xls reader package, so error cells are not distinguished
+func (xls *xlsHandle) GetScannedErrors() []*TCellError {
+	return make([]*TCellError, len(xls.GetScanned()))
+}
+
+// cell values are returned as text by xls reader package, so raw numbers are unavailable
+func (xls *xlsHandle) GetScannedDecimals() []TDecimal {
+	return make([]TDecimal, len(xls.GetScanned()))
+}
+
+func (xls *xlsHandle) GetScannedRowInfo() TRowInfo {
+	err, meta := xls.requireSheetMeta(xls.iteratorSheetId)
+	if nil != err {
+		return TRowInfo{}
+	}
+	return meta.rows[xls.iteratorRowNum]
+}
+
+func (xls *xlsHandle) GetColumnsInfo(sheetId int) (error, []TColumnInfo) {
+	err, meta := xls.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.columns
+}
+
+func (xls *xlsHandle) SetVisibilityFilter(filter TVisibilityFilter) error {
+	xls.visibilityFilter = filter
+	return nil
+}
+
+func (xls *xlsHandle) GetVisibilityFilter() TVisibilityFilter {
+	return xls.visibilityFilter
+}
+
+// visibleColumnIds reads COLINFO records only when hidden columns have to be dropped
+func (xls *xlsHandle) visibleColumnIds(rowLength int) []int {
+	if 0 == xls.visibilityFilter&VisibilityFilterSkipHiddenColumns {
+		return nil
+	}
+	err, meta := xls.requireSheetMeta(xls.iteratorSheetId)
+	if nil != err {
+		return nil
+	}
+	return xls.visibilityFilter.visibleColumnIds(meta.columns, rowLength)
+}
+
+// AUTOFILTER records are not exposed by xls reader package
+func (xls *xlsHandle) GetAutoFilter(sheetId int) (error, *TAutoFilter) {
+	return fmt.Errorf("autofilter is not supported for XLS format"), nil
+}
+
+func (xls *xlsHandle) SetAutoFilterEvaluation(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("autofilter evaluation is not supported for XLS format")
+	}
+	return nil
+}
+
+func (xls *xlsHandle) GetFreezePane(sheetId int) (error, *TFreezePane) {
+	err, meta := xls.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.freezePane
 }
 
 func (xls *xlsHandle) scanInternal() error {
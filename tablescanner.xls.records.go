@@ -0,0 +1,275 @@
+package tablescanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// BIFF8 record types which are not exposed by xls reader package, so they are read from Workbook stream directly
+const (
+	xlsRecordFormula     = 0x0006
+	xlsRecordEOF         = 0x000A
+	xlsRecordExternSheet = 0x0017
+	xlsRecordName        = 0x0018
+	xlsRecordNote        = 0x001C
+	xlsRecordFont        = 0x0031
+	xlsRecordContinue    = 0x003C
+	xlsRecordPane        = 0x0041
+	xlsRecordObj         = 0x005D
+	xlsRecordColInfo     = 0x007D
+	xlsRecordMulRk       = 0x00BD
+	xlsRecordMulBlank    = 0x00BE
+	xlsRecordRString     = 0x00D6
+	xlsRecordSupBook     = 0x01AE
+	xlsRecordDVal        = 0x01B2
+	xlsRecordXF          = 0x00E0
+	xlsRecordLabelSST    = 0x00FD
+	xlsRecordTxo         = 0x01B6
+	xlsRecordHLink       = 0x01B8
+	xlsRecordDV          = 0x01BE
+	xlsRecordBlank       = 0x0201
+	xlsRecordNumber      = 0x0203
+	xlsRecordLabel       = 0x0204
+	xlsRecordBoolErr     = 0x0205
+	xlsRecordRow         = 0x0208
+	xlsRecordWindow2     = 0x023E
+	xlsRecordRk          = 0x027E
+	xlsRecordFormat      = 0x041E
+)
+
+// xlsRecord is BIFF8 record, payloads of following CONTINUE records are kept separately
+// because some records (like TXO) start new structure in every CONTINUE
+type xlsRecord struct {
+	recordType uint16
+	data       []byte
+	continues  [][]byte
+}
+
+// xlsRecordReader reads little-endian fields of record payload, reading past the end sets err and returns zeros
+type xlsRecordReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (reader *xlsRecordReader) bytes(n int) []byte {
+	if nil != reader.err || n < 0 || n > len(reader.data)-reader.offset {
+		if nil == reader.err {
+			reader.err = io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	res := reader.data[reader.offset : reader.offset+n]
+	reader.offset += n
+	return res
+}
+
+func (reader *xlsRecordReader) u8() byte {
+	if data := reader.bytes(1); nil != data {
+		return data[0]
+	}
+	return 0
+}
+
+func (reader *xlsRecordReader) u16() uint16 {
+	if data := reader.bytes(2); nil != data {
+		return binary.LittleEndian.Uint16(data)
+	}
+	return 0
+}
+
+func (reader *xlsRecordReader) u32() uint32 {
+	if data := reader.bytes(4); nil != data {
+		return binary.LittleEndian.Uint32(data)
+	}
+	return 0
+}
+
+// chars reads count characters preceded by option flags byte, high byte flag selects UTF-16 over compressed latin1
+func (reader *xlsRecordReader) chars(count int) string {
+	if 0 == reader.u8()&0x01 {
+		return decodeLatin1(reader.bytes(count))
+	}
+	return decodeUTF16LE(reader.bytes(count * 2))
+}
+
+// shortString reads ShortXLUnicodeString, 8-bit length is followed by characters
+func (reader *xlsRecordReader) shortString() string {
+	return reader.chars(int(reader.u8()))
+}
+
+// unicodeString reads XLUnicodeString, 16-bit length is followed by characters
+func (reader *xlsRecordReader) unicodeString() string {
+	return reader.chars(int(reader.u16()))
+}
+
+// hyperlinkString reads HyperlinkString, 32-bit length counts UTF-16 characters including terminating zero
+func (reader *xlsRecordReader) hyperlinkString() string {
+	length := reader.u32()
+	if length > uint32(len(reader.data)) {
+		reader.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	return strings.TrimRight(decodeUTF16LE(reader.bytes(int(length)*2)), "\x00")
+}
+
+func decodeLatin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, char := range data {
+		runes[i] = rune(char)
+	}
+	return string(runes)
+}
+
+func decodeUTF16LE(data []byte) string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(chars))
+}
+
+// walkXLSRecords calls handler for records of substream started by BOF at offset up to its EOF,
+// embedded substreams (like charts placed on sheet) are skipped
+func walkXLSRecords(stream []byte, offset int, handler func(record *xlsRecord) error) error {
+	depth := 0
+	for offset+4 <= len(stream) {
+		recordType := binary.LittleEndian.Uint16(stream[offset:])
+		size := int(binary.LittleEndian.Uint16(stream[offset+2:]))
+		if offset+4+size > len(stream) {
+			return fmt.Errorf("record 0x%04X at offset %d is truncated", recordType, offset)
+		}
+		record := &xlsRecord{recordType: recordType, data: stream[offset+4 : offset+4+size]}
+		offset += 4 + size
+		for offset+4 <= len(stream) && xlsRecordContinue == binary.LittleEndian.Uint16(stream[offset:]) {
+			size = int(binary.LittleEndian.Uint16(stream[offset+2:]))
+			if offset+4+size > len(stream) {
+				return fmt.Errorf("record 0x%04X at offset %d is truncated", xlsRecordContinue, offset)
+			}
+			record.continues = append(record.continues, stream[offset+4:offset+4+size])
+			offset += 4 + size
+		}
+		switch recordType {
+		case xlsRecordBOF:
+			depth++
+			continue
+		case xlsRecordEOF:
+			depth--
+			if depth <= 0 {
+				return nil
+			}
+			continue
+		}
+		if 1 != depth {
+			continue
+		}
+		if err := handler(record); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRecords loads Workbook stream, reads styles and finds substreams of sheets, records of sheet are parsed on demand
+func (xls *xlsHandle) readRecords(fileName string) error {
+	err, cfb := openCFB(fileName)
+	if nil != err {
+		return err
+	}
+	defer nowarnCloseCloser(cfb)
+	err, xls.stream = cfb.readStream("Workbook")
+	if nil != err {
+		return err
+	}
+	xls.fonts = []TCellFont{}
+	xls.numFormats = make(map[int]string)
+	xls.cellStyles = []TCellStyle{}
+	xls.sheetNames = []string{}
+	xls.externSheets = []int{}
+	xls.definedNames = []string{}
+	internalSupBooks := []bool{}
+	sheetId := 0
+	return walkXLSRecords(xls.stream, 0, func(record *xlsRecord) error {
+		switch record.recordType {
+		case xlsRecordSupBook:
+			// supporting link of own workbook keeps 0x0401 marker in place of file name length
+			internalSupBooks = append(internalSupBooks, 4 == len(record.data) && 0x0401 == binary.LittleEndian.Uint16(record.data[2:]))
+			return nil
+		case xlsRecordExternSheet:
+			reader := &xlsRecordReader{data: bytes.Join(append([][]byte{record.data}, record.continues...), nil)}
+			for count := int(reader.u16()); count > 0; count-- {
+				supBookId, sheetFirst := int(reader.u16()), int(reader.u16())
+				reader.u16() // last sheet of 3D reference
+				if supBookId >= len(internalSupBooks) || !internalSupBooks[supBookId] {
+					sheetFirst = -1
+				}
+				xls.externSheets = append(xls.externSheets, sheetFirst)
+			}
+			if nil != reader.err {
+				return fmt.Errorf("EXTERNSHEET record is broken: %s", reader.err)
+			}
+			return nil
+		case xlsRecordName:
+			reader := &xlsRecordReader{data: record.data}
+			flags := reader.u16()
+			reader.u8() // keyboard shortcut
+			length := int(reader.u8())
+			reader.bytes(2 + 2 + 2 + 4) // formula size, reserved, sheet index, reserved
+			name := reader.chars(length)
+			if nil != reader.err {
+				return fmt.Errorf("NAME record is broken: %s", reader.err)
+			}
+			if 0 != flags&0x0020 && 1 == len(name) {
+				name = xlsEnumName(xlsBuiltinNames, int(name[0]))
+			}
+			xls.definedNames = append(xls.definedNames, name)
+			return nil
+		case xlsRecordFont:
+			err, font := readXLSFont(record.data)
+			if nil != err {
+				return err
+			}
+			xls.fonts = append(xls.fonts, font)
+			return nil
+		case xlsRecordFormat:
+			reader := &xlsRecordReader{data: record.data}
+			numFmtId := int(reader.u16())
+			xls.numFormats[numFmtId] = reader.unicodeString()
+			if nil != reader.err {
+				return fmt.Errorf("FORMAT record is broken: %s", reader.err)
+			}
+			return nil
+		case xlsRecordXF:
+			err, style := xls.readXLSCellStyle(record.data)
+			if nil != err {
+				return err
+			}
+			xls.cellStyles = append(xls.cellStyles, style)
+			return nil
+		case xlsRecordBoundSheet:
+		default:
+			return nil
+		}
+		reader := &xlsRecordReader{data: record.data}
+		offset := int(reader.u32())
+		reader.bytes(2)
+		name := reader.shortString()
+		if nil != reader.err {
+			return fmt.Errorf("BOUNDSHEET record is broken: %s", reader.err)
+		}
+		xls.sheetNames = append(xls.sheetNames, name)
+		// sheets are matched by name because xls reader package may skip substreams of other types
+		for id := sheetId; id < len(xls.sheets); id++ {
+			if xls.sheets[id].Name == name {
+				xls.sheets[id].offset = offset
+				sheetId = id + 1
+				break
+			}
+		}
+		return nil
+	})
+}
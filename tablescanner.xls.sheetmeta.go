@@ -0,0 +1,279 @@
+package tablescanner
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// xlsSheetMeta keeps sheet records which are not exposed by xls reader package, they are read by separate pass
+// over sheet substream which is started only when caller asks for such data
+type xlsSheetMeta struct {
+	hyperlinks      map[int][]xlsxHyperlink // row number to hyperlinks touching the row
+	comments        map[string]TCellComment
+	styles          map[int][]int // row number to XF indexes of row cells
+	rows            map[int]TRowInfo
+	columns         []TColumnInfo
+	freezePane      *TFreezePane // nil when sheet is not split
+	dataValidations []TDataValidation
+}
+
+// xlsNote is NOTE record which refers text of comment by drawing object id
+type xlsNote struct {
+	ref      string
+	author   string
+	objectId int
+}
+
+var (
+	xlsClsidURLMoniker  = []byte{0xE0, 0xC9, 0xEA, 0x79, 0xF9, 0xBA, 0xCE, 0x11, 0x8C, 0x82, 0x00, 0xAA, 0x00, 0x4B, 0xA9, 0x0B}
+	xlsClsidFileMoniker = []byte{0x03, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}
+)
+
+// hyperlink object flags
+const (
+	xlsHyperlinkHasMoniker        = 0x0001
+	xlsHyperlinkHasLocation       = 0x0008
+	xlsHyperlinkHasDisplayName    = 0x0010
+	xlsHyperlinkHasFrameName      = 0x0080
+	xlsHyperlinkMonikerSavedAsStr = 0x0100
+)
+
+func (xls *xlsHandle) requireSheetMeta(sheetId int) (error, *xlsSheetMeta) {
+	if err := checkSheetId(sheetId, len(xls.sheets)); nil != err {
+		return err, nil
+	}
+	sheet := xls.sheets[sheetId]
+	if nil != sheet.meta {
+		return nil, sheet.meta
+	}
+	if sheet.offset < 0 {
+		return fmt.Errorf("records of sheet #%d are not found", sheetId), nil
+	}
+	meta := &xlsSheetMeta{
+		hyperlinks:      make(map[int][]xlsxHyperlink),
+		comments:        make(map[string]TCellComment),
+		styles:          make(map[int][]int),
+		rows:            make(map[int]TRowInfo),
+		columns:         []TColumnInfo{},
+		dataValidations: []TDataValidation{},
+	}
+	// comment text is kept in TXO record following OBJ record of the note, NOTE records come after all objects
+	notes := []xlsNote{}
+	texts := make(map[int]string)
+	objectId := -1
+	frozen := false
+	validationsCount := 0
+	err := walkXLSRecords(xls.stream, sheet.offset, func(record *xlsRecord) error {
+		switch record.recordType {
+		case xlsRecordNumber, xlsRecordLabelSST, xlsRecordRk, xlsRecordBlank, xlsRecordBoolErr, xlsRecordFormula,
+			xlsRecordLabel, xlsRecordRString:
+			reader := &xlsRecordReader{data: record.data}
+			row, column, styleId := int(reader.u16()), int(reader.u16()), int(reader.u16())
+			if nil == reader.err {
+				meta.setCellStyle(row, column, styleId)
+			}
+		case xlsRecordMulRk, xlsRecordMulBlank:
+			if len(record.data) < 6 {
+				break
+			}
+			// XF index of every cell is followed by RK value in MULRK, last column closes the record
+			reader := &xlsRecordReader{data: record.data[:len(record.data)-2]}
+			row, column := int(reader.u16()), int(reader.u16())
+			for ; reader.offset < len(reader.data); column++ {
+				styleId := int(reader.u16())
+				if xlsRecordMulRk == record.recordType {
+					reader.bytes(4)
+				}
+				if nil != reader.err {
+					break
+				}
+				meta.setCellStyle(row, column, styleId)
+			}
+		case xlsRecordRow:
+			reader := &xlsRecordReader{data: record.data}
+			row := int(reader.u16())
+			reader.bytes(10)
+			flags := reader.u32()
+			if nil == reader.err {
+				meta.rows[row+1] = TRowInfo{Hidden: 0 != flags&0x20, OutlineLevel: int(flags & 0x07), Collapsed: 0 != flags&0x10}
+			}
+		case xlsRecordColInfo:
+			reader := &xlsRecordReader{data: record.data}
+			columnFirst, columnLast := int(reader.u16()), int(reader.u16())
+			reader.bytes(4) // width, XF index
+			flags := reader.u16()
+			if nil == reader.err {
+				info := TColumnInfo{Hidden: 0 != flags&0x0001, OutlineLevel: int(flags >> 8 & 0x07), Collapsed: 0 != flags&0x1000}
+				meta.columns = setColumnsInfo(meta.columns, columnFirst+1, columnLast+1, info)
+			}
+		case xlsRecordWindow2:
+			reader := &xlsRecordReader{data: record.data}
+			frozen = 0 != reader.u16()&0x0008
+		case xlsRecordPane:
+			// WINDOW2 record precedes PANE and tells whether panes are frozen
+			reader := &xlsRecordReader{data: record.data}
+			x, y := int(reader.u16()), int(reader.u16())
+			top, left := int(reader.u16()), int(reader.u16())
+			if nil != reader.err {
+				return fmt.Errorf("PANE record is broken: %s", reader.err)
+			}
+			meta.freezePane = &TFreezePane{Frozen: frozen, Rows: y, Columns: x, TopLeftCell: makeCellAddr(left+1, top+1)}
+			if !frozen {
+				// split position is measured in twips, so pane extent is taken from its first cell
+				meta.freezePane.Rows = top
+				meta.freezePane.Columns = left
+			}
+		case xlsRecordDVal:
+			// DVAL record is followed by DV records, one per validation
+			reader := &xlsRecordReader{data: record.data}
+			reader.bytes(2 + 4 + 4 + 4) // flags, drop-down position, drop-down object id
+			validationsCount = int(reader.u32())
+			if nil != reader.err {
+				return fmt.Errorf("DVAL record is broken: %s", reader.err)
+			}
+		case xlsRecordDV:
+			if validationsCount <= 0 {
+				break
+			}
+			validationsCount--
+			err, validation := xls.readXLSDataValidation(record.data)
+			if nil != err {
+				return err
+			}
+			meta.dataValidations = append(meta.dataValidations, validation)
+		case xlsRecordHLink:
+			return meta.collectHyperlink(record)
+		case xlsRecordObj:
+			// the first subrecord is ftCmo keeping object type and id
+			reader := &xlsRecordReader{data: record.data}
+			reader.bytes(2 + 2 + 2)
+			objectId = int(reader.u16())
+			if nil != reader.err {
+				objectId = -1
+			}
+		case xlsRecordTxo:
+			if objectId >= 0 {
+				texts[objectId] = readXLSTextObject(record)
+				objectId = -1
+			}
+		case xlsRecordNote:
+			reader := &xlsRecordReader{data: record.data}
+			row, column := int(reader.u16()), int(reader.u16())
+			reader.bytes(2)
+			note := xlsNote{ref: makeCellAddr(column+1, row+1), objectId: int(reader.u16())}
+			note.author = reader.unicodeString()
+			if nil != reader.err {
+				return fmt.Errorf("NOTE record is broken: %s", reader.err)
+			}
+			notes = append(notes, note)
+		}
+		return nil
+	})
+	if nil != err {
+		return fmt.Errorf("records of sheet #%d are broken: %s", sheetId, err), nil
+	}
+	for _, note := range notes {
+		meta.comments[note.ref] = TCellComment{Ref: note.ref, Author: note.author, Text: texts[note.objectId]}
+	}
+	sheet.meta = meta
+	return nil, meta
+}
+
+func (meta *xlsSheetMeta) setCellStyle(row int, column int, styleId int) {
+	styles := meta.styles[row+1]
+	for len(styles) <= column {
+		styles = append(styles, 0)
+	}
+	styles[column] = styleId
+	meta.styles[row+1] = styles
+}
+
+// collectHyperlink reads HLINK record, URL and file monikers are supported, location is appended after '#'
+func (meta *xlsSheetMeta) collectHyperlink(record *xlsRecord) error {
+	reader := &xlsRecordReader{data: record.data}
+	rowFirst, rowLast := int(reader.u16()), int(reader.u16())
+	columnFirst, columnLast := int(reader.u16()), int(reader.u16())
+	reader.bytes(16 + 4) // hlinkClsid, streamVersion
+	flags := reader.u32()
+	if 0 != flags&xlsHyperlinkHasDisplayName {
+		reader.hyperlinkString()
+	}
+	if 0 != flags&xlsHyperlinkHasFrameName {
+		reader.hyperlinkString()
+	}
+	target := ""
+	if 0 != flags&xlsHyperlinkHasMoniker {
+		if 0 != flags&xlsHyperlinkMonikerSavedAsStr {
+			target = reader.hyperlinkString()
+		} else if ok, moniker := reader.moniker(); ok {
+			target = moniker
+		} else {
+			// composite and item monikers are not resolved to target
+			return nil
+		}
+	}
+	if 0 != flags&xlsHyperlinkHasLocation {
+		target += "#" + reader.hyperlinkString()
+	}
+	if nil != reader.err {
+		return fmt.Errorf("HLINK record is broken: %s", reader.err)
+	}
+	if "" == target {
+		return nil
+	}
+	for y := rowFirst + 1; y <= rowLast+1; y++ {
+		meta.hyperlinks[y] = append(meta.hyperlinks[y], xlsxHyperlink{columnFirst: columnFirst + 1, columnLast: columnLast + 1, target: target})
+	}
+	return nil
+}
+
+// readXLSTextObject returns text of TXO record, characters are placed in CONTINUE records
+// and every CONTINUE starts with its own option flags byte
+func readXLSTextObject(record *xlsRecord) string {
+	reader := &xlsRecordReader{data: record.data}
+	reader.bytes(10)
+	remaining := int(reader.u16())
+	var text strings.Builder
+	for _, data := range record.continues {
+		if remaining <= 0 || 0 == len(data) {
+			break
+		}
+		count := len(data) - 1
+		if 0 != data[0]&0x01 {
+			count /= 2
+		}
+		if count > remaining {
+			count = remaining
+		}
+		chars := &xlsRecordReader{data: data}
+		text.WriteString(chars.chars(count))
+		remaining -= count
+	}
+	return strings.Replace(text.String(), "\r\n", "\n", -1)
+}
+
+// moniker reads URL or file moniker of hyperlink, ok is false for other moniker types
+func (reader *xlsRecordReader) moniker() (bool, string) {
+	clsid := reader.bytes(16)
+	switch {
+	case bytes.Equal(xlsClsidURLMoniker, clsid):
+		// url is followed by optional serial guid, version and flags which are counted by length too
+		url := decodeUTF16LE(reader.bytes(int(reader.u32())))
+		if end := strings.IndexByte(url, 0); end >= 0 {
+			url = url[:end]
+		}
+		return true, url
+	case bytes.Equal(xlsClsidFileMoniker, clsid):
+		parentLevels := int(reader.u16())
+		path := decodeLatin1(bytes.TrimRight(reader.bytes(int(reader.u32())), "\x00"))
+		reader.bytes(2 + 2 + 16 + 4) // endServer, versionNumber, reserved
+		if 0 != reader.u32() {
+			size := int(reader.u32())
+			reader.bytes(2) // usKeyValue
+			path = decodeUTF16LE(reader.bytes(size))
+		}
+		return true, strings.Repeat(`..\`, parentLevels) + path
+	}
+	return false, ""
+}
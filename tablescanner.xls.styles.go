@@ -0,0 +1,134 @@
+package tablescanner
+
+import (
+	"fmt"
+	"strconv"
+)
+
+var xlsFillPatterns = []string{"none", "solid", "mediumGray", "darkGray", "lightGray", "darkHorizontal", "darkVertical",
+	"darkDown", "darkUp", "darkGrid", "darkTrellis", "lightHorizontal", "lightVertical", "lightDown", "lightUp",
+	"lightGrid", "lightTrellis", "gray125", "gray0625"}
+
+var xlsBorderStyles = []string{"", "thin", "medium", "dashed", "dotted", "thick", "double", "hair", "mediumDashed",
+	"dashDot", "mediumDashDot", "dashDotDot", "mediumDashDotDot", "slantDashDot"}
+
+var xlsHorizontalAlignments = []string{"", "left", "center", "right", "fill", "justify", "centerContinuous", "distributed"}
+
+var xlsVerticalAlignments = []string{"top", "center", "", "justify", "distributed"}
+
+// xlsColor converts palette index to the same reference as xml colors use, 0x7FFF is window text color
+func xlsColor(index int) string {
+	if 0x7FFF == index {
+		return "auto"
+	}
+	return "indexed:" + strconv.Itoa(index)
+}
+
+func xlsEnumName(names []string, index int) string {
+	if index < len(names) {
+		return names[index]
+	}
+	return ""
+}
+
+func readXLSFont(data []byte) (error, TCellFont) {
+	reader := &xlsRecordReader{data: data}
+	font := TCellFont{Size: float64(reader.u16()) / 20}
+	flags := reader.u16()
+	font.Italic = 0 != flags&0x02
+	font.Strike = 0 != flags&0x08
+	font.Color = xlsColor(int(reader.u16()))
+	font.Bold = reader.u16() >= 700
+	reader.bytes(2) // superscript/subscript
+	switch reader.u8() {
+	case 0x01:
+		font.Underline = "single"
+	case 0x02:
+		font.Underline = "double"
+	case 0x21:
+		font.Underline = "singleAccounting"
+	case 0x22:
+		font.Underline = "doubleAccounting"
+	}
+	reader.bytes(3) // family, charset, reserved
+	font.Name = reader.shortString()
+	if nil != reader.err {
+		return fmt.Errorf("FONT record is broken: %s", reader.err), TCellFont{}
+	}
+	return nil, font
+}
+
+// readXLSCellStyle reads XF record, fonts and number formats have to be read before
+func (xls *xlsHandle) readXLSCellStyle(data []byte) (error, TCellStyle) {
+	reader := &xlsRecordReader{data: data}
+	fontId := int(reader.u16())
+	style := TCellStyle{NumFmtId: int(reader.u16())}
+	protection := reader.u16()
+	style.Protection = TCellProtection{Locked: 0 != protection&0x0001, Hidden: 0 != protection&0x0002}
+	alignment := reader.u8()
+	style.Alignment.Horizontal = xlsEnumName(xlsHorizontalAlignments, int(alignment&0x07))
+	style.Alignment.WrapText = 0 != alignment&0x08
+	style.Alignment.Vertical = xlsEnumName(xlsVerticalAlignments, int(alignment>>4&0x07))
+	style.Alignment.TextRotation = int(reader.u8())
+	indent := reader.u8()
+	style.Alignment.Indent = int(indent & 0x0F)
+	style.Alignment.ShrinkToFit = 0 != indent&0x10
+	reader.bytes(1) // used attributes
+	borders := reader.u32()
+	colors := reader.u32()
+	fillColors := reader.u16()
+	if nil != reader.err {
+		return fmt.Errorf("XF record is broken: %s", reader.err), TCellStyle{}
+	}
+	edge := func(lineStyle uint32, color uint32) TCellBorderEdge {
+		if 0 == lineStyle {
+			return TCellBorderEdge{}
+		}
+		return TCellBorderEdge{Style: xlsEnumName(xlsBorderStyles, int(lineStyle)), Color: xlsColor(int(color))}
+	}
+	style.Border = TCellBorder{
+		Left:         edge(borders&0x0F, borders>>16&0x7F),
+		Right:        edge(borders>>4&0x0F, borders>>23&0x7F),
+		Top:          edge(borders>>8&0x0F, colors&0x7F),
+		Bottom:       edge(borders>>12&0x0F, colors>>7&0x7F),
+		Diagonal:     edge(colors>>21&0x0F, colors>>14&0x7F),
+		DiagonalDown: 0 != borders&0x40000000,
+		DiagonalUp:   0 != borders&0x80000000,
+	}
+	style.Fill = TCellFill{PatternType: xlsEnumName(xlsFillPatterns, int(colors>>26&0x3F))}
+	if "none" != style.Fill.PatternType {
+		style.Fill.FgColor = xlsColor(int(fillColors & 0x7F))
+		style.Fill.BgColor = xlsColor(int(fillColors >> 7 & 0x7F))
+	}
+	// font #4 is never written, so following fonts are shifted
+	if fontId > 4 {
+		fontId--
+	}
+	if fontId < len(xls.fonts) {
+		style.Font = xls.fonts[fontId]
+	}
+	style.NumFmt = xls.numFormats[style.NumFmtId]
+	if "" == style.NumFmt {
+		style.NumFmt = numFmtI18n["en"].numFmtDefaults[style.NumFmtId]
+	}
+	return nil, style
+}
+
+// GetScannedStyles returns XF indexes of scanned row cells, use GetStyle() to resolve them
+func (xls *xlsHandle) GetScannedStyles() []int {
+	res := make([]int, len(xls.iteratorScannedData))
+	err, meta := xls.requireSheetMeta(xls.iteratorSheetId)
+	if nil != err {
+		return res
+	}
+	copy(res, meta.styles[xls.iteratorRowNum])
+	return pickInts(res, xls.visibleColumnIds(len(res)))
+}
+
+func (xls *xlsHandle) GetStyle(styleId int) (error, *TCellStyle) {
+	if styleId < 0 || styleId >= len(xls.cellStyles) {
+		return fmt.Errorf("style #%d not found", styleId), nil
+	}
+	style := xls.cellStyles[styleId]
+	return nil, &style
+}
@@ -0,0 +1,57 @@
+package tablescanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+var xlsValidationTypes = []string{"any", "whole", "decimal", "list", "date", "time", "textLength", "custom"}
+
+var xlsValidationOperators = []string{"between", "notBetween", "equal", "notEqual", "greaterThan", "lessThan",
+	"greaterThanOrEqual", "lessThanOrEqual"}
+
+// readXLSDataValidation reads DV record, formulas are decoded to text and left empty when they can not be decoded
+func (xls *xlsHandle) readXLSDataValidation(data []byte) (error, TDataValidation) {
+	reader := &xlsRecordReader{data: data}
+	flags := reader.u32()
+	text := func() string {
+		// empty strings are stored as single zero character
+		return strings.TrimRight(reader.unicodeString(), "\x00")
+	}
+	text() // prompt title
+	errorTitle := text()
+	text() // prompt
+	errorText := text()
+	formulas := [2]string{}
+	for i := range formulas {
+		size := int(reader.u16())
+		reader.bytes(2)
+		formulas[i], _ = xls.decodeFormula(reader.bytes(size))
+	}
+	refs := make([]string, int(reader.u16()))
+	for i := range refs {
+		rowFirst, rowLast := int(reader.u16()), int(reader.u16())
+		columnFirst, columnLast := int(reader.u16()), int(reader.u16())
+		refs[i] = makeCellAddr(columnFirst+1, rowFirst+1)
+		if rowFirst != rowLast || columnFirst != columnLast {
+			refs[i] += ":" + makeCellAddr(columnLast+1, rowLast+1)
+		}
+	}
+	if nil != reader.err {
+		return fmt.Errorf("DV record is broken: %s", reader.err), TDataValidation{}
+	}
+	validationType := xlsEnumName(xlsValidationTypes, int(flags&0x0F))
+	operator := ""
+	if "any" != validationType && "list" != validationType && "custom" != validationType {
+		operator = xlsEnumName(xlsValidationOperators, int(flags>>20&0x0F))
+	}
+	return nil, makeDataValidation(validationType, operator, 0 != flags&0x0100, errorTitle, errorText, strings.Join(refs, " "), formulas[0], formulas[1])
+}
+
+func (xls *xlsHandle) GetDataValidations(sheetId int) (error, []TDataValidation) {
+	err, meta := xls.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.dataValidations
+}
@@ -0,0 +1,726 @@
+package tablescanner
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// BIFF12 record types used by scanner
+const (
+	biff12RowHdr          = 0
+	biff12CellBlank       = 1
+	biff12CellRk          = 2
+	biff12CellError       = 3
+	biff12CellBool        = 4
+	biff12CellReal        = 5
+	biff12CellSt          = 6
+	biff12CellIsst        = 7
+	biff12FmlaString      = 8
+	biff12FmlaNum         = 9
+	biff12FmlaBool        = 10
+	biff12FmlaError       = 11
+	biff12SSTItem         = 19
+	biff12Font            = 43
+	biff12Fmt             = 44
+	biff12XF              = 47
+	biff12ColInfo         = 60
+	biff12CellRString     = 62
+	biff12BookView        = 135
+	biff12EndSheetData    = 146
+	biff12WsDim           = 148
+	biff12WbProp          = 153
+	biff12BundleSh        = 156
+	biff12HLink           = 494
+	biff12BeginCellXFs    = 617
+	biff12EndCellXFs      = 618
+	biff12BeginCellStyleX = 626
+)
+
+var biff12ErrorCodes = map[byte]string{
+	0x00: CellErrorNull,
+	0x07: CellErrorDivZero,
+	0x0F: CellErrorValue,
+	0x17: CellErrorRef,
+	0x1D: CellErrorName,
+	0x24: CellErrorNum,
+	0x2A: CellErrorNA,
+	0x2B: CellErrorGettingData,
+}
+
+// xlsbStream shares zip, relations, number formats and row iterator state with xlsx backend,
+// only workbook parts are parsed from BIFF12 records instead of xml
+type xlsbStream struct {
+	xlsxStream
+	iteratorRecords   *bufio.Reader // current row-iterating record stream
+	iteratorRecordBuf []byte        // reusable record payload buffer
+	iteratorRowHdr    []byte        // row header already read while finishing previous row, nil when absent
+	iteratorEnded     bool          // end of sheet data is reached
+}
+
+func newXLSBStream(fileName string) (error, ITableDocumentScanner) {
+	z, err := zip.OpenReader(fileName)
+	if err != nil {
+		return err, nil
+	}
+	return newXLSBStreamFromZip(fileName, &z.Reader, z)
+}
+
+// newXLSBStreamFromZip reads workbook of opened package, closer is nil for packages kept in memory
+func newXLSBStreamFromZip(fileName string, z *zip.Reader, closer io.Closer) (error, ITableDocumentScanner) {
+	xlsb := &xlsbStream{}
+	xlsb.zFileName = fileName
+	xlsb.z = z
+	xlsb.zCloser = closer
+	err := xlsb.SetI18n("en")
+	if nil != err {
+		return err, nil
+	}
+	xlsb.zFiles = make(map[string]*zip.File, len(xlsb.z.File))
+	for _, v := range xlsb.z.File {
+		xlsb.zFiles[v.Name] = v
+	}
+	workbookPath := xlsb.resolveWorkbookPart("xl/workbook.bin")
+	err = xlsb.getWorkbookRelations(workbookRelationsPath(workbookPath))
+	if err != nil {
+		return err, nil
+	}
+	err = xlsb.readBinarySharedStrings()
+	if err != nil {
+		return err, nil
+	}
+	err = xlsb.readBinaryStyles()
+	if err != nil {
+		return err, nil
+	}
+	err = xlsb.readBinaryWorkbook(workbookPath)
+	if err != nil {
+		return err, nil
+	}
+	return nil, xlsb
+}
+
+// readBiff12Record reads record header and payload, payload buffer is reused between calls
+func readBiff12Record(reader *bufio.Reader, buf []byte) (err error, recordType int, data []byte) {
+	for i := 0; i < 2; i++ {
+		b, err := reader.ReadByte()
+		if nil != err {
+			if 0 != i && io.EOF == err {
+				err = io.ErrUnexpectedEOF
+			}
+			return err, 0, buf
+		}
+		recordType |= int(b&0x7F) << (7 * uint(i))
+		if 0 == b&0x80 {
+			break
+		}
+	}
+	size := 0
+	for i := 0; i < 4; i++ {
+		b, err := reader.ReadByte()
+		if nil != err {
+			return io.ErrUnexpectedEOF, 0, buf
+		}
+		size |= int(b&0x7F) << (7 * uint(i))
+		if 0 == b&0x80 {
+			break
+		}
+	}
+	if cap(buf) < size {
+		buf = make([]byte, size)
+	}
+	data = buf[:size]
+	_, err = io.ReadFull(reader, data)
+	if nil != err {
+		return io.ErrUnexpectedEOF, 0, buf
+	}
+	return nil, recordType, data
+}
+
+// readBiff12Part calls handler for every record of zip part, missing part is reported by error
+func (xlsb *xlsbStream) readBiff12Part(path string, handler func(recordType int, data []byte) error) error {
+	z, err := xlsb.findZipHandler(path)
+	if nil != err {
+		return err
+	}
+	rc, err := z.Open()
+	if err != nil {
+		return fmt.Errorf("file stream [%s] Open() failed: %s", path, err.Error())
+	}
+	defer nowarnCloseCloser(rc)
+	reader := bufio.NewReader(rc)
+	var buf []byte
+	for {
+		err, recordType, data := readBiff12Record(reader, buf)
+		if io.EOF == err {
+			return nil
+		}
+		if nil != err {
+			return fmt.Errorf("record read error in [%s]: %s", path, err)
+		}
+		buf = data
+		err = handler(recordType, data)
+		if nil != err {
+			return fmt.Errorf("cannot parse record #%d in [%s]: %s", recordType, path, err)
+		}
+	}
+}
+
+// readXLWideString reads length-prefixed UTF-16LE string, 0xFFFFFFFF length is treated as empty (nullable string)
+func readXLWideString(data []byte, offset int) (err error, value string, next int) {
+	if offset+4 > len(data) {
+		return io.ErrUnexpectedEOF, "", offset
+	}
+	length := binary.LittleEndian.Uint32(data[offset:])
+	offset += 4
+	if 0xFFFFFFFF == length {
+		return nil, "", offset
+	}
+	if uint64(offset)+uint64(length)*2 > uint64(len(data)) {
+		return io.ErrUnexpectedEOF, "", offset
+	}
+	chars := make([]uint16, length)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[offset+i*2:])
+	}
+	return nil, string(utf16.Decode(chars)), offset + int(length)*2
+}
+
+func (xlsb *xlsbStream) readBinarySharedStrings() error {
+	xlsb.referenceTable = []string{}
+	xlsb.referenceRichTable = make(map[int][]TRichTextRun)
+	if _, err := xlsb.findZipHandler(xlsb.zPathSharedStrings); nil != err {
+		// non-critical error: sharedStrings file not found
+		return nil
+	}
+	return xlsb.readBiff12Part(xlsb.zPathSharedStrings, func(recordType int, data []byte) error {
+		if biff12SSTItem != recordType {
+			return nil
+		}
+		// RichStr: flags byte followed by string, formatting runs and phonetic data are ignored
+		err, value, _ := readXLWideString(data, 1)
+		if nil != err {
+			return err
+		}
+		xlsb.referenceTable = append(xlsb.referenceTable, value)
+		return nil
+	})
+}
+
+// readBinaryStyles reads number formats and cell formats, fonts are the only resolved formatting
+func (xlsb *xlsbStream) readBinaryStyles() error {
+	xlsb.numFmtCustom = make([]string, 0, 256)
+	xlsb.style2numFmtId = make([]int, 0, 32)
+	xlsb.styleNumberFormatCache = make([]*parsedNumberFormat, 0, 256)
+	xlsb.cellStyles = []TCellStyle{}
+	if _, err := xlsb.findZipHandler(xlsb.zPathStyles); nil != err {
+		// non-critical error: styles file not found
+		return nil
+	}
+	fonts := []TCellFont{}
+	inCellXfs := false
+	return xlsb.readBiff12Part(xlsb.zPathStyles, func(recordType int, data []byte) error {
+		switch recordType {
+		case biff12Fmt:
+			if len(data) < 2 {
+				return io.ErrUnexpectedEOF
+			}
+			numFmtId := int(binary.LittleEndian.Uint16(data))
+			err, formatCode, _ := readXLWideString(data, 2)
+			if nil != err {
+				return err
+			}
+			for len(xlsb.numFmtCustom) < numFmtId+1 {
+				xlsb.numFmtCustom = append(xlsb.numFmtCustom, "")
+			}
+			xlsb.numFmtCustom[numFmtId] = formatCode
+		case biff12Font:
+			err, font := readBiff12Font(data)
+			if nil != err {
+				return err
+			}
+			fonts = append(fonts, font)
+		case biff12BeginCellXFs:
+			inCellXfs = true
+		case biff12EndCellXFs, biff12BeginCellStyleX:
+			inCellXfs = false
+		case biff12XF:
+			if !inCellXfs {
+				break
+			}
+			if len(data) < 6 {
+				return io.ErrUnexpectedEOF
+			}
+			numFmtId := int(binary.LittleEndian.Uint16(data[2:]))
+			fontId := int(binary.LittleEndian.Uint16(data[4:]))
+			style := TCellStyle{NumFmtId: numFmtId}
+			if fontId < len(fonts) {
+				style.Font = fonts[fontId]
+			}
+			xlsb.style2numFmtId = append(xlsb.style2numFmtId, numFmtId)
+			xlsb.cellStyles = append(xlsb.cellStyles, style)
+		}
+		return nil
+	})
+}
+
+func readBiff12Font(data []byte) (error, TCellFont) {
+	if len(data) < 25 {
+		return io.ErrUnexpectedEOF, TCellFont{}
+	}
+	flags := binary.LittleEndian.Uint16(data[2:])
+	font := TCellFont{
+		Size:   float64(binary.LittleEndian.Uint16(data)) / 20,
+		Bold:   binary.LittleEndian.Uint16(data[4:]) >= 700,
+		Italic: 0 != flags&0x02,
+		Strike: 0 != flags&0x08,
+		Color:  biff12Color(data[12:20]),
+	}
+	switch data[8] {
+	case 0x01:
+		font.Underline = "single"
+	case 0x02:
+		font.Underline = "double"
+	case 0x21:
+		font.Underline = "singleAccounting"
+	case 0x22:
+		font.Underline = "doubleAccounting"
+	}
+	err, name, _ := readXLWideString(data, 21)
+	if nil != err {
+		return err, TCellFont{}
+	}
+	font.Name = name
+	return nil, font
+}
+
+// biff12Color converts BrtColor to ARGB hex or palette reference like xml colors are
+func biff12Color(data []byte) string {
+	switch data[0] >> 1 {
+	case 0:
+		return "auto"
+	case 1:
+		return "indexed:" + strconv.Itoa(int(data[1]))
+	case 2:
+		return fmt.Sprintf("%02X%02X%02X%02X", data[7], data[4], data[5], data[6])
+	case 3:
+		return "theme:" + strconv.Itoa(int(data[1]))
+	}
+	return ""
+}
+
+func (xlsb *xlsbStream) readBinaryWorkbook(path string) error {
+	xlsb.formatter = *newExcelFormatter("en")
+	xlsb.sheets = []*xlsxTableSheetInfo{}
+	err := xlsb.readBiff12Part(path, func(recordType int, data []byte) error {
+		switch recordType {
+		case biff12WbProp:
+			if len(data) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			xlsb.formatter.setDate1904(0 != binary.LittleEndian.Uint32(data)&0x01)
+		case biff12BookView:
+			if len(data) < 28 {
+				return io.ErrUnexpectedEOF
+			}
+			xlsb.sheetSelected = int(binary.LittleEndian.Uint32(data[24:]))
+		case biff12BundleSh:
+			if len(data) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			err, rId, offset := readXLWideString(data, 8)
+			if nil != err {
+				return err
+			}
+			err, name, _ := readXLWideString(data, offset)
+			if nil != err {
+				return err
+			}
+			// undefined path isn't critical, broken sheet can be softly ignored while fetching
+			sheet := &xlsxTableSheetInfo{Name: name, HideLevel: TableSheetVisible, path: xlsb.relations[rId], rId: rId}
+			switch binary.LittleEndian.Uint32(data) {
+			case 1:
+				sheet.HideLevel = TableSheetHidden
+			case 2:
+				sheet.HideLevel = TableSheetVeryHidden
+			}
+			xlsb.sheets = append(xlsb.sheets, sheet)
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+	if xlsb.sheetSelected > len(xlsb.sheets)-1 {
+		xlsb.sheetSelected = len(xlsb.sheets) - 1
+	}
+	if xlsb.sheetSelected < 0 {
+		xlsb.sheetSelected = 0
+	}
+	_ = xlsb.SetSheetId(xlsb.sheetSelected)
+	return nil
+}
+
+func (xlsb *xlsbStream) SetSheetId(id int) error {
+	if err := checkSheetId(id, len(xlsb.sheets)); nil != err {
+		return err
+	}
+	xlsb.iteratorRecords = nil
+	xlsb.iteratorRowHdr = nil
+	xlsb.iteratorEnded = false
+	return xlsb.xlsxStream.SetSheetId(id)
+}
+
+func (xlsb *xlsbStream) requireScanStream() error {
+	if nil == xlsb.iteratorStream {
+		z, err := xlsb.findZipHandler(xlsb.sheets[xlsb.iteratorSheetId].path)
+		if nil != err {
+			return fmt.Errorf("sheet #%d not found: %s", xlsb.iteratorSheetId, err)
+		}
+		xlsb.iteratorStream, err = z.Open()
+		if err != nil {
+			return fmt.Errorf("file stream [%s] Open() failed: %s", xlsb.sheets[xlsb.iteratorSheetId].path, err.Error())
+		}
+		xlsb.iteratorRecords = bufio.NewReader(xlsb.iteratorStream)
+	}
+	return nil
+}
+
+func (xlsb *xlsbStream) Scan() (err error) {
+	for {
+		// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
+		if xlsb.iteratorScannedRowNum > xlsb.iteratorRowNum {
+			xlsb.iteratorRowNum++
+		} else {
+			err = xlsb.scanInternal()
+			if nil == err {
+				xlsb.iteratorRowNum++
+			}
+		}
+		if nil != err || !xlsb.visibilityFilter.isRowFiltered(xlsb.GetScannedRowInfo()) {
+			break
+		}
+	}
+	xlsb.iteratorLastError = err
+	return err
+}
+
+// scanInternal reads records up to the next row header, it is kept for the next call
+func (xlsb *xlsbStream) scanInternal() error {
+	err := xlsb.requireScanStream()
+	if nil != err {
+		return err
+	}
+	path := xlsb.sheets[xlsb.iteratorSheetId].path
+	for nil == xlsb.iteratorRowHdr {
+		if xlsb.iteratorEnded {
+			_ = xlsb.SetSheetId(xlsb.iteratorSheetId)
+			return io.EOF
+		}
+		err, recordType, data := readBiff12Record(xlsb.iteratorRecords, xlsb.iteratorRecordBuf)
+		if nil != err {
+			_ = xlsb.SetSheetId(xlsb.iteratorSheetId)
+			if io.EOF == err {
+				return err
+			}
+			return fmt.Errorf("record read error in [%s]: %s", path, err)
+		}
+		xlsb.iteratorRecordBuf = data
+		switch recordType {
+		case biff12WsDim:
+			if len(data) >= 16 {
+				xlsb.iteratorCapacity = int(binary.LittleEndian.Uint32(data[12:])) + 1
+			}
+		case biff12ColInfo:
+			xlsb.readColInfo(data)
+		case biff12RowHdr:
+			xlsb.iteratorRowHdr = append([]byte{}, data...)
+		case biff12EndSheetData:
+			xlsb.iteratorEnded = true
+		}
+	}
+	err = xlsb.startRow(xlsb.iteratorRowHdr)
+	xlsb.iteratorRowHdr = nil
+	if nil != err {
+		return fmt.Errorf("%s in [%s]", err, path)
+	}
+	for {
+		err, recordType, data := readBiff12Record(xlsb.iteratorRecords, xlsb.iteratorRecordBuf)
+		if io.EOF == err {
+			xlsb.iteratorEnded = true
+			return nil
+		}
+		if nil != err {
+			_ = xlsb.SetSheetId(xlsb.iteratorSheetId)
+			return fmt.Errorf("record read error in [%s]: %s", path, err)
+		}
+		xlsb.iteratorRecordBuf = data
+		switch recordType {
+		case biff12RowHdr:
+			xlsb.iteratorRowHdr = append([]byte{}, data...)
+			return nil
+		case biff12EndSheetData:
+			xlsb.iteratorEnded = true
+			return nil
+		case biff12CellBlank, biff12CellRk, biff12CellError, biff12CellBool, biff12CellReal, biff12CellSt, biff12CellIsst,
+			biff12FmlaString, biff12FmlaNum, biff12FmlaBool, biff12FmlaError, biff12CellRString:
+			err = xlsb.readCell(recordType, data)
+			if nil != err {
+				return fmt.Errorf("cannot parse cell of row #%d in [%s]: %s", xlsb.iteratorScannedRowNum, path, err)
+			}
+		}
+	}
+}
+
+// startRow resets scanned row by BrtRowHdr
+func (xlsb *xlsbStream) startRow(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("row header is truncated")
+	}
+	rowNum := int(binary.LittleEndian.Uint32(data)) + 1
+	if rowNum <= xlsb.iteratorRowNum {
+		return fmt.Errorf("row numbers are not strictly increasing ...%d...%d...", xlsb.iteratorRowNum, rowNum)
+	}
+	xlsb.iteratorScannedRowNum = rowNum
+	xlsb.iteratorScannedData = make([]string, 0, xlsb.iteratorCapacity)
+	xlsb.iteratorScannedRichText = nil
+	xlsb.iteratorScannedStyles = make([]int, 0, xlsb.iteratorCapacity)
+	xlsb.iteratorScannedErrors = nil
+	xlsb.iteratorScannedDecimals = nil
+	xlsb.iteratorScannedRowInfo = TRowInfo{
+		OutlineLevel: int(data[11] & 0x07),
+		Collapsed:    0 != data[11]&0x08,
+		Hidden:       0 != data[11]&0x10,
+	}
+	return nil
+}
+
+// readColInfo stores BrtColInfo visibility, columns become known while sheet is being scanned
+func (xlsb *xlsbStream) readColInfo(data []byte) {
+	if len(data) < 18 {
+		return
+	}
+	sheet := xlsb.sheets[xlsb.iteratorSheetId]
+	if nil == sheet.columns {
+		sheet.columns = []TColumnInfo{}
+	}
+	flags := binary.LittleEndian.Uint16(data[16:])
+	info := TColumnInfo{Hidden: 0 != flags&0x01, OutlineLevel: int(flags>>8) & 0x07, Collapsed: 0 != flags&0x1000}
+	sheet.columns = setColumnsInfo(sheet.columns, int(binary.LittleEndian.Uint32(data))+1, int(binary.LittleEndian.Uint32(data[4:]))+1, info)
+}
+
+// readCell decodes cell record and stores formatted value into scanned row
+func (xlsb *xlsbStream) readCell(recordType int, data []byte) error {
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	columnNum := int(binary.LittleEndian.Uint32(data)) + 1
+	styleId := int(binary.LittleEndian.Uint32(data[4:]) & 0xFFFFFF)
+	value := data[8:]
+	cellType := strCellTypeNumeric
+	cellValue := ""
+	switch recordType {
+	case biff12CellBlank:
+	case biff12CellRk:
+		if len(value) < 4 {
+			return io.ErrUnexpectedEOF
+		}
+		cellValue = formatBiff12Number(decodeRkNumber(binary.LittleEndian.Uint32(value)))
+		xlsb.setScannedDecimal(columnNum, cellValue)
+	case biff12CellReal, biff12FmlaNum:
+		if len(value) < 8 {
+			return io.ErrUnexpectedEOF
+		}
+		cellValue = formatBiff12Number(math.Float64frombits(binary.LittleEndian.Uint64(value)))
+		xlsb.setScannedDecimal(columnNum, cellValue)
+	case biff12CellError, biff12FmlaError:
+		if len(value) < 1 {
+			return io.ErrUnexpectedEOF
+		}
+		cellType = strCellTypeError
+		cellValue = biff12ErrorCodes[value[0]]
+		xlsb.setScannedError(columnNum, cellValue)
+	case biff12CellBool, biff12FmlaBool:
+		if len(value) < 1 {
+			return io.ErrUnexpectedEOF
+		}
+		cellType = strCellTypeBool
+		cellValue = strconv.Itoa(int(value[0] & 0x01))
+	case biff12CellSt, biff12FmlaString:
+		var err error
+		cellType = strCellTypeInline
+		err, cellValue, _ = readXLWideString(value, 0)
+		if nil != err {
+			return err
+		}
+	case biff12CellRString:
+		var err error
+		cellType = strCellTypeInline
+		err, cellValue, _ = readXLWideString(value, 1)
+		if nil != err {
+			return err
+		}
+	case biff12CellIsst:
+		if len(value) < 4 {
+			return io.ErrUnexpectedEOF
+		}
+		cellType = strCellTypeString
+		strId := int(binary.LittleEndian.Uint32(value))
+		if strId < len(xlsb.referenceTable) {
+			cellValue = xlsb.referenceTable[strId]
+		}
+	}
+	if biff12CellBlank != recordType {
+		parsedFormat := xlsb.getParsedNumFmtByStyle(styleId)
+		if nil != parsedFormat {
+			formatted, err := xlsb.formatter.FormatValue(cellValue, cellType, parsedFormat)
+			if nil == err {
+				cellValue = formatted
+			}
+		}
+	}
+	for len(xlsb.iteratorScannedData) < columnNum {
+		xlsb.iteratorScannedData = append(xlsb.iteratorScannedData, "")
+	}
+	xlsb.iteratorScannedData[columnNum-1] = cellValue
+	for len(xlsb.iteratorScannedStyles) < columnNum {
+		xlsb.iteratorScannedStyles = append(xlsb.iteratorScannedStyles, 0)
+	}
+	xlsb.iteratorScannedStyles[columnNum-1] = styleId
+	if columnNum > xlsb.iteratorCapacity {
+		xlsb.iteratorCapacity = columnNum
+	}
+	return nil
+}
+
+// decodeRkNumber unpacks RK value: 30-bit integer or the most significant bits of float64, optionally multiplied by 100
+func decodeRkNumber(rk uint32) float64 {
+	var value float64
+	if 0 != rk&0x02 {
+		value = float64(int32(rk) >> 2)
+	} else {
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if 0 != rk&0x01 {
+		value /= 100
+	}
+	return value
+}
+
+// formatBiff12Number renders number like xml parts keep it, so formatter gets the same input for both backends
+func formatBiff12Number(value float64) string {
+	abs := math.Abs(value)
+	if 0 != abs && (abs < 1e-9 || abs >= 1e21) {
+		return strconv.FormatFloat(value, 'E', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// GetColumnsInfo returns columns known by scanning, BrtColInfo records precede rows so they are read with the first row
+func (xlsb *xlsbStream) GetColumnsInfo(sheetId int) (error, []TColumnInfo) {
+	if sheetId < 0 || sheetId >= len(xlsb.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	if nil == xlsb.sheets[sheetId].columns {
+		return fmt.Errorf("columns info of XLSB sheet #%d is available after scanning started", sheetId), nil
+	}
+	return nil, xlsb.sheets[sheetId].columns
+}
+
+// requireSheetMeta reads BrtHLink records which follow sheet data, so the sheet part is read by separate pass;
+// other parts of xlsx sheet meta are not supported for XLSB format and are left empty
+func (xlsb *xlsbStream) requireSheetMeta(sheetId int) (error, *xlsxSheetMeta) {
+	sheet := xlsb.sheets[sheetId]
+	if nil != sheet.meta {
+		return nil, sheet.meta
+	}
+	err := xlsb.readSheetRelations(sheet)
+	if nil != err {
+		return err, nil
+	}
+	meta := &xlsxSheetMeta{
+		hyperlinks:      make(map[int][]xlsxHyperlink),
+		dataValidations: []TDataValidation{},
+	}
+	err = xlsb.readBiff12Part(sheet.path, func(recordType int, data []byte) error {
+		if biff12HLink != recordType {
+			return nil
+		}
+		// rfx of 0-based rwFirst, rwLast, colFirst, colLast is followed by relation id and location
+		if len(data) < 16 {
+			return io.ErrUnexpectedEOF
+		}
+		err, relationId, offset := readXLWideString(data, 16)
+		if nil != err {
+			return err
+		}
+		err, location, _ := readXLWideString(data, offset)
+		if nil != err {
+			return err
+		}
+		target := sheet.hyperlinkTarget(relationId, location)
+		if "" == target {
+			return nil
+		}
+		rowFirst, rowLast := int(binary.LittleEndian.Uint32(data)), int(binary.LittleEndian.Uint32(data[4:]))
+		hyperlink := xlsxHyperlink{columnFirst: int(binary.LittleEndian.Uint32(data[8:])) + 1, columnLast: int(binary.LittleEndian.Uint32(data[12:])) + 1, target: target}
+		for y := rowFirst + 1; y <= rowLast+1; y++ {
+			meta.hyperlinks[y] = append(meta.hyperlinks[y], hyperlink)
+		}
+		return nil
+	})
+	if nil != err {
+		return err, nil
+	}
+	sheet.meta = meta
+	return nil, meta
+}
+
+func (xlsb *xlsbStream) GetScannedHyperlinks() []string {
+	if err, _ := xlsb.requireSheetMeta(xlsb.iteratorSheetId); nil != err {
+		return []string{}
+	}
+	return xlsb.xlsxStream.GetScannedHyperlinks()
+}
+
+func (xlsb *xlsbStream) GetComments(sheetId int) (error, map[string]TCellComment) {
+	return fmt.Errorf("comments are not supported for XLSB format"), nil
+}
+
+func (xlsb *xlsbStream) SetPhoneticRuns(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("phonetic runs are not supported for XLSB format")
+	}
+	return nil
+}
+
+func (xlsb *xlsbStream) SetRichText(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("rich text is not supported for XLSB format")
+	}
+	return nil
+}
+
+func (xlsb *xlsbStream) GetAutoFilter(sheetId int) (error, *TAutoFilter) {
+	return fmt.Errorf("autofilter is not supported for XLSB format"), nil
+}
+
+func (xlsb *xlsbStream) SetAutoFilterEvaluation(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("autofilter evaluation is not supported for XLSB format")
+	}
+	return nil
+}
+
+func (xlsb *xlsbStream) GetFreezePane(sheetId int) (error, *TFreezePane) {
+	return fmt.Errorf("freeze panes are not supported for XLSB format"), nil
+}
+
+func (xlsb *xlsbStream) GetDataValidations(sheetId int) (error, []TDataValidation) {
+	return fmt.Errorf("data validations are not supported for XLSB format"), nil
+}
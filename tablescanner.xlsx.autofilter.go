@@ -0,0 +1,216 @@
+package tablescanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type xmlAutoFilter struct {
+	Ref          string            `xml:"ref,attr"`
+	FilterColumn []xmlFilterColumn `xml:"filterColumn"`
+	SortState    *xmlSortState     `xml:"sortState"`
+}
+
+type xmlFilterColumn struct {
+	ColId         int               `xml:"colId,attr"`
+	Filters       *xmlFilters       `xml:"filters"`
+	Top10         *xmlTop10         `xml:"top10"`
+	CustomFilters *xmlCustomFilters `xml:"customFilters"`
+	DynamicFilter *struct {
+		Type string `xml:"type,attr"`
+	} `xml:"dynamicFilter"`
+}
+
+type xmlFilters struct {
+	Blank  bool `xml:"blank,attr"`
+	Filter []struct {
+		Val string `xml:"val,attr"`
+	} `xml:"filter"`
+}
+
+type xmlTop10 struct {
+	Top       *bool    `xml:"top,attr"`
+	Percent   bool     `xml:"percent,attr"`
+	Val       float64  `xml:"val,attr"`
+	FilterVal *float64 `xml:"filterVal,attr"`
+}
+
+type xmlCustomFilters struct {
+	And          bool `xml:"and,attr"`
+	CustomFilter []struct {
+		Operator string `xml:"operator,attr"`
+		Val      string `xml:"val,attr"`
+	} `xml:"customFilter"`
+}
+
+type xmlSortState struct {
+	Ref           string `xml:"ref,attr"`
+	CaseSensitive bool   `xml:"caseSensitive,attr"`
+	SortCondition []struct {
+		Ref        string `xml:"ref,attr"`
+		Descending bool   `xml:"descending,attr"`
+		SortBy     string `xml:"sortBy,attr"`
+		CustomList string `xml:"customList,attr"`
+	} `xml:"sortCondition"`
+}
+
+func (autoFilter *xmlAutoFilter) autoFilter() *TAutoFilter {
+	res := &TAutoFilter{Ref: autoFilter.Ref, Columns: []TAutoFilterColumn{}}
+	for _, filterColumn := range autoFilter.FilterColumn {
+		column := TAutoFilterColumn{ColumnId: filterColumn.ColId}
+		switch {
+		case nil != filterColumn.Filters:
+			column.Blank = filterColumn.Filters.Blank
+			column.Values = make([]string, len(filterColumn.Filters.Filter))
+			for i, filter := range filterColumn.Filters.Filter {
+				column.Values[i] = filter.Val
+			}
+		case nil != filterColumn.Top10:
+			column.Top10 = &TTop10Filter{
+				Top:       nil == filterColumn.Top10.Top || *filterColumn.Top10.Top,
+				Percent:   filterColumn.Top10.Percent,
+				Val:       filterColumn.Top10.Val,
+				FilterVal: filterColumn.Top10.FilterVal,
+			}
+		case nil != filterColumn.CustomFilters:
+			column.Custom = &TCustomFilters{And: filterColumn.CustomFilters.And, Filters: []TCustomFilter{}}
+			for _, filter := range filterColumn.CustomFilters.CustomFilter {
+				column.Custom.Filters = append(column.Custom.Filters, TCustomFilter{Operator: filter.Operator, Val: filter.Val})
+			}
+		case nil != filterColumn.DynamicFilter:
+			column.Dynamic = filterColumn.DynamicFilter.Type
+		default:
+			// color and icon filters
+			continue
+		}
+		res.Columns = append(res.Columns, column)
+	}
+	if nil != autoFilter.SortState {
+		res.SortState = autoFilter.SortState.sortState()
+	}
+	return res
+}
+
+func (sortState *xmlSortState) sortState() *TSortState {
+	res := &TSortState{Ref: sortState.Ref, CaseSensitive: sortState.CaseSensitive, Conditions: []TSortCondition{}}
+	for _, condition := range sortState.SortCondition {
+		sortBy := condition.SortBy
+		if "" == sortBy {
+			sortBy = "value"
+		}
+		res.Conditions = append(res.Conditions, TSortCondition{Ref: condition.Ref, Descending: condition.Descending, SortBy: sortBy, CustomList: condition.CustomList})
+	}
+	return res
+}
+
+func (xlsx *xlsxStream) GetAutoFilter(sheetId int) (error, *TAutoFilter) {
+	if sheetId < 0 || sheetId >= len(xlsx.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	err, meta := xlsx.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.autoFilter
+}
+
+// SetAutoFilterEvaluation makes Scan() skip rows failing value, top10 and custom criteria regardless of stored hidden flags
+func (xlsx *xlsxStream) SetAutoFilterEvaluation(enabled bool) error {
+	xlsx.autoFilterEvaluation = enabled
+	return nil
+}
+
+// isRowAutoFiltered tells if Scan() have to skip row by autofilter evaluation
+func (xlsx *xlsxStream) isRowAutoFiltered() (error, bool) {
+	if !xlsx.autoFilterEvaluation {
+		return nil, false
+	}
+	err, meta := xlsx.requireSheetMeta(xlsx.iteratorSheetId)
+	if nil != err || nil == meta.autoFilter || "" == meta.autoFilter.Ref {
+		return err, false
+	}
+	if nil == meta.autoFilterThresholds {
+		err = xlsx.collectAutoFilterThresholds(meta)
+		if nil != err {
+			return err, false
+		}
+	}
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		// implied empty row
+		return nil, !meta.autoFilter.rowMatches(xlsx.iteratorRowNum, nil, nil, meta.autoFilterThresholds)
+	}
+	return nil, !meta.autoFilter.rowMatches(xlsx.iteratorRowNum, xlsx.iteratorScannedData, xlsx.iteratorScannedNumbers, meta.autoFilterThresholds)
+}
+
+// collectAutoFilterThresholds computes top10 thresholds which excel has not stored, numeric cells of filter range
+// are read by separate pass
+func (xlsx *xlsxStream) collectAutoFilterThresholds(meta *xlsxSheetMeta) error {
+	meta.autoFilterThresholds = make(map[int]float64)
+	numbers := make(map[int][]float64) // column x to numeric values
+	for _, column := range meta.autoFilter.Columns {
+		if nil != column.Top10 && nil == column.Top10.FilterVal {
+			numbers[column.ColumnId] = []float64{}
+		}
+	}
+	err, x1, y1, _, y2 := extractCellRangeCoords(meta.autoFilter.Ref)
+	if nil != err {
+		return err
+	}
+	if len(numbers) > 0 {
+		sheet := xlsx.sheets[xlsx.iteratorSheetId]
+		z, err := xlsx.findZipHandler(sheet.path)
+		if nil != err {
+			return fmt.Errorf("sheet #%d not found: %s", xlsx.iteratorSheetId, err)
+		}
+		rc, err := z.Open()
+		if err != nil {
+			return fmt.Errorf("file stream [%s] Open() failed: %s", sheet.path, err.Error())
+		}
+		defer nowarnCloseCloser(rc)
+		decoder := xml.NewDecoder(rc)
+		for {
+			tok, tokenErr := decoder.Token()
+			if io.EOF == tokenErr {
+				break
+			}
+			if tokenErr != nil {
+				return fmt.Errorf("xml token read error in [%s] at pos %d: %s", sheet.path, decoder.InputOffset(), tokenErr.Error())
+			}
+			startTok, ok := tok.(xml.StartElement)
+			if !ok || startTok.Name.Local != "c" {
+				continue
+			}
+			cellType, _ := findXmlTokenAttrValue(&startTok, "t")
+			coords, _ := findXmlTokenAttrValue(&startTok, "r")
+			err, x, y := extractCellCoords(coords)
+			if nil != err || y <= y1 || y > y2 || ("" != cellType && "n" != cellType) {
+				_ = decoder.Skip()
+				continue
+			}
+			if _, ok := numbers[x-x1]; !ok {
+				_ = decoder.Skip()
+				continue
+			}
+			cell := &struct {
+				V string `xml:"v"`
+			}{}
+			if nil != decoder.DecodeElement(cell, &startTok) {
+				continue
+			}
+			if number, err := strconv.ParseFloat(cell.V, 64); nil == err {
+				numbers[x-x1] = append(numbers[x-x1], number)
+			}
+		}
+	}
+	for _, column := range meta.autoFilter.Columns {
+		if nil == column.Top10 {
+			continue
+		}
+		if threshold, ok := column.Top10.threshold(numbers[column.ColumnId]); ok {
+			meta.autoFilterThresholds[column.ColumnId] = threshold
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package tablescanner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	relationTypeComments         = "comments"
+	relationTypeThreadedComments = "threadedcomment"
+)
+
+type xmlComments struct {
+	Authors     []string     `xml:"authors>author"`
+	CommentList []xmlComment `xml:"commentList>comment"`
+}
+
+type xmlComment struct {
+	Ref      string      `xml:"ref,attr"`
+	AuthorId int         `xml:"authorId,attr"`
+	Text     xmlRichText `xml:"text"`
+}
+
+// xmlRichText is <si>-like string item, phonetic <rPh> runs are not mapped and therefore skipped
+type xmlRichText struct {
+	T string       `xml:"t"`
+	R []xmlRichRun `xml:"r"`
+}
+
+type xmlRichRun struct {
+	RPr *xmlRunProperties `xml:"rPr"`
+	T   string            `xml:"t"`
+}
+
+type xmlThreadedComments struct {
+	ThreadedComment []xmlThreadedComment `xml:"threadedComment"`
+}
+
+type xmlThreadedComment struct {
+	Ref      string `xml:"ref,attr"`
+	Id       string `xml:"id,attr"`
+	ParentId string `xml:"parentId,attr"`
+	PersonId string `xml:"personId,attr"`
+	Text     string `xml:"text"`
+}
+
+type xmlPersonList struct {
+	Person []xmlPerson `xml:"person"`
+}
+
+type xmlPerson struct {
+	Id          string `xml:"id,attr"`
+	DisplayName string `xml:"displayName,attr"`
+}
+
+func (text *xmlRichText) String() string {
+	res := text.T
+	for _, run := range text.R {
+		res += run.T
+	}
+	return res
+}
+
+// GetComments reads legacy notes and threaded comments, threaded ones take precedence
+// because excel duplicates them into legacy part with "[Threaded comment]" placeholder text
+func (xlsx *xlsxStream) GetComments(sheetId int) (error, map[string]TCellComment) {
+	if sheetId < 0 || sheetId >= len(xlsx.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	res := make(map[string]TCellComment)
+	sheet := xlsx.sheets[sheetId]
+	for _, relation := range sheet.relations {
+		if strings.ToLower(path.Base(relation.Type)) != relationTypeComments {
+			continue
+		}
+		comments := &xmlComments{}
+		err := xlsx.decodeZipXML(relation.Target, comments)
+		if nil != err {
+			return err, nil
+		}
+		for _, comment := range comments.CommentList {
+			ref := strings.ToUpper(comment.Ref)
+			author := ""
+			if comment.AuthorId >= 0 && comment.AuthorId < len(comments.Authors) {
+				author = comments.Authors[comment.AuthorId]
+			}
+			res[ref] = TCellComment{Ref: ref, Author: author, Text: comment.Text.String()}
+		}
+	}
+	for _, relation := range sheet.relations {
+		if strings.ToLower(path.Base(relation.Type)) != relationTypeThreadedComments {
+			continue
+		}
+		err, persons := xlsx.readPersons()
+		if nil != err {
+			return err, nil
+		}
+		threaded := &xmlThreadedComments{}
+		err = xlsx.decodeZipXML(relation.Target, threaded)
+		if nil != err {
+			return err, nil
+		}
+		threadRefs := make(map[string]string) // root comment id to cell ref
+		for _, comment := range threaded.ThreadedComment {
+			ref := strings.ToUpper(comment.Ref)
+			item := TCellComment{Ref: ref, Author: persons[comment.PersonId], Text: comment.Text}
+			if rootRef, isReply := threadRefs[comment.ParentId]; isReply && "" != comment.ParentId {
+				root := res[rootRef]
+				root.Replies = append(root.Replies, item)
+				res[rootRef] = root
+			} else {
+				threadRefs[comment.Id] = ref
+				res[ref] = item
+			}
+		}
+	}
+	return nil, res
+}
+
+// persons part is optional, unknown authors are left empty
+func (xlsx *xlsxStream) readPersons() (error, map[string]string) {
+	res := make(map[string]string)
+	if "" == xlsx.zPathPersons {
+		return nil, res
+	}
+	if _, err := xlsx.findZipHandler(xlsx.zPathPersons); nil != err {
+		return nil, res
+	}
+	persons := &xmlPersonList{}
+	err := xlsx.decodeZipXML(xlsx.zPathPersons, persons)
+	if nil != err {
+		return err, nil
+	}
+	for _, person := range persons.Person {
+		res[person.Id] = person.DisplayName
+	}
+	return nil, res
+}
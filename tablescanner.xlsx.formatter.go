@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +23,8 @@ const (
 	strCellTypeNumericAlt    = ""
 	maxNonScientificNumber   = 1e11
 	minNonScientificNumber   = 1e-9
+	excelSignificantDigits   = 15 // precision of numbers shown by excel
+	excelGeneralWidth        = 11 // characters of General formatted number fitting standard column width
 )
 
 type excelFormatter struct {
@@ -33,8 +36,42 @@ type excelFormatter struct {
 	thousandSeparator string
 	trim              bool
 	date1904          bool
+	dateFormats       map[int]*parsedNumberFormat // built-in formats of date cells styled as general, keyed by numFmtId
+	location          *time.Location              // zone of typed date values, serials have no zone so wall clock is kept
+	lotusCompatible   bool                        // serials below 61 count phantom 1900-02-29 like excel displays them
+	boolRendering     TBoolRendering
+	boolRenderingSet  bool // SpreadsheetML keeps stored "1"/"0" of bool cells until rendering is selected
+	errorRendering    TErrorRendering
+	generalFormatMode TGeneralFormatMode
 }
 
+// TGeneralFormatMode selects rendering of numbers formatted as General
+type TGeneralFormatMode int
+
+const (
+	GeneralFormatExact   TGeneralFormatMode = iota // shortest string parsed back to the same float64, like 0.30000000000000004
+	GeneralFormatExcel                             // 15 significant digits fitted into 11 characters like excel shows, like 0.3 or 1.23457E+11
+	GeneralFormatDecimal                           // 15 significant digits in plain decimal notation, like 0.3 or 123456789012
+)
+
+// TBoolRendering selects text of bool cells
+type TBoolRendering int
+
+const (
+	BoolRenderingExcel   TBoolRendering = iota // "TRUE"/"FALSE"
+	BoolRenderingLocale                        // names of i18n, like "ИСТИНА"/"ЛОЖЬ" for "ru"
+	BoolRenderingNumeric                       // "1"/"0"
+)
+
+// TErrorRendering selects text of error cells, typed errors are returned by GetScannedErrors() anyway
+type TErrorRendering int
+
+const (
+	ErrorRenderingText   TErrorRendering = iota // stored code like "#N/A"
+	ErrorRenderingLocale                        // code of i18n, like "#Н/Д" for "ru"
+	ErrorRenderingEmpty                         // empty string
+)
+
 type parsedNumberFormat struct {
 	numFmt                        string
 	isTimeFormat                  bool
@@ -80,6 +117,23 @@ func (formatter *excelFormatter) setI18n(i18n *tI18n) {
 	formatter.i18n = i18n
 	formatter.decimalSeparator = i18n.decimalSeparator
 	formatter.thousandSeparator = i18n.thousandSeparator
+	formatter.dateFormats = make(map[int]*parsedNumberFormat)
+}
+
+// defaultDateFormat returns locale date (14), date-time (22) or time (21) built-in format
+func (formatter *excelFormatter) defaultDateFormat(hasDate bool, hasTime bool) *parsedNumberFormat {
+	numFmtId := 14
+	if hasTime {
+		numFmtId = 21
+		if hasDate {
+			numFmtId = 22
+		}
+	}
+	if parsedFormat, ok := formatter.dateFormats[numFmtId]; ok {
+		return parsedFormat
+	}
+	formatter.dateFormats[numFmtId] = parseNumFmt(formatter.i18n.numFmtDefaults[numFmtId])
+	return formatter.dateFormats[numFmtId]
 }
 
 func (formatter *excelFormatter) DisableFormatting() {
@@ -100,6 +154,32 @@ func (formatter *excelFormatter) SetTrimOn() {
 func (formatter *excelFormatter) SetTrimOff() {
 	formatter.trim = false
 }
+
+// SetLocation sets zone of DateValue() results, wall clock of serial is kept, nil means UTC
+func (formatter *excelFormatter) SetLocation(location *time.Location) {
+	formatter.location = location
+}
+
+// SetLotusCompatibility makes serials 1..59 mean 1900-01-01..1900-02-28 and serial 60 mean phantom 1900-02-29
+// like excel shows them, by default days are counted from 1899-12-30 continuously
+func (formatter *excelFormatter) SetLotusCompatibility(enabled bool) {
+	formatter.lotusCompatible = enabled
+}
+
+// SetGeneralFormatMode selects rendering of General formatted numbers, AllowScientific() affects exact mode only
+func (formatter *excelFormatter) SetGeneralFormatMode(mode TGeneralFormatMode) {
+	formatter.generalFormatMode = mode
+}
+
+func (formatter *excelFormatter) SetBoolRendering(rendering TBoolRendering) {
+	formatter.boolRendering = rendering
+	formatter.boolRenderingSet = true
+}
+
+func (formatter *excelFormatter) SetErrorRendering(rendering TErrorRendering) {
+	formatter.errorRendering = rendering
+}
+
 func (formatter *excelFormatter) SetDateFixedFormat(value string) {
 	formatter.dateFixedFormat = value
 }
@@ -359,22 +439,20 @@ func (formatter *excelFormatter) FormatValue(cellValue string, cellType string,
 }
 
 func (formatter *excelFormatter) internalFormatValue(cellValue string, cellType string, fullFormat *parsedNumberFormat) (string, error) {
+	if strCellTypeError == cellType {
+		// The error type is what XLSX uses in error cases such as when formulas are invalid.
+		// There will be text in the cell's value that can be shown, something ugly like #NAME? or #######
+		return formatter.formatError(cellValue), nil
+	}
 	if formatter.discardFormatting {
 		return cellValue, nil
 	}
 	switch cellType {
-	case strCellTypeError:
-		// The error type is what XLSX uses in error cases such as when formulas are invalid.
-		// There will be text in the cell's value that can be shown, something ugly like #NAME? or #######
-		return cellValue, nil
 	case strCellTypeBool:
-		if cellValue == "0" {
-			return "FALSE", nil
-		} else if cellValue == "1" {
-			return "TRUE", nil
-		} else {
+		if cellValue != "0" && cellValue != "1" {
 			return cellValue, errors.New("invalid value in bool cell")
 		}
+		return formatter.formatBool("1" == cellValue), nil
 	case strCellTypeString:
 		fallthrough
 	case strCellTypeInline:
@@ -397,9 +475,16 @@ func (formatter *excelFormatter) internalFormatValue(cellValue string, cellType
 			return cellValue, errors.New("invalid or unsupported format, unsupported string format")
 		}
 	case strCellTypeDate:
-		// These are dates that are stored in date format instead of being stored as numbers with a format to turn them
-		// into a date string.
-		return cellValue, nil
+		// These are dates that are stored in ISO 8601 format instead of being stored as numbers with a format to turn
+		// them into a date string. They are turned into serials, general format is replaced by locale date format.
+		val, hasDate, hasTime, ok := parseISO8601(cellValue)
+		if !ok {
+			return cellValue, errors.New("invalid ISO 8601 value in date cell")
+		}
+		if !fullFormat.isTimeFormat {
+			fullFormat = formatter.defaultDateFormat(hasDate, hasTime)
+		}
+		return formatter.formatTime(val, fullFormat), nil
 	case strCellTypeNumeric:
 		fallthrough
 	case strCellTypeNumericAlt:
@@ -409,6 +494,36 @@ func (formatter *excelFormatter) internalFormatValue(cellValue string, cellType
 	}
 }
 
+func (formatter *excelFormatter) formatBool(value bool) string {
+	id := 0
+	if value {
+		id = 1
+	}
+	switch formatter.boolRendering {
+	case BoolRenderingLocale:
+		if nil != formatter.i18n && "" != formatter.i18n.boolNames[id] {
+			return formatter.i18n.boolNames[id]
+		}
+	case BoolRenderingNumeric:
+		return strconv.Itoa(id)
+	}
+	return numFmtI18n["en"].boolNames[id]
+}
+
+func (formatter *excelFormatter) formatError(code string) string {
+	switch formatter.errorRendering {
+	case ErrorRenderingLocale:
+		if nil != formatter.i18n {
+			if name, found := formatter.i18n.errorNames[code]; found {
+				return name
+			}
+		}
+	case ErrorRenderingEmpty:
+		return ""
+	}
+	return code
+}
+
 func (formatter *excelFormatter) formatNumericCell(cellValue string, fullFormat *parsedNumberFormat) (string, error) {
 	rawValue := strings.TrimSpace(cellValue)
 	// If there wasn't a value in the cell, it shouldn't have been marked as Numeric.
@@ -552,6 +667,12 @@ func (formatter *excelFormatter) generalNumericScientific(value string) (string,
 	if err != nil {
 		return value, err
 	}
+	switch formatter.generalFormatMode {
+	case GeneralFormatExcel:
+		return generalExcelNumeric(f), nil
+	case GeneralFormatDecimal:
+		return strconv.FormatFloat(roundSignificant(f, excelSignificantDigits), 'f', -1, 64), nil
+	}
 	if formatter.allowScientific {
 		absF := math.Abs(f)
 		// When using General format, numbers that are less than 1e-9 (0.000000001) and greater than or equal to
@@ -569,12 +690,103 @@ func (formatter *excelFormatter) generalNumericScientific(value string) (string,
 	return strconv.FormatFloat(f, 'f', -1, 64), nil
 }
 
+// roundSignificant rounds to given significant digits, the shortest representation of result has no more digits
+func roundSignificant(f float64, digits int) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', digits, 64), 64)
+	if nil != err {
+		return f // the largest numbers overflow
+	}
+	if 0 == rounded {
+		return 0 // negative zero is shown as 0
+	}
+	return rounded
+}
+
+// generalExcelNumeric emulates General format of standard column width: number is rounded to 15 significant digits,
+// then to fit 11 characters (negative sign is not counted), scientific notation is used for numbers which
+// cannot keep enough digits
+func generalExcelNumeric(f float64) string {
+	f = roundSignificant(f, excelSignificantDigits)
+	if 0 == f || math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	width := excelGeneralWidth
+	if f < 0 {
+		width++
+	}
+	exponent := int(math.Floor(math.Log10(math.Abs(f))))
+	switch {
+	case exponent >= -4 && exponent <= -1:
+		return trimFractionZeros(formatFixedHalfUp(f, excelGeneralWidth-2))
+	case exponent >= -9 && exponent < excelGeneralWidth-1:
+		if result := trimFractionZeros(formatFixedHalfUp(f, excelGeneralWidth+1)); len(result) <= width {
+			return result
+		}
+		if result := trimFractionZeros(formatFixedHalfUp(f, excelGeneralWidth-2-exponent)); exponent >= 0 && len(result) <= width {
+			return result
+		}
+	case exponent == excelGeneralWidth-1:
+		// rounding may carry to one more digit like 99999999999.5, such numbers are scientific
+		if result := formatFixedHalfUp(f, 0); len(result) <= width {
+			return result
+		}
+	}
+	exact := decimalRat(f)
+	mantissa := new(big.Rat).Mul(exact, pow10Rat(-exponent)).FloatString(5)
+	if strings.HasPrefix(strings.TrimPrefix(mantissa, "-"), "10") { // 9.999995 is rounded up
+		exponent++
+		mantissa = new(big.Rat).Mul(exact, pow10Rat(-exponent)).FloatString(5)
+	}
+	sign := "+"
+	if exponent < 0 {
+		sign = "-"
+		exponent = -exponent
+	}
+	return fmt.Sprintf("%sE%s%02d", trimFractionZeros(mantissa), sign, exponent)
+}
+
+// formatFixedHalfUp rounds shortest decimal representation of number half away from zero like excel does,
+// binary value would round 1234567890.5 to even
+func formatFixedHalfUp(f float64, decimals int) string {
+	return decimalRat(f).FloatString(decimals)
+}
+
+// decimalRat returns shortest decimal representation of finite number as exact fraction
+func decimalRat(f float64) *big.Rat {
+	exact, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return exact
+}
+
+func pow10Rat(exponent int) *big.Rat {
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exponent)), nil))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+}
+
+// trimFractionZeros removes trailing zeros of fraction part and lonely decimal point
+func trimFractionZeros(value string) string {
+	if strings.IndexByte(value, '.') < 0 {
+		return value
+	}
+	return strings.TrimSuffix(strings.TrimRight(value, "0"), ".")
+}
+
 func (formatter *excelFormatter) parseTime(value string, fullFormat *parsedNumberFormat) (string, error) {
 	f, err := strconv.ParseFloat(value, 64)
 	if err != nil {
 		return value, err
 	}
 	val := TimeFromExcelTime(f, formatter.date1904)
+	if formatter.lotusCompatible && !formatter.date1904 && f < 61 {
+		// phantom 1900-02-29 is shown as 1900-03-01
+		date, nanoseconds := formatter.civilDateFromExcelTime(f)
+		val = time.Date(date.Year, date.Month, date.Day, 0, 0, 0, int(nanoseconds), time.UTC)
+	}
+	return formatter.formatTime(val, fullFormat), nil
+}
+
+func (formatter *excelFormatter) formatTime(val time.Time, fullFormat *parsedNumberFormat) string {
 	format := fullFormat.numFmt
 	if formatter.dateFixedFormat != "" {
 		format = formatter.dateFixedFormat
@@ -616,6 +828,10 @@ func (formatter *excelFormatter) parseTime(value string, fullFormat *parsedNumbe
 		//{"&&&", "Mon", 1},
 		{"\\ ", " ", -1},
 		{"\\,", ",", -1},
+		{"\\-", "-", -1},
+		{"\\/", "/", -1},
+		{"\\.", ".", -1},
+		{"\\:", ":", -1},
 	}
 	// It is the presence of the "am/pm" indicator that determins
 	// if this is a 12 hour or 24 hours time format, not the
@@ -655,7 +871,7 @@ func (formatter *excelFormatter) parseTime(value string, fullFormat *parsedNumbe
 	} {
 		format = strings.Replace(format, repl.macro, repl.value, -1)
 	}
-	return val.Format(format), nil
+	return val.Format(format)
 }
 
 // isTimeFormat checks whether an Excel format string represents a time.Time.
@@ -827,3 +1043,116 @@ func TimeFromExcelTime(excelTime float64, date1904 bool) time.Time {
 	durationPart := time.Duration(nanosInADay * floatPart)
 	return date.AddDate(0, 0, wholeDaysPart).Add(durationPart)
 }
+
+// parseISO8601 parses date, date-time or time, wall clock is kept when value has time zone offset,
+// time without date is placed on 1899-12-30
+func parseISO8601(value string) (val time.Time, hasDate bool, hasTime bool, ok bool) {
+	value = strings.TrimSpace(value)
+	dateLayouts := []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999", "2006-01-02T15:04Z07:00", "2006-01-02T15:04", "2006-01-02"}
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, value)
+		if nil == err {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), true, "2006-01-02" != layout, true
+		}
+	}
+	for _, layout := range []string{"15:04:05.999999999", "15:04"} {
+		t, err := time.Parse(layout, strings.TrimPrefix(value, "T"))
+		if nil == err {
+			return excel1900Epoc.Add(t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))), false, true, true
+		}
+	}
+	return time.Time{}, false, false, false
+}
+
+// ExcelTimeFromISO8601 converts ISO 8601 date, date-time or time (like "2024-03-01", "2024-03-01T10:30:00Z" or "10:30:00")
+// to excel serial, wall clock is kept when value has time zone offset
+func ExcelTimeFromISO8601(value string, date1904 bool) (excelTime float64, hasDate bool, hasTime bool, ok bool) {
+	val, hasDate, hasTime, ok := parseISO8601(value)
+	if !ok {
+		return 0, false, false, false
+	}
+	if !hasDate {
+		return float64(val.Sub(excel1900Epoc)) / nanosInADay, false, true, true
+	}
+	epoch := excel1900Epoc
+	if date1904 {
+		epoch = excel1904Epoc
+	}
+	excelTime = float64(val.Sub(epoch)) / nanosInADay
+	if !date1904 && excelTime < 61 {
+		// serials before 1900-03-01 are shifted by phantom 1900-02-29
+		excelTime--
+	}
+	return excelTime, true, hasTime, true
+}
+
+// TCivilDate is calendar date without time zone, Day may be 29 for phantom 1900-02-29 of lotus-compatible serial 60
+type TCivilDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func (date TCivilDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year, int(date.Month), date.Day)
+}
+
+// civilDateFromExcelTime splits serial into calendar date and nanoseconds of day rounded to milliseconds
+func (formatter *excelFormatter) civilDateFromExcelTime(excelTime float64) (TCivilDate, int64) {
+	days := math.Floor(excelTime)
+	milliseconds := int64(math.Round((excelTime - days) * 86400000))
+	if milliseconds >= 86400000 {
+		days++
+		milliseconds -= 86400000
+	}
+	epoch := excel1900Epoc
+	switch {
+	case formatter.date1904:
+		epoch = excel1904Epoc
+	case formatter.lotusCompatible && 60 == days:
+		return TCivilDate{Year: 1900, Month: time.February, Day: 29}, milliseconds * int64(time.Millisecond)
+	case formatter.lotusCompatible && days < 60:
+		epoch = epoch.AddDate(0, 0, 1)
+	}
+	date := epoch.AddDate(0, 0, int(days))
+	return TCivilDate{Year: date.Year(), Month: date.Month(), Day: date.Day()}, milliseconds * int64(time.Millisecond)
+}
+
+// DateValue converts numeric serial or ISO 8601 date cell to time placed in location set by SetLocation()
+func (formatter *excelFormatter) DateValue(cellValue string, cellType string) (time.Time, error) {
+	err, date, nanoseconds := formatter.parseDateValue(cellValue, cellType)
+	if nil != err {
+		return time.Time{}, err
+	}
+	location := formatter.location
+	if nil == location {
+		location = time.UTC
+	}
+	return time.Date(date.Year, date.Month, date.Day, 0, 0, 0, int(nanoseconds), location), nil
+}
+
+// CivilDateValue converts numeric serial or ISO 8601 date cell to calendar date, time of day is dropped
+func (formatter *excelFormatter) CivilDateValue(cellValue string, cellType string) (TCivilDate, error) {
+	err, date, _ := formatter.parseDateValue(cellValue, cellType)
+	return date, err
+}
+
+// parseDateValue splits numeric serial or ISO 8601 date cell into calendar date and nanoseconds of day
+func (formatter *excelFormatter) parseDateValue(cellValue string, cellType string) (error, TCivilDate, int64) {
+	switch cellType {
+	case strCellTypeDate:
+		val, _, _, ok := parseISO8601(cellValue)
+		if !ok {
+			return errors.New("invalid ISO 8601 value in date cell"), TCivilDate{}, 0
+		}
+		return nil, TCivilDate{Year: val.Year(), Month: val.Month(), Day: val.Day()}, int64(val.Sub(time.Date(val.Year(), val.Month(), val.Day(), 0, 0, 0, 0, time.UTC)))
+	case strCellTypeNumeric, strCellTypeNumericAlt:
+		excelTime, err := strconv.ParseFloat(strings.TrimSpace(cellValue), 64)
+		if nil != err {
+			return err, TCivilDate{}, 0
+		}
+		date, nanoseconds := formatter.civilDateFromExcelTime(excelTime)
+		return nil, date, nanoseconds
+	}
+	return fmt.Errorf("cell type [%s] is not a date", cellType), TCivilDate{}, 0
+}
@@ -0,0 +1,34 @@
+package tablescanner
+
+import "testing"
+
+func TestGeneralExcelNumeric(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0"},
+		{0.1, "0.1"},
+		{0.0001, "0.0001"},
+		{0.00001, "0.00001"},
+		{1e-10, "1E-10"},
+		{123456789.123, "123456789.1"},
+		{1234567890.12, "1234567890"},
+		{9999999999, "9999999999"},
+		{9999999999.5, "10000000000"},
+		{1e10, "10000000000"},
+		{12345678901.5, "12345678902"},
+		{99999999999, "99999999999"},
+		{99999999999.4, "99999999999"},
+		{99999999999.5, "1E+11"},
+		{-99999999999.4, "-99999999999"},
+		{-99999999999.5, "-1E+11"},
+		{1e11, "1E+11"},
+		{123456789012, "1.23457E+11"},
+	}
+	for _, test := range tests {
+		if result := generalExcelNumeric(test.value); test.expected != result {
+			t.Errorf("generalExcelNumeric(%v) = %q, expected %q", test.value, result, test.expected)
+		}
+	}
+}
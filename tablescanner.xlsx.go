@@ -5,7 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
 )
@@ -15,33 +15,51 @@ type xlsxTableSheetInfo struct {
 	HideLevel TSheetHideLevel
 	path      string
 	rId       string
+	relations map[string]xmlWorkbookRelation // sheet-relation-id to relation with target resolved to zip path
+	tables    []*xlsxTableInfo
+	meta      *xlsxSheetMeta // parts placed outside of <sheetData>, nil until requested
+	columns   []TColumnInfo  // <cols> content, nil until sheet scanning or meta reading
 }
 
 type xlsxStream struct {
-	formatter              excelFormatter
-	i18n                   *tI18n   // reference to selected i18n config
-	fmtI18n                []string // excel built-in number formats depending on system locale
-	sheets                 []*xlsxTableSheetInfo
-	sheetSelected          int                  // default-opening sheet id
-	iteratorLastError      error                // error which caused last Scan() failed
-	iteratorRowNum         int                  // row number that Scan() implies
-	iteratorScannedRowNum  int                  // current row number fetched by reading, starting with 1
-	iteratorScannedData    []string             // current row-iterating row data
-	iteratorSheetId        int                  // current row-iterating sheet id
-	iteratorStream         io.ReadCloser        // current row-iterating xml stream
-	iteratorDecoder        *xml.Decoder         // statefull decoder object for iterator
-	iteratorXMLSegment     tIteratorXMLSegment  // current decoder xml tree location
-	iteratorCapacity       int                  // default result slice capacity, synchronizes while Scan()
-	zFileName              string               // original filename
-	zPathSharedStrings     string               // sharedStrings.xml path from *.rels file
-	zPathStyles            string               // styles.xml path from *.rels file
-	z                      *zip.ReadCloser      // root zip handler
-	zFiles                 map[string]*zip.File // key=zipPath
-	relations              map[string]string    // workbook-relation-id to path
-	referenceTable         []string             // sharedStrings
-	numFmtCustom           []string
-	style2numFmtId         []int
-	styleNumberFormatCache []*parsedNumberFormat // style-id to parsedNumberFormat
+	formatter               excelFormatter
+	i18n                    *tI18n   // reference to selected i18n config
+	fmtI18n                 []string // excel built-in number formats depending on system locale
+	sheets                  []*xlsxTableSheetInfo
+	sheetSelected           int                    // default-opening sheet id
+	iteratorLastError       error                  // error which caused last Scan() failed
+	iteratorRowNum          int                    // row number that Scan() implies
+	iteratorScannedRowNum   int                    // current row number fetched by reading, starting with 1
+	iteratorScannedData     []string               // current row-iterating row data
+	iteratorScannedRichText [][]TRichTextRun       // current row-iterating row formatted runs, when richText is on
+	iteratorScannedStyles   []int                  // current row-iterating row style ids
+	iteratorScannedErrors   []*TCellError          // current row-iterating row error cells
+	iteratorScannedDecimals []TDecimal             // current row-iterating row raw numeric values
+	iteratorScannedRowInfo  TRowInfo               // current row-iterating row visibility
+	visibilityFilter        TVisibilityFilter      // rows and columns to be skipped while scanning
+	autoFilterEvaluation    bool                   // skip rows failing autofilter criteria while scanning
+	iteratorScannedNumbers  []string               // current row-iterating row raw numeric values, when autoFilterEvaluation is on
+	iteratorSheetId         int                    // current row-iterating sheet id
+	iteratorStream          io.ReadCloser          // current row-iterating xml stream
+	iteratorDecoder         *xml.Decoder           // statefull decoder object for iterator
+	iteratorXMLSegment      tIteratorXMLSegment    // current decoder xml tree location
+	iteratorCapacity        int                    // default result slice capacity, synchronizes while Scan()
+	zFileName               string                 // original filename
+	zPathSharedStrings      string                 // sharedStrings.xml path from *.rels file
+	zPathStyles             string                 // styles.xml path from *.rels file
+	zPathPersons            string                 // persons.xml path from *.rels file (threaded comment authors)
+	z                       *zip.Reader            // root zip handler
+	zCloser                 io.Closer              // nil for packages kept in memory
+	zFiles                  map[string]*zip.File   // key=zipPath
+	relations               map[string]string      // workbook-relation-id to path
+	referenceTable          []string               // sharedStrings
+	referenceRichTable      map[int][]TRichTextRun // sharedStrings formatted runs, filled for rich items only when richText is on
+	richText                bool                   // collect formatted runs of rich text cells
+	phonetic                bool                   // include phonetic <rPh> runs into cell text
+	numFmtCustom            []string
+	style2numFmtId          []int
+	styleNumberFormatCache  []*parsedNumberFormat // style-id to parsedNumberFormat
+	cellStyles              []TCellStyle          // style-id to resolved cellXfs entry, number format is filled on request
 }
 
 type tIteratorXMLSegment byte
@@ -84,10 +102,31 @@ type xmlSheets struct {
 }
 
 type xmlSheet struct {
-	Name    string `xml:"name,attr,omitempty"`
-	SheetId string `xml:"sheetId,attr,omitempty"`
-	Id      string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr,omitempty"`
-	State   string `xml:"state,attr,omitempty"`
+	Name     string `xml:"name,attr,omitempty"`
+	SheetId  string `xml:"sheetId,attr,omitempty"`
+	Id       string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr,omitempty"`
+	StrictId string `xml:"http://purl.oclc.org/ooxml/officeDocument/relationships id,attr,omitempty"`
+	State    string `xml:"state,attr,omitempty"`
+}
+
+// relationId returns relation id of transitional or strict (ISO/IEC 29500 Strict) namespace
+func (sheet *xmlSheet) relationId() string {
+	if "" == sheet.Id {
+		return sheet.StrictId
+	}
+	return sheet.Id
+}
+
+type xmlCols struct {
+	Col []xmlCol `xml:"col"`
+}
+
+type xmlCol struct {
+	Min          int  `xml:"min,attr"`
+	Max          int  `xml:"max,attr"`
+	Hidden       bool `xml:"hidden,attr"`
+	OutlineLevel int  `xml:"outlineLevel,attr"`
+	Collapsed    bool `xml:"collapsed,attr"`
 }
 
 type xmlWorkbookRels struct {
@@ -95,14 +134,19 @@ type xmlWorkbookRels struct {
 }
 
 type xmlWorkbookRelation struct {
-	Id     string `xml:",attr"`
-	Target string `xml:",attr"`
-	Type   string `xml:",attr"`
+	Id         string `xml:",attr"`
+	Target     string `xml:",attr"`
+	Type       string `xml:",attr"`
+	TargetMode string `xml:",attr"`
 }
 
 type xmlStyleSheet struct {
-	CellXfs xmlCellXfs `xml:"cellXfs,omitempty"`
-	NumFmts xmlNumFmts `xml:"numFmts,omitempty"`
+	CellXfs      xmlCellXfs  `xml:"cellXfs,omitempty"`
+	CellStyleXfs xmlCellXfs  `xml:"cellStyleXfs,omitempty"`
+	NumFmts      xmlNumFmts  `xml:"numFmts,omitempty"`
+	Fonts        []xmlFont   `xml:"fonts>font"`
+	Fills        []xmlFill   `xml:"fills>fill"`
+	Borders      []xmlBorder `xml:"borders>border"`
 }
 
 type xmlCellXfs struct {
@@ -110,7 +154,18 @@ type xmlCellXfs struct {
 }
 
 type xmlXf struct {
-	NumFmtId int `xml:"numFmtId,attr"`
+	NumFmtId        int            `xml:"numFmtId,attr"`
+	FontId          int            `xml:"fontId,attr"`
+	FillId          int            `xml:"fillId,attr"`
+	BorderId        int            `xml:"borderId,attr"`
+	XfId            int            `xml:"xfId,attr"`
+	ApplyFont       *bool          `xml:"applyFont,attr"`
+	ApplyFill       *bool          `xml:"applyFill,attr"`
+	ApplyBorder     *bool          `xml:"applyBorder,attr"`
+	ApplyAlignment  *bool          `xml:"applyAlignment,attr"`
+	ApplyProtection *bool          `xml:"applyProtection,attr"`
+	Alignment       *xmlAlignment  `xml:"alignment"`
+	Protection      *xmlProtection `xml:"protection"`
 }
 
 type xmlNumFmts struct {
@@ -123,21 +178,26 @@ type xmlNumFmt struct {
 }
 
 func newXLSXStream(fileName string) (error, ITableDocumentScanner) {
-	var err error
-	xlsx := &xlsxStream{zFileName: fileName}
-	err = xlsx.SetI18n("en")
-	if nil != err {
+	z, err := zip.OpenReader(fileName)
+	if err != nil {
 		return err, nil
 	}
-	xlsx.z, err = zip.OpenReader(fileName)
-	if err != nil {
+	return newXLSXStreamFromZip(fileName, &z.Reader, z)
+}
+
+// newXLSXStreamFromZip reads workbook of opened package, closer is nil for packages kept in memory
+func newXLSXStreamFromZip(fileName string, z *zip.Reader, closer io.Closer) (error, ITableDocumentScanner) {
+	xlsx := &xlsxStream{zFileName: fileName, z: z, zCloser: closer}
+	err := xlsx.SetI18n("en")
+	if nil != err {
 		return err, nil
 	}
 	xlsx.zFiles = make(map[string]*zip.File, len(xlsx.z.File))
 	for _, v := range xlsx.z.File {
 		xlsx.zFiles[v.Name] = v
 	}
-	err = xlsx.getWorkbookRelations("xl/_rels/workbook.xml.rels")
+	workbookPath := xlsx.resolveWorkbookPart("xl/workbook.xml")
+	err = xlsx.getWorkbookRelations(workbookRelationsPath(workbookPath))
 	if err != nil {
 		return err, nil
 	}
@@ -149,7 +209,7 @@ func newXLSXStream(fileName string) (error, ITableDocumentScanner) {
 	if err != nil {
 		return err, nil
 	}
-	err = xlsx.readWorkbook("xl/workbook.xml")
+	err = xlsx.readWorkbook(workbookPath)
 	if err != nil {
 		return err, nil
 	}
@@ -164,8 +224,19 @@ func (sheet *xlsxTableSheetInfo) GetHideLevel() TSheetHideLevel {
 	return sheet.HideLevel
 }
 
+func (sheet *xlsxTableSheetInfo) GetTables() []ITableInfo {
+	res := make([]ITableInfo, len(sheet.tables))
+	for i, table := range sheet.tables {
+		res[i] = table
+	}
+	return res
+}
+
 func (xlsx *xlsxStream) Close() error {
-	return xlsx.z.Close()
+	if nil == xlsx.zCloser {
+		return nil
+	}
+	return xlsx.zCloser.Close()
 }
 
 func (sheet *xlsxStream) FormatterAvailable() bool {
@@ -208,15 +279,32 @@ func (xlsx *xlsxStream) findZipHandler(path string) (*zip.File, error) {
 	return nil, fmt.Errorf("cannot find required file %s", path)
 }
 
-func (xlsx *xlsxStream) getWorkbookRelations(path string) error {
-	currentWorkbookPath := filepath.FromSlash(filepath.Dir(filepath.Dir(path)))
-	defaultPathPrefix := ""
-	if len(currentWorkbookPath) > 0 {
-		defaultPathPrefix = currentWorkbookPath + "/"
+func (xlsx *xlsxStream) decodeZipXML(path string, v interface{}) error {
+	z, err := xlsx.findZipHandler(path)
+	if nil != err {
+		return err
+	}
+	rc, err := z.Open()
+	if err != nil {
+		return err
+	}
+	defer nowarnCloseCloser(rc)
+	err = xml.NewDecoder(rc).Decode(v)
+	if err != nil {
+		return fmt.Errorf("cannot decode [%s]: %s", path, err)
+	}
+	return nil
+}
+
+func (xlsx *xlsxStream) getWorkbookRelations(relsPath string) error {
+	// zip entry names always use forward slashes, workbook part at the package root has empty directory
+	workbookDir := path.Dir(path.Dir(relsPath))
+	if "." == workbookDir {
+		workbookDir = ""
 	}
 	rels := new(xmlWorkbookRels)
 	xlsx.relations = make(map[string]string)
-	z, err := xlsx.findZipHandler(path)
+	z, err := xlsx.findZipHandler(relsPath)
 	if nil != err {
 		return err
 	}
@@ -233,20 +321,60 @@ func (xlsx *xlsxStream) getWorkbookRelations(path string) error {
 	xlsx.zPathSharedStrings = "xl/sharedStrings.xml"
 	xlsx.zPathStyles = "xl/styles.xml"
 	for _, relation := range rels.Relationships {
+		if "" == relation.Target {
+			continue
+		}
 		if relation.Target[0] == '/' {
 			xlsx.relations[relation.Id] = relation.Target[1:]
 		} else {
-			xlsx.relations[relation.Id] = defaultPathPrefix + relation.Target
+			xlsx.relations[relation.Id] = path.Join(workbookDir, relation.Target)
 		}
-		switch strings.ToLower(filepath.Base(relation.Type)) {
+		switch strings.ToLower(path.Base(relation.Type)) {
 		case "styles":
 			xlsx.zPathStyles = xlsx.relations[relation.Id]
 		case "sharedstrings":
 			xlsx.zPathSharedStrings = xlsx.relations[relation.Id]
+		case "person":
+			xlsx.zPathPersons = xlsx.relations[relation.Id]
+		}
+	}
+	return nil
+}
+
+// sheet relations are optional, missing *.rels file means sheet has no tables, comments etc.
+func (xlsx *xlsxStream) readSheetRelations(sheet *xlsxTableSheetInfo) error {
+	sheet.relations = make(map[string]xmlWorkbookRelation)
+	if "" == sheet.path {
+		return nil
+	}
+	sheetDir, sheetFile := path.Split(sheet.path)
+	z, err := xlsx.findZipHandler(sheetDir + "_rels/" + sheetFile + ".rels")
+	if nil != err {
+		return nil
+	}
+	rc, err := z.Open()
+	if err != nil {
+		return err
+	}
+	defer nowarnCloseCloser(rc)
+	rels := new(xmlWorkbookRels)
+	err = xml.NewDecoder(rc).Decode(rels)
+	if err != nil {
+		return fmt.Errorf("cannot decode relations of sheet [%s]: %s", sheet.path, err)
+	}
+	for _, relation := range rels.Relationships {
+		if strings.ToLower(relation.TargetMode) != "external" && "" != relation.Target {
+			if relation.Target[0] == '/' {
+				relation.Target = relation.Target[1:]
+			} else {
+				relation.Target = path.Join(sheetDir, relation.Target)
+			}
 		}
+		sheet.relations[relation.Id] = relation
 	}
 	return nil
 }
+
 func (xlsx *xlsxStream) readWorkbook(path string) error {
 	workbook := new(xmlWorkbook)
 	z, err := xlsx.findZipHandler(path)
@@ -268,13 +396,22 @@ func (xlsx *xlsxStream) readWorkbook(path string) error {
 	xlsx.sheets = make([]*xlsxTableSheetInfo, len(workbook.Sheets.Sheet))
 	for idx, sheet := range workbook.Sheets.Sheet {
 		// undefined path isn't critical, broken sheet can be softly ignored while fetching
-		xlsx.sheets[idx] = &xlsxTableSheetInfo{Name: sheet.Name, HideLevel: TableSheetVisible, path: xlsx.relations[sheet.Id], rId: sheet.Id}
+		rId := sheet.relationId()
+		xlsx.sheets[idx] = &xlsxTableSheetInfo{Name: sheet.Name, HideLevel: TableSheetVisible, path: xlsx.relations[rId], rId: rId}
 		if sheet.State == sheetStateHidden {
 			xlsx.sheets[idx].HideLevel = TableSheetHidden
 		}
 		if sheet.State == sheetStateVeryHidden {
 			xlsx.sheets[idx].HideLevel = TableSheetVeryHidden
 		}
+		err = xlsx.readSheetRelations(xlsx.sheets[idx])
+		if nil != err {
+			return err
+		}
+		err = xlsx.readSheetTables(idx)
+		if nil != err {
+			return err
+		}
 	}
 	if len(workbook.BookViews.WorkBookView) > 0 {
 		xlsx.sheetSelected = workbook.BookViews.WorkBookView[0].ActiveTab
@@ -299,6 +436,7 @@ func (xlsx *xlsxStream) readStyles() error {
 	xlsx.numFmtCustom = make([]string, 0, 256)
 	xlsx.style2numFmtId = make([]int, 0, 32)
 	xlsx.styleNumberFormatCache = make([]*parsedNumberFormat, 0, 256)
+	xlsx.cellStyles = []TCellStyle{}
 	z, err := xlsx.findZipHandler(path)
 	if nil != err {
 		// non-critical error: styles file not found
@@ -335,6 +473,7 @@ func (xlsx *xlsxStream) readStyles() error {
 		}
 		xlsx.style2numFmtId[styleId] = xf.NumFmtId
 	}
+	xlsx.collectCellStyles(styles)
 	return nil
 }
 
@@ -400,7 +539,10 @@ func (xlsx *xlsxStream) readSharedStrings() error {
 	defer nowarnCloseCloser(rc)
 	decoder := xml.NewDecoder(rc)
 	var stateStr string
+	var stateRuns []TRichTextRun
 	var tmp string
+	xlsx.referenceTable = []string{}
+	xlsx.referenceRichTable = make(map[int][]TRichTextRun)
 	for {
 		tok, tokenErr := decoder.Token()
 		if tokenErr == io.EOF {
@@ -414,29 +556,50 @@ func (xlsx *xlsxStream) readSharedStrings() error {
 		switch tok := tok.(type) {
 		case xml.EndElement:
 			if tok.Name.Local == "si" {
+				if nil != stateRuns {
+					xlsx.referenceRichTable[len(xlsx.referenceTable)] = stateRuns
+				}
 				xlsx.referenceTable = append(xlsx.referenceTable, stateStr)
 			}
 		case xml.StartElement:
-			if tok.Name.Local == "si" {
+			switch tok.Name.Local {
+			case "si":
 				stateStr = ""
-			}
-			if tok.Name.Local == "t" {
+				stateRuns = nil
+			case "t":
 				if err := decoder.DecodeElement(&tmp, &tok); err != nil {
 					return err
 				}
 				stateStr = stateStr + tmp
+			case "r":
+				if xlsx.richText {
+					run := &xmlRichRun{}
+					if err := decoder.DecodeElement(run, &tok); err != nil {
+						return err
+					}
+					stateStr = stateStr + run.T
+					stateRuns = append(stateRuns, run.richTextRun())
+				}
+			case "rPh":
+				// phonetic reading (furigana) is not a part of displayed text
+				if xlsx.phonetic {
+					run := &xmlRichRun{}
+					if err := decoder.DecodeElement(run, &tok); err != nil {
+						return err
+					}
+					stateStr = stateStr + run.T
+				} else {
+					_ = decoder.Skip()
+				}
 			}
 		}
 	}
 	return nil
 }
 
+// Deprecated: SwitchSheet is kept for compatibility, use SetSheetId
 func (xlsx *xlsxStream) SwitchSheet(id int) error {
-	if id < 0 || id >= len(xlsx.sheets) {
-		return fmt.Errorf("sheet id is out of range")
-	}
-	_ = xlsx.SetSheetId(id)
-	return nil
+	return xlsx.SetSheetId(id)
 }
 
 func (xlsx *xlsxStream) GetSheets() []ITableSheetInfo {
@@ -452,19 +615,23 @@ func (xlsx *xlsxStream) GetCurrentSheetId() int {
 }
 
 func (xlsx *xlsxStream) SetSheetId(id int) error {
+	if err := checkSheetId(id, len(xlsx.sheets)); nil != err {
+		return err
+	}
 	xlsx.iteratorLastError = nil
 	xlsx.iteratorCapacity = 0
 	xlsx.iteratorRowNum = 0
 	xlsx.iteratorScannedRowNum = 0
 	xlsx.iteratorScannedData = []string{}
+	xlsx.iteratorScannedRichText = nil
+	xlsx.iteratorScannedStyles = []int{}
+	xlsx.iteratorScannedErrors = nil
+	xlsx.iteratorScannedDecimals = nil
 	xlsx.iteratorXMLSegment = iteratorSegmentRoot
 	if nil != xlsx.iteratorStream {
 		_ = xlsx.iteratorStream.Close()
 		xlsx.iteratorStream = nil // force rewind
 	}
-	if id < 0 || id > len(xlsx.sheets) {
-		return fmt.Errorf("sheet #%d not found", id)
-	}
 	_, err := xlsx.findZipHandler(xlsx.sheets[id].path)
 	if nil != err {
 		return err
@@ -476,7 +643,56 @@ func (xlsx *xlsxStream) GetScanned() []string {
 	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
 		return []string{}
 	}
-	return xlsx.iteratorScannedData
+	return pickStrings(xlsx.iteratorScannedData, xlsx.visibleColumnIds(len(xlsx.iteratorScannedData)))
+}
+
+func (xlsx *xlsxStream) GetScannedRowNum() int {
+	return xlsx.iteratorRowNum
+}
+
+func (xlsx *xlsxStream) GetScannedColumnIds() []int {
+	return scannedColumnIds(xlsx.visibleColumnIds(len(xlsx.iteratorScannedData)), len(xlsx.GetScanned()))
+}
+
+func (xlsx *xlsxStream) GetScannedRowInfo() TRowInfo {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		return TRowInfo{}
+	}
+	return xlsx.iteratorScannedRowInfo
+}
+
+func (xlsx *xlsxStream) GetColumnsInfo(sheetId int) (error, []TColumnInfo) {
+	if sheetId < 0 || sheetId >= len(xlsx.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	if nil == xlsx.sheets[sheetId].columns {
+		err, _ := xlsx.requireSheetMeta(sheetId)
+		if nil != err {
+			return err, nil
+		}
+	}
+	return nil, xlsx.sheets[sheetId].columns
+}
+
+func (xlsx *xlsxStream) SetVisibilityFilter(filter TVisibilityFilter) error {
+	xlsx.visibilityFilter = filter
+	return nil
+}
+
+func (xlsx *xlsxStream) GetVisibilityFilter() TVisibilityFilter {
+	return xlsx.visibilityFilter
+}
+
+func (xlsx *xlsxStream) visibleColumnIds(rowLength int) []int {
+	return xlsx.visibilityFilter.visibleColumnIds(xlsx.sheets[xlsx.iteratorSheetId].columns, rowLength)
+}
+
+// setColumns stores decoded <cols> of sheet
+func (sheet *xlsxTableSheetInfo) setColumns(cols *xmlCols) {
+	sheet.columns = []TColumnInfo{}
+	for _, col := range cols.Col {
+		sheet.columns = setColumnsInfo(sheet.columns, col.Min, col.Max, TColumnInfo{Hidden: col.Hidden, OutlineLevel: col.OutlineLevel, Collapsed: col.Collapsed})
+	}
 }
 
 func (xlsx *xlsxStream) GetLastScanError() error {
@@ -499,13 +715,25 @@ func (xlsx *xlsxStream) requireScanStream() error {
 }
 
 func (xlsx *xlsxStream) Scan() (err error) {
-	// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
-	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
-		xlsx.iteratorRowNum++
-	} else {
-		err = xlsx.scanInternal()
-		if nil == err {
+	for {
+		// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
+		if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
 			xlsx.iteratorRowNum++
+		} else {
+			err = xlsx.scanInternal()
+			if nil == err {
+				xlsx.iteratorRowNum++
+			}
+		}
+		if nil != err {
+			break
+		}
+		if xlsx.visibilityFilter.isRowFiltered(xlsx.GetScannedRowInfo()) {
+			continue
+		}
+		var autoFiltered bool
+		if err, autoFiltered = xlsx.isRowAutoFiltered(); nil != err || !autoFiltered {
+			break
 		}
 	}
 	xlsx.iteratorLastError = err
@@ -522,6 +750,7 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 	currentCellStyleId := -1
 	currentCellTypeStr := ""
 	currentCellString := ""
+	var currentCellRuns []TRichTextRun
 	rowIsParsed := false
 	for !rowIsParsed {
 		tok, tokenErr := xlsx.iteratorDecoder.Token()
@@ -554,6 +783,22 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 					if currentColumnNum < 1 {
 						panic(fmt.Sprintf("WTF i'm doing here? Cell have to been skipped in this condition! [file=%s sheet=%s at pos %d]", xlsx.zFileName, xlsx.sheets[xlsx.iteratorSheetId].path, xlsx.iteratorDecoder.InputOffset()))
 					}
+					if xlsx.autoFilterEvaluation && ("" == currentCellTypeStr || "n" == currentCellTypeStr || strCellTypeDate == currentCellTypeStr) {
+						for len(xlsx.iteratorScannedNumbers) < currentColumnNum {
+							xlsx.iteratorScannedNumbers = append(xlsx.iteratorScannedNumbers, "")
+						}
+						xlsx.iteratorScannedNumbers[currentColumnNum-1] = currentCellString
+						if strCellTypeDate == currentCellTypeStr {
+							// ISO 8601 dates are compared as serials
+							excelTime, _, _, _ := ExcelTimeFromISO8601(currentCellString, xlsx.formatter.date1904)
+							xlsx.iteratorScannedNumbers[currentColumnNum-1] = strconv.FormatFloat(excelTime, 'f', -1, 64)
+						}
+					}
+					if strCellTypeError == currentCellTypeStr {
+						xlsx.setScannedError(currentColumnNum, currentCellString)
+					} else if (strCellTypeNumeric == currentCellTypeStr || strCellTypeNumericAlt == currentCellTypeStr) && "" != strings.TrimSpace(currentCellString) {
+						xlsx.setScannedDecimal(currentColumnNum, strings.TrimSpace(currentCellString))
+					}
 					parsedFormat := xlsx.getParsedNumFmtByStyle(currentCellStyleId)
 					if nil == parsedFormat {
 						// style[#currentCellStyleId].numFmt is incorrect
@@ -573,6 +818,18 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 						}
 						xlsx.iteratorScannedData = append(xlsx.iteratorScannedData, currentCellString)
 					}
+					for len(xlsx.iteratorScannedStyles) < currentColumnNum {
+						xlsx.iteratorScannedStyles = append(xlsx.iteratorScannedStyles, 0)
+					}
+					if currentCellStyleId > 0 {
+						xlsx.iteratorScannedStyles[currentColumnNum-1] = currentCellStyleId
+					}
+					if nil != currentCellRuns {
+						for len(xlsx.iteratorScannedRichText) < currentColumnNum {
+							xlsx.iteratorScannedRichText = append(xlsx.iteratorScannedRichText, nil)
+						}
+						xlsx.iteratorScannedRichText[currentColumnNum-1] = currentCellRuns
+					}
 				}
 			case iteratorSegmentWSRCIs:
 				if tok.Name.Local == "is" {
@@ -601,12 +858,34 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 				case iteratorSegmentW:
 					if tok.Name.Local == "sheetData" {
 						nextSegment = iteratorSegmentWS
+					} else if tok.Name.Local == "cols" {
+						cols := &xmlCols{}
+						err = xlsx.iteratorDecoder.DecodeElement(cols, &tok)
+						tagIsDecoded = true
+						if nil == err {
+							xlsx.sheets[xlsx.iteratorSheetId].setColumns(cols)
+						}
 					}
 				case iteratorSegmentWS:
 					if tok.Name.Local == "row" {
 						nextSegment = iteratorSegmentWSR
 						xlsx.iteratorScannedData = make([]string, 0, xlsx.iteratorCapacity)
-						currentRowNumStr,attrExists := findXmlTokenAttrValue(&tok, "r")
+						xlsx.iteratorScannedRichText = nil
+						xlsx.iteratorScannedStyles = make([]int, 0, xlsx.iteratorCapacity)
+						xlsx.iteratorScannedErrors = nil
+						xlsx.iteratorScannedDecimals = nil
+						xlsx.iteratorScannedNumbers = nil
+						xlsx.iteratorScannedRowInfo = TRowInfo{}
+						if hidden, attrExists := findXmlTokenAttrValue(&tok, "hidden"); attrExists {
+							xlsx.iteratorScannedRowInfo.Hidden = parseXmlBool(hidden)
+						}
+						if outlineLevel, attrExists := findXmlTokenAttrValue(&tok, "outlineLevel"); attrExists {
+							xlsx.iteratorScannedRowInfo.OutlineLevel, _ = strconv.Atoi(outlineLevel)
+						}
+						if collapsed, attrExists := findXmlTokenAttrValue(&tok, "collapsed"); attrExists {
+							xlsx.iteratorScannedRowInfo.Collapsed = parseXmlBool(collapsed)
+						}
+						currentRowNumStr, attrExists := findXmlTokenAttrValue(&tok, "r")
 						if attrExists {
 							// attr "r" present, require valid int and greater than previous value
 							xlsx.iteratorScannedRowNum, err = strconv.Atoi(currentRowNumStr)
@@ -624,6 +903,7 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 					if tok.Name.Local == "c" {
 						nextSegment = iteratorSegmentWSRC
 						currentCellString = ""
+						currentCellRuns = nil
 						currentColumnNum = -1
 						currentCellTypeStr,_ = findXmlTokenAttrValue(&tok, "t")
 						currentCellStyleStr,_ = findXmlTokenAttrValue(&tok, "s")
@@ -673,6 +953,7 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 										break SkipCurrentToken
 									}
 									tagValue = xlsx.referenceTable[strId]
+									currentCellRuns = xlsx.referenceRichTable[strId]
 								}
 							}
 							currentCellString += tagValue
@@ -683,7 +964,22 @@ func (xlsx *xlsxStream) scanInternal() (err error) {
 				case iteratorSegmentWSRCIs:
 					switch tok.Name.Local {
 					case "r":
-						// do nothing, fetch next tag
+						if xlsx.richText {
+							run := &xmlRichRun{}
+							err = xlsx.iteratorDecoder.DecodeElement(run, &tok)
+							tagIsDecoded = true
+							if nil != err {
+								break SkipCurrentToken
+							}
+							currentCellString += run.T
+							currentCellRuns = append(currentCellRuns, run.richTextRun())
+						}
+						// otherwise do nothing, fetch next tag
+					case "rPh":
+						if !xlsx.phonetic {
+							break SkipCurrentToken
+						}
+						// phonetic text is fetched by nested <t>
 					case "t":
 						if currentColumnNum < 1 {
 							panic(fmt.Sprintf("WTF i'm doing here? Cell have to been skipped in this condition! [file=%s sheet=%s at pos %d]", xlsx.zFileName, xlsx.sheets[xlsx.iteratorSheetId].path, xlsx.iteratorDecoder.InputOffset()))
@@ -739,6 +1035,15 @@ func extractCellRangeCoords(cellRangeAddr string) (err error, x1 int, y1 int, x2
 	return
 }
 
+// make A5/D4/ZZ2354 address from coords (1-based)
+func makeCellAddr(x int, y int) string {
+	column := ""
+	for ; x > 0; x = (x - 1) / 26 {
+		column = string(rune('A'+(x-1)%26)) + column
+	}
+	return column + strconv.Itoa(y)
+}
+
 // parse A5/D4/ZZ2354 coords (1-based)
 func extractCellCoords(cellAddr string) (err error, x int, y int) {
 	for idx, char := range cellAddr {
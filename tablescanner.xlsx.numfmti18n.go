@@ -10,12 +10,15 @@ type tI18n struct {
 	monthNames3       [13]string
 	numFmtDefaults    map[int]string
 	numFmtSystem      map[string]string
+	boolNames         [2]string         // FALSE and TRUE
+	errorNames        map[string]string // localized error codes keyed by english ones, nil means english
 }
 
 var numFmtI18n = map[string]*tI18n{
 	"en": {
 		decimalSeparator:  ".",
 		thousandSeparator: ",",
+		boolNames:         [2]string{"FALSE", "TRUE"},
 		weekdayNames: [7]string{
 			"Sunday",
 			"Monday",
@@ -171,6 +174,17 @@ var numFmtI18n = map[string]*tI18n{
 	"ru": {
 		decimalSeparator:  ",",
 		thousandSeparator: "\xC2\xA0",
+		boolNames:         [2]string{"ЛОЖЬ", "ИСТИНА"},
+		errorNames: map[string]string{
+			CellErrorNull:        "#ПУСТО!",
+			CellErrorDivZero:     "#ДЕЛ/0!",
+			CellErrorValue:       "#ЗНАЧ!",
+			CellErrorRef:         "#ССЫЛКА!",
+			CellErrorName:        "#ИМЯ?",
+			CellErrorNum:         "#ЧИСЛО!",
+			CellErrorNA:          "#Н/Д",
+			CellErrorGettingData: "#ПОЛУЧЕНИЕ_ДАННЫХ",
+		},
 		monthNames: [13]string{
 			"",
 			"Январь",
@@ -0,0 +1,44 @@
+package tablescanner
+
+import (
+	"path"
+	"strings"
+)
+
+const relationTypeOfficeDocument = "officedocument" // last segment of transitional and strict relationship type
+
+// resolveWorkbookPart finds main workbook part by package relationships, then by declared content type,
+// defaultPath is returned for packages missing both
+func (xlsx *xlsxStream) resolveWorkbookPart(defaultPath string) string {
+	rels := new(xmlWorkbookRels)
+	if nil == xlsx.decodeZipXML("_rels/.rels", rels) {
+		for _, relation := range rels.Relationships {
+			if relationTypeOfficeDocument != strings.ToLower(path.Base(relation.Type)) || "" == relation.Target {
+				continue
+			}
+			target := strings.TrimPrefix(relation.Target, "/")
+			if _, err := xlsx.findZipHandler(target); nil == err {
+				return target
+			}
+		}
+	}
+	contentTypes := new(xmlContentTypes)
+	if nil == xlsx.decodeZipXML("[Content_Types].xml", contentTypes) {
+		for _, item := range contentTypes.Override {
+			if _, ok := workbookContentTypes[item.ContentType]; !ok && contentTypeXLSBWorkbook != item.ContentType {
+				continue
+			}
+			target := strings.TrimPrefix(item.PartName, "/")
+			if _, err := xlsx.findZipHandler(target); nil == err {
+				return target
+			}
+		}
+	}
+	return defaultPath
+}
+
+// workbookRelationsPath returns relationships part of workbook part, like xl/_rels/workbook.xml.rels
+func workbookRelationsPath(workbookPath string) string {
+	dir, file := path.Split(workbookPath)
+	return dir + "_rels/" + file + ".rels"
+}
@@ -0,0 +1,107 @@
+package tablescanner
+
+import "strconv"
+
+// xmlRunProperties is <rPr> of rich text run, attributes are kept raw and interpreted by richTextRun()
+type xmlRunProperties struct {
+	B      *xmlValProperty `xml:"b"`
+	I      *xmlValProperty `xml:"i"`
+	Strike *xmlValProperty `xml:"strike"`
+	U      *xmlValProperty `xml:"u"`
+	Color  *xmlColor       `xml:"color"`
+}
+
+// xmlValProperty is <b/>, <b val="0"/>, <u val="double"/> etc.
+type xmlValProperty struct {
+	Val *string `xml:"val,attr"`
+}
+
+type xmlColor struct {
+	Auto    *bool  `xml:"auto,attr"`
+	Rgb     string `xml:"rgb,attr"`
+	Theme   *int   `xml:"theme,attr"`
+	Indexed *int   `xml:"indexed,attr"`
+	Tint    string `xml:"tint,attr"`
+}
+
+// flag is set by bare tag, val attr is able to switch it off
+func (prop *xmlValProperty) isOn() bool {
+	if nil == prop {
+		return false
+	}
+	if nil == prop.Val {
+		return true
+	}
+	return *prop.Val != "0" && *prop.Val != "false"
+}
+
+// underline style, "" when not underlined
+func (prop *xmlValProperty) underline() string {
+	if nil == prop {
+		return ""
+	}
+	if nil == prop.Val {
+		return "single"
+	}
+	if *prop.Val == "none" {
+		return ""
+	}
+	return *prop.Val
+}
+
+// color as ARGB hex or palette reference
+func (color *xmlColor) String() string {
+	if nil == color {
+		return ""
+	}
+	switch {
+	case "" != color.Rgb:
+		return color.Rgb
+	case nil != color.Theme:
+		return "theme:" + strconv.Itoa(*color.Theme)
+	case nil != color.Indexed:
+		return "indexed:" + strconv.Itoa(*color.Indexed)
+	case nil != color.Auto && *color.Auto:
+		return "auto"
+	}
+	return ""
+}
+
+func (run *xmlRichRun) richTextRun() TRichTextRun {
+	res := TRichTextRun{Text: run.T}
+	if nil != run.RPr {
+		res.Bold = run.RPr.B.isOn()
+		res.Italic = run.RPr.I.isOn()
+		res.Strike = run.RPr.Strike.isOn()
+		res.Underline = run.RPr.U.underline()
+		res.Color = run.RPr.Color.String()
+	}
+	return res
+}
+
+func (xlsx *xlsxStream) GetScannedRichText() [][]TRichTextRun {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		return [][]TRichTextRun{}
+	}
+	res := make([][]TRichTextRun, len(xlsx.iteratorScannedData))
+	copy(res, xlsx.iteratorScannedRichText)
+	return pickRichText(res, xlsx.visibleColumnIds(len(res)))
+}
+
+// SetRichText switches collecting of rich text runs, shared strings are re-read to fill/release runs table
+func (xlsx *xlsxStream) SetRichText(enabled bool) error {
+	if xlsx.richText == enabled {
+		return nil
+	}
+	xlsx.richText = enabled
+	return xlsx.readSharedStrings()
+}
+
+// SetPhoneticRuns switches including of phonetic <rPh> runs into cell text, they are excluded by default
+func (xlsx *xlsxStream) SetPhoneticRuns(enabled bool) error {
+	if xlsx.phonetic == enabled {
+		return nil
+	}
+	xlsx.phonetic = enabled
+	return xlsx.readSharedStrings()
+}
@@ -0,0 +1,251 @@
+package tablescanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// xlsxSheetMeta keeps sheet parts placed outside of <sheetData>, some of them (like <hyperlinks>) follow rows,
+// so they are read by separate pass which skips rows and is started only when caller asks for such data
+type xlsxSheetMeta struct {
+	hyperlinks           map[int][]xlsxHyperlink // row number to hyperlinks touching the row
+	autoFilter           *TAutoFilter            // nil when sheet has neither <autoFilter> nor <sortState>
+	autoFilterThresholds map[int]float64         // autofilter column id to top10 threshold, nil until evaluation starts
+	freezePane           *TFreezePane            // pane of the first sheet view, nil when sheet is not split
+	dataValidations      []TDataValidation
+}
+
+type xlsxHyperlink struct {
+	columnFirst int // 1-based
+	columnLast  int // 1-based
+	target      string
+}
+
+type xmlHyperlink struct {
+	Ref      string `xml:"ref,attr"`
+	Id       string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	StrictId string `xml:"http://purl.oclc.org/ooxml/officeDocument/relationships id,attr"`
+	Location string `xml:"location,attr"`
+}
+
+// relationId returns relation id of transitional or strict namespace
+func (hyperlink *xmlHyperlink) relationId() string {
+	if "" == hyperlink.Id {
+		return hyperlink.StrictId
+	}
+	return hyperlink.Id
+}
+
+type xmlHyperlinks struct {
+	Hyperlink []xmlHyperlink `xml:"hyperlink"`
+}
+
+type xmlSheetViews struct {
+	SheetView []struct {
+		Pane *xmlPane `xml:"pane"`
+	} `xml:"sheetView"`
+}
+
+// xmlPane keeps split position in rows/columns for frozen panes and in 1/20 of point for split ones
+type xmlPane struct {
+	XSplit      float64 `xml:"xSplit,attr"`
+	YSplit      float64 `xml:"ySplit,attr"`
+	TopLeftCell string  `xml:"topLeftCell,attr"`
+	State       string  `xml:"state,attr"` // "split" (default)/"frozen"/"frozenSplit"
+}
+
+func (xlsx *xlsxStream) requireSheetMeta(sheetId int) (error, *xlsxSheetMeta) {
+	sheet := xlsx.sheets[sheetId]
+	if nil != sheet.meta {
+		return nil, sheet.meta
+	}
+	meta := &xlsxSheetMeta{
+		hyperlinks:      make(map[int][]xlsxHyperlink),
+		dataValidations: []TDataValidation{},
+	}
+	z, err := xlsx.findZipHandler(sheet.path)
+	if nil != err {
+		return fmt.Errorf("sheet #%d not found: %s", sheetId, err), nil
+	}
+	rc, err := z.Open()
+	if err != nil {
+		return fmt.Errorf("file stream [%s] Open() failed: %s", sheet.path, err.Error()), nil
+	}
+	defer nowarnCloseCloser(rc)
+	decoder := xml.NewDecoder(rc)
+	level := 0 // 0=/ 1=/worksheet
+	for {
+		tok, tokenErr := decoder.Token()
+		if io.EOF == tokenErr {
+			break
+		}
+		if tokenErr != nil {
+			return fmt.Errorf("xml token read error in [%s] at pos %d: %s", sheet.path, decoder.InputOffset(), tokenErr.Error()), nil
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			if 1 == level && tok.Name.Local == "worksheet" {
+				level = 0
+			}
+		case xml.StartElement:
+			if 0 == level {
+				if tok.Name.Local == "worksheet" {
+					level = 1
+				} else {
+					_ = decoder.Skip()
+				}
+				break
+			}
+			switch tok.Name.Local {
+			case "cols":
+				cols := &xmlCols{}
+				err = decoder.DecodeElement(cols, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <cols> in [%s]: %s", sheet.path, err), nil
+				}
+				sheet.setColumns(cols)
+			case "sheetViews":
+				sheetViews := &xmlSheetViews{}
+				err = decoder.DecodeElement(sheetViews, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <sheetViews> in [%s]: %s", sheet.path, err), nil
+				}
+				if len(sheetViews.SheetView) > 0 && nil != sheetViews.SheetView[0].Pane {
+					meta.freezePane = sheetViews.SheetView[0].Pane.freezePane()
+				}
+			case "hyperlinks":
+				hyperlinks := &xmlHyperlinks{}
+				err = decoder.DecodeElement(hyperlinks, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <hyperlinks> in [%s]: %s", sheet.path, err), nil
+				}
+				xlsx.collectHyperlinks(sheet, meta, hyperlinks)
+			case "autoFilter":
+				autoFilter := &xmlAutoFilter{}
+				err = decoder.DecodeElement(autoFilter, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <autoFilter> in [%s]: %s", sheet.path, err), nil
+				}
+				sortState := meta.autoFilter
+				meta.autoFilter = autoFilter.autoFilter()
+				if nil != sortState && nil == meta.autoFilter.SortState {
+					meta.autoFilter.SortState = sortState.SortState
+				}
+			case "sortState":
+				sortState := &xmlSortState{}
+				err = decoder.DecodeElement(sortState, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <sortState> in [%s]: %s", sheet.path, err), nil
+				}
+				if nil == meta.autoFilter {
+					meta.autoFilter = &TAutoFilter{Columns: []TAutoFilterColumn{}}
+				}
+				meta.autoFilter.SortState = sortState.sortState()
+			case "dataValidations":
+				validations := &xmlDataValidations{}
+				err = decoder.DecodeElement(validations, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <dataValidations> in [%s]: %s", sheet.path, err), nil
+				}
+				meta.collectDataValidations(validations)
+			case "extLst":
+				extLst := &xmlExtLst{}
+				err = decoder.DecodeElement(extLst, &tok)
+				if nil != err {
+					return fmt.Errorf("cannot decode <extLst> in [%s]: %s", sheet.path, err), nil
+				}
+				meta.collectX14DataValidations(extLst)
+			default:
+				// <sheetData> is skipped here too
+				_ = decoder.Skip()
+			}
+		}
+	}
+	if nil == sheet.columns {
+		sheet.columns = []TColumnInfo{}
+	}
+	sheet.meta = meta
+	return nil, meta
+}
+
+func (pane *xmlPane) freezePane() *TFreezePane {
+	res := &TFreezePane{
+		Frozen:      "frozen" == pane.State || "frozenSplit" == pane.State,
+		TopLeftCell: pane.TopLeftCell,
+	}
+	if res.Frozen {
+		res.Rows = int(pane.YSplit)
+		res.Columns = int(pane.XSplit)
+	} else if err, x, y := extractCellCoords(pane.TopLeftCell); nil == err {
+		// split position is measured in twips, so pane extent is taken from its first cell
+		res.Rows = y - 1
+		res.Columns = x - 1
+	}
+	return res
+}
+
+func (xlsx *xlsxStream) GetFreezePane(sheetId int) (error, *TFreezePane) {
+	if sheetId < 0 || sheetId >= len(xlsx.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	err, meta := xlsx.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.freezePane
+}
+
+func (xlsx *xlsxStream) collectHyperlinks(sheet *xlsxTableSheetInfo, meta *xlsxSheetMeta, hyperlinks *xmlHyperlinks) {
+	for _, hyperlink := range hyperlinks.Hyperlink {
+		target := sheet.hyperlinkTarget(hyperlink.relationId(), hyperlink.Location)
+		if "" == target {
+			continue
+		}
+		err, x1, y1, x2, y2 := extractCellRangeCoords(hyperlink.Ref)
+		if nil != err {
+			// single cell reference
+			err, x1, y1 = extractCellCoords(hyperlink.Ref)
+			if nil != err {
+				continue
+			}
+			x2, y2 = x1, y1
+		}
+		for y := y1; y <= y2; y++ {
+			meta.hyperlinks[y] = append(meta.hyperlinks[y], xlsxHyperlink{columnFirst: x1, columnLast: x2, target: target})
+		}
+	}
+}
+
+// hyperlinkTarget joins external target of relation and internal document location prefixed with '#'
+func (sheet *xlsxTableSheetInfo) hyperlinkTarget(relationId string, location string) string {
+	target := ""
+	if relation, ok := sheet.relations[relationId]; ok {
+		target = relation.Target
+	}
+	if "" != location {
+		target += "#" + location
+	}
+	return target
+}
+
+func (xlsx *xlsxStream) GetScannedHyperlinks() []string {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum || xlsx.iteratorScannedRowNum < 1 {
+		return []string{}
+	}
+	err, meta := xlsx.requireSheetMeta(xlsx.iteratorSheetId)
+	if nil != err {
+		return []string{}
+	}
+	hyperlinks := meta.hyperlinks[xlsx.iteratorScannedRowNum]
+	res := make([]string, len(xlsx.iteratorScannedData))
+	for _, hyperlink := range hyperlinks {
+		for len(res) < hyperlink.columnLast {
+			res = append(res, "")
+		}
+		for x := hyperlink.columnFirst; x <= hyperlink.columnLast; x++ {
+			res[x-1] = hyperlink.target
+		}
+	}
+	return pickStrings(res, xlsx.visibleColumnIds(len(res)))
+}
@@ -0,0 +1,211 @@
+package tablescanner
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type xmlFont struct {
+	Name   *xmlValProperty `xml:"name"`
+	Sz     *xmlValProperty `xml:"sz"`
+	B      *xmlValProperty `xml:"b"`
+	I      *xmlValProperty `xml:"i"`
+	Strike *xmlValProperty `xml:"strike"`
+	U      *xmlValProperty `xml:"u"`
+	Color  *xmlColor       `xml:"color"`
+}
+
+type xmlFill struct {
+	PatternFill  *xmlPatternFill  `xml:"patternFill"`
+	GradientFill *xmlGradientFill `xml:"gradientFill"`
+}
+
+type xmlPatternFill struct {
+	PatternType string    `xml:"patternType,attr"`
+	FgColor     *xmlColor `xml:"fgColor"`
+	BgColor     *xmlColor `xml:"bgColor"`
+}
+
+type xmlGradientFill struct {
+	Stop []xmlGradientStop `xml:"stop"`
+}
+
+type xmlGradientStop struct {
+	Color *xmlColor `xml:"color"`
+}
+
+type xmlBorder struct {
+	DiagonalUp   bool           `xml:"diagonalUp,attr"`
+	DiagonalDown bool           `xml:"diagonalDown,attr"`
+	Left         *xmlBorderEdge `xml:"left"`
+	Right        *xmlBorderEdge `xml:"right"`
+	Top          *xmlBorderEdge `xml:"top"`
+	Bottom       *xmlBorderEdge `xml:"bottom"`
+	Diagonal     *xmlBorderEdge `xml:"diagonal"`
+}
+
+type xmlBorderEdge struct {
+	Style string    `xml:"style,attr"`
+	Color *xmlColor `xml:"color"`
+}
+
+type xmlAlignment struct {
+	Horizontal   string `xml:"horizontal,attr"`
+	Vertical     string `xml:"vertical,attr"`
+	WrapText     bool   `xml:"wrapText,attr"`
+	ShrinkToFit  bool   `xml:"shrinkToFit,attr"`
+	Indent       int    `xml:"indent,attr"`
+	TextRotation int    `xml:"textRotation,attr"`
+}
+
+type xmlProtection struct {
+	Locked *bool `xml:"locked,attr"`
+	Hidden bool  `xml:"hidden,attr"`
+}
+
+func (font *xmlFont) cellFont() TCellFont {
+	res := TCellFont{
+		Bold:      font.B.isOn(),
+		Italic:    font.I.isOn(),
+		Strike:    font.Strike.isOn(),
+		Underline: font.U.underline(),
+		Color:     font.Color.String(),
+	}
+	if nil != font.Name && nil != font.Name.Val {
+		res.Name = *font.Name.Val
+	}
+	if nil != font.Sz && nil != font.Sz.Val {
+		res.Size, _ = strconv.ParseFloat(*font.Sz.Val, 64)
+	}
+	return res
+}
+
+func (fill *xmlFill) cellFill() TCellFill {
+	res := TCellFill{PatternType: "none"}
+	switch {
+	case nil != fill.PatternFill:
+		if "" != fill.PatternFill.PatternType {
+			res.PatternType = fill.PatternFill.PatternType
+		}
+		res.FgColor = fill.PatternFill.FgColor.String()
+		res.BgColor = fill.PatternFill.BgColor.String()
+	case nil != fill.GradientFill:
+		res.PatternType = "gradient"
+		if len(fill.GradientFill.Stop) > 0 {
+			res.FgColor = fill.GradientFill.Stop[0].Color.String()
+			res.BgColor = fill.GradientFill.Stop[len(fill.GradientFill.Stop)-1].Color.String()
+		}
+	}
+	return res
+}
+
+func (edge *xmlBorderEdge) cellBorderEdge() TCellBorderEdge {
+	if nil == edge || "none" == edge.Style {
+		return TCellBorderEdge{}
+	}
+	return TCellBorderEdge{Style: edge.Style, Color: edge.Color.String()}
+}
+
+func (border *xmlBorder) cellBorder() TCellBorder {
+	return TCellBorder{
+		Left:         border.Left.cellBorderEdge(),
+		Right:        border.Right.cellBorderEdge(),
+		Top:          border.Top.cellBorderEdge(),
+		Bottom:       border.Bottom.cellBorderEdge(),
+		Diagonal:     border.Diagonal.cellBorderEdge(),
+		DiagonalUp:   border.DiagonalUp,
+		DiagonalDown: border.DiagonalDown,
+	}
+}
+
+// isApplied treats absent apply* attribute as applied, cell xf values take precedence over cell style xf
+func isApplied(apply *bool) bool {
+	return nil == apply || *apply
+}
+
+// collectCellStyles resolves cellXfs entries, components with apply*="0" are inherited from cellStyleXfs[xfId]
+func (xlsx *xlsxStream) collectCellStyles(styles *xmlStyleSheet) {
+	fonts := make([]TCellFont, len(styles.Fonts))
+	for i := range styles.Fonts {
+		fonts[i] = styles.Fonts[i].cellFont()
+	}
+	fills := make([]TCellFill, len(styles.Fills))
+	for i := range styles.Fills {
+		fills[i] = styles.Fills[i].cellFill()
+	}
+	borders := make([]TCellBorder, len(styles.Borders))
+	for i := range styles.Borders {
+		borders[i] = styles.Borders[i].cellBorder()
+	}
+	makeStyle := func(xf *xmlXf) TCellStyle {
+		style := TCellStyle{NumFmtId: xf.NumFmtId, Protection: TCellProtection{Locked: true}}
+		if xf.FontId >= 0 && xf.FontId < len(fonts) {
+			style.Font = fonts[xf.FontId]
+		}
+		if xf.FillId >= 0 && xf.FillId < len(fills) {
+			style.Fill = fills[xf.FillId]
+		}
+		if xf.BorderId >= 0 && xf.BorderId < len(borders) {
+			style.Border = borders[xf.BorderId]
+		}
+		if nil != xf.Alignment {
+			style.Alignment = TCellAlignment(*xf.Alignment)
+		}
+		if nil != xf.Protection {
+			style.Protection.Locked = nil == xf.Protection.Locked || *xf.Protection.Locked
+			style.Protection.Hidden = xf.Protection.Hidden
+		}
+		return style
+	}
+	parents := make([]TCellStyle, len(styles.CellStyleXfs.Xf))
+	for i := range styles.CellStyleXfs.Xf {
+		parents[i] = makeStyle(&styles.CellStyleXfs.Xf[i])
+	}
+	xlsx.cellStyles = make([]TCellStyle, len(styles.CellXfs.Xf))
+	for i := range styles.CellXfs.Xf {
+		xf := &styles.CellXfs.Xf[i]
+		style := makeStyle(xf)
+		if xf.XfId >= 0 && xf.XfId < len(parents) {
+			parent := &parents[xf.XfId]
+			if !isApplied(xf.ApplyFont) {
+				style.Font = parent.Font
+			}
+			if !isApplied(xf.ApplyFill) {
+				style.Fill = parent.Fill
+			}
+			if !isApplied(xf.ApplyBorder) {
+				style.Border = parent.Border
+			}
+			if nil == xf.Alignment && !(nil != xf.ApplyAlignment && *xf.ApplyAlignment) {
+				style.Alignment = parent.Alignment
+			}
+			if nil == xf.Protection && !(nil != xf.ApplyProtection && *xf.ApplyProtection) {
+				style.Protection = parent.Protection
+			}
+		}
+		xlsx.cellStyles[i] = style
+	}
+}
+
+// GetScannedStyles returns style ids of scanned row cells, use GetStyle() to resolve them
+func (xlsx *xlsxStream) GetScannedStyles() []int {
+	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
+		return []int{}
+	}
+	res := make([]int, len(xlsx.iteratorScannedData))
+	copy(res, xlsx.iteratorScannedStyles)
+	return pickInts(res, xlsx.visibleColumnIds(len(res)))
+}
+
+func (xlsx *xlsxStream) GetStyle(styleId int) (error, *TCellStyle) {
+	if 0 == styleId && 0 == len(xlsx.cellStyles) {
+		// workbook without styles part, default style is implied
+		return nil, &TCellStyle{NumFmt: xlsx.getParsedNumFmtByStyle(0).numFmt, Fill: TCellFill{PatternType: "none"}, Protection: TCellProtection{Locked: true}}
+	}
+	if styleId < 0 || styleId >= len(xlsx.cellStyles) {
+		return fmt.Errorf("style #%d not found", styleId), nil
+	}
+	style := xlsx.cellStyles[styleId]
+	style.NumFmt = xlsx.getParsedNumFmtByStyle(styleId).numFmt
+	return nil, &style
+}
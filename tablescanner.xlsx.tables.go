@@ -0,0 +1,139 @@
+package tablescanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const relationTypeTable = "table"
+
+type xlsxTableInfo struct {
+	Name           string
+	DisplayName    string
+	Ref            string
+	HeaderRowCount int
+	TotalsRowCount int
+	Columns        []string
+	sheetId        int
+	path           string
+}
+
+type xmlTable struct {
+	Name           string          `xml:"name,attr"`
+	DisplayName    string          `xml:"displayName,attr"`
+	Ref            string          `xml:"ref,attr"`
+	HeaderRowCount *int            `xml:"headerRowCount,attr"` // absent attribute means single header row
+	TotalsRowCount int             `xml:"totalsRowCount,attr"`
+	TableColumns   xmlTableColumns `xml:"tableColumns"`
+}
+
+type xmlTableColumns struct {
+	TableColumn []xmlTableColumn `xml:"tableColumn"`
+}
+
+type xmlTableColumn struct {
+	Id   int    `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+}
+
+func (table *xlsxTableInfo) GetName() string {
+	if "" != table.DisplayName {
+		return table.DisplayName
+	}
+	return table.Name
+}
+
+func (table *xlsxTableInfo) GetSheetId() int {
+	return table.sheetId
+}
+
+func (table *xlsxTableInfo) GetRef() string {
+	return table.Ref
+}
+
+func (table *xlsxTableInfo) GetHeaderRowCount() int {
+	return table.HeaderRowCount
+}
+
+func (table *xlsxTableInfo) GetTotalsRowCount() int {
+	return table.TotalsRowCount
+}
+
+func (table *xlsxTableInfo) GetColumns() []string {
+	return table.Columns
+}
+
+// table parts are small, so all of them are decoded while opening the workbook
+func (xlsx *xlsxStream) readSheetTables(sheetId int) error {
+	sheet := xlsx.sheets[sheetId]
+	sheet.tables = []*xlsxTableInfo{}
+	for _, relation := range sheet.relations {
+		if strings.ToLower(path.Base(relation.Type)) != relationTypeTable {
+			continue
+		}
+		z, err := xlsx.findZipHandler(relation.Target)
+		if nil != err {
+			// broken relation isn't critical, table is softly ignored
+			continue
+		}
+		rc, err := z.Open()
+		if err != nil {
+			return err
+		}
+		table := &xmlTable{}
+		err = xml.NewDecoder(rc).Decode(table)
+		nowarnCloseCloser(rc)
+		if err != nil {
+			return fmt.Errorf("cannot decode table [%s]: %s", relation.Target, err)
+		}
+		info := &xlsxTableInfo{
+			Name:           table.Name,
+			DisplayName:    table.DisplayName,
+			Ref:            table.Ref,
+			HeaderRowCount: 1,
+			TotalsRowCount: table.TotalsRowCount,
+			Columns:        make([]string, len(table.TableColumns.TableColumn)),
+			sheetId:        sheetId,
+			path:           relation.Target,
+		}
+		if nil != table.HeaderRowCount {
+			info.HeaderRowCount = *table.HeaderRowCount
+		}
+		for i, column := range table.TableColumns.TableColumn {
+			info.Columns[i] = column.Name
+		}
+		sheet.tables = append(sheet.tables, info)
+	}
+	// relations come from map, keep tables ordered by part name for reproducible listing,
+	// numbered parts go by number, so table10.xml follows table2.xml
+	sort.Slice(sheet.tables, func(i, j int) bool {
+		prefixI, numberI := splitPartNumber(sheet.tables[i].path)
+		prefixJ, numberJ := splitPartNumber(sheet.tables[j].path)
+		if prefixI != prefixJ {
+			return prefixI < prefixJ
+		}
+		if numberI != numberJ {
+			return numberI < numberJ
+		}
+		return sheet.tables[i].path < sheet.tables[j].path
+	})
+	return nil
+}
+
+// splitPartNumber splits "xl/tables/table12.xml" to "xl/tables/table" and 12, part without number gets -1
+func splitPartNumber(partPath string) (string, int) {
+	name := strings.TrimSuffix(partPath, path.Ext(partPath))
+	digits := len(name)
+	for digits > 0 && name[digits-1] >= '0' && name[digits-1] <= '9' {
+		digits--
+	}
+	number, err := strconv.Atoi(name[digits:])
+	if nil != err {
+		return name, -1
+	}
+	return name[:digits], number
+}
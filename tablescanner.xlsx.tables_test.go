@@ -0,0 +1,41 @@
+package tablescanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitPartNumber(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix string
+		number int
+	}{
+		{"xl/tables/table12.xml", "xl/tables/table", 12},
+		{"xl/tables/table2.xml", "xl/tables/table", 2},
+		{"xl/tables/table.xml", "xl/tables/table", -1},
+		{"xl/tables/t1able", "xl/tables/t1able", -1},
+	}
+	for _, test := range tests {
+		if prefix, number := splitPartNumber(test.path); test.prefix != prefix || test.number != number {
+			t.Errorf("%s is split to %q %d, expected %q %d", test.path, prefix, number, test.prefix, test.number)
+		}
+	}
+}
+
+// tables.xlsx keeps table1.xml, table2.xml and table10.xml on single sheet, relation ids are shuffled
+func TestXLSXTablesOrder(t *testing.T) {
+	err, scanner := NewTableStream(filepath.Join("testdata", "tables.xlsx"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer nowarnCloseCloser(scanner)
+	names := []string{}
+	for _, table := range scanner.GetSheets()[0].GetTables() {
+		names = append(names, table.GetName())
+	}
+	if expected := []string{"Table1", "Table2", "Table10"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("tables are listed as %q, expected %q", names, expected)
+	}
+}
@@ -0,0 +1,85 @@
+package tablescanner
+
+import "fmt"
+
+type xmlDataValidations struct {
+	DataValidation []xmlDataValidation `xml:"dataValidation"`
+}
+
+type xmlDataValidation struct {
+	Type       string `xml:"type,attr"`
+	Operator   string `xml:"operator,attr"`
+	AllowBlank bool   `xml:"allowBlank,attr"`
+	ErrorTitle string `xml:"errorTitle,attr"`
+	Error      string `xml:"error,attr"`
+	Sqref      string `xml:"sqref,attr"`
+	Formula1   string `xml:"formula1"`
+	Formula2   string `xml:"formula2"`
+}
+
+// xmlExtLst keeps x14 data validations which are written by excel for lists referring other sheets
+type xmlExtLst struct {
+	Ext []struct {
+		DataValidations *struct {
+			DataValidation []xmlX14DataValidation `xml:"dataValidation"`
+		} `xml:"dataValidations"`
+	} `xml:"ext"`
+}
+
+type xmlX14DataValidation struct {
+	Type       string `xml:"type,attr"`
+	Operator   string `xml:"operator,attr"`
+	AllowBlank bool   `xml:"allowBlank,attr"`
+	ErrorTitle string `xml:"errorTitle,attr"`
+	Error      string `xml:"error,attr"`
+	Formula1   string `xml:"formula1>f"`
+	Formula2   string `xml:"formula2>f"`
+	Sqref      string `xml:"sqref"`
+}
+
+func makeDataValidation(validationType string, operator string, allowBlank bool, errorTitle string, errorText string, sqref string, formula1 string, formula2 string) TDataValidation {
+	res := TDataValidation{
+		Ref:        sqref,
+		Type:       validationType,
+		Operator:   operator,
+		AllowBlank: allowBlank,
+		ErrorTitle: errorTitle,
+		Error:      errorText,
+	}
+	if "" == res.Type {
+		res.Type = "any"
+	}
+	if "" == res.Operator && "any" != res.Type && "list" != res.Type && "custom" != res.Type {
+		res.Operator = "between"
+	}
+	res.setFormulas(formula1, formula2)
+	return res
+}
+
+func (meta *xlsxSheetMeta) collectDataValidations(validations *xmlDataValidations) {
+	for _, v := range validations.DataValidation {
+		meta.dataValidations = append(meta.dataValidations, makeDataValidation(v.Type, v.Operator, v.AllowBlank, v.ErrorTitle, v.Error, v.Sqref, v.Formula1, v.Formula2))
+	}
+}
+
+func (meta *xlsxSheetMeta) collectX14DataValidations(extLst *xmlExtLst) {
+	for _, ext := range extLst.Ext {
+		if nil == ext.DataValidations {
+			continue
+		}
+		for _, v := range ext.DataValidations.DataValidation {
+			meta.dataValidations = append(meta.dataValidations, makeDataValidation(v.Type, v.Operator, v.AllowBlank, v.ErrorTitle, v.Error, v.Sqref, v.Formula1, v.Formula2))
+		}
+	}
+}
+
+func (xlsx *xlsxStream) GetDataValidations(sheetId int) (error, []TDataValidation) {
+	if sheetId < 0 || sheetId >= len(xlsx.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	err, meta := xlsx.requireSheetMeta(sheetId)
+	if nil != err {
+		return err, nil
+	}
+	return nil, meta.dataValidations
+}
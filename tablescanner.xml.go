@@ -13,10 +13,12 @@ import (
 )
 
 type xmlTableSheetInfo struct {
-	Name      string
-	HideLevel TSheetHideLevel
-	start     int64 // offset of <Worksheet>
-	stop      int64 // offset of </Worksheet>
+	Name       string
+	HideLevel  TSheetHideLevel
+	start      int64         // offset of <Worksheet>
+	stop       int64         // offset of </Worksheet>
+	columns    []TColumnInfo // <Column> declarations, nil until requested
+	freezePane *TFreezePane  // nil when sheet is not split
 }
 
 type ReadSeekCloser interface {
@@ -36,6 +38,9 @@ const (
 
 type xmlHandle struct {
 	formatter                    excelFormatter
+	i18n                         *tI18n                         // reference to selected i18n config
+	styleNumberFormats           map[string]string              // ss:ID to ss:Format of <NumberFormat>, inherited ones included
+	styleNumberFormatCache       map[string]*parsedNumberFormat // ss:ID to parsedNumberFormat
 	sheets                       []*xmlTableSheetInfo
 	sheetSelected                int                    // default-opening sheet id
 	iteratorLastError            error                  // error which caused last Scan() failed
@@ -47,18 +52,79 @@ type xmlHandle struct {
 	iteratorXMLSegment           tIteratorRAWXMLSegment // current decoder xml tree location
 	iteratorScannedRowNum        int                    // current row number fetched by reading, starting with 1
 	iteratorScannedData          []string               // current row-iterating row data
+	iteratorScannedHyperlinks    []string               // current row-iterating row ss:HRef values
+	iteratorScannedErrors        []*TCellError          // current row-iterating row Error cells
+	iteratorScannedDecimals      []TDecimal             // current row-iterating row Number cells
+	iteratorScannedRowInfo       TRowInfo               // current row-iterating row visibility
+	visibilityFilter             TVisibilityFilter      // rows and columns to be skipped while scanning
 	iteratorRowNum               int                    // row number that Scan() implies (starting with 1)
 	iteratorSheetId              int                    // current row-iterating sheet id
 }
 
 type rawxmlWorksheetOptions struct {
-	Visible  string     `xml:"Visible,omitempty"`  // "SheetHidden"/"SheetVeryHidden"/""
-	Selected []struct{} `xml:"Selected,omitempty"` // <selected /> = []bool{false}
+	Visible             string     `xml:"Visible,omitempty"`     // "SheetHidden"/"SheetVeryHidden"/""
+	Selected            []struct{} `xml:"Selected,omitempty"`    // <selected /> = []bool{false}
+	FreezePanes         []struct{} `xml:"FreezePanes,omitempty"` // split position is measured in rows/columns when present, in twips otherwise
+	SplitHorizontal     *float64   `xml:"SplitHorizontal"`
+	SplitVertical       *float64   `xml:"SplitVertical"`
+	TopRowBottomPane    int        `xml:"TopRowBottomPane"`    // 0-based
+	LeftColumnRightPane int        `xml:"LeftColumnRightPane"` // 0-based
 }
 
 type rawxmlCell struct {
-	//Type  string     `xml:"Type,attr"`  // "String"/"Number" expected only, not yet used
-	Data string `xml:"Data,omitempty"`
+	Data rawxmlData `xml:"Data,omitempty"`
+}
+
+type rawxmlData struct {
+	Type  string `xml:"Type,attr"` // "String"/"Number"/"DateTime"/"Boolean"/"Error", numbers are kept as is
+	Value string `xml:",chardata"`
+}
+
+type rawxmlStyles struct {
+	Style []struct {
+		ID           string `xml:"ID,attr"`
+		Parent       string `xml:"Parent,attr"`
+		NumberFormat *struct {
+			Format string `xml:"Format,attr"`
+		} `xml:"NumberFormat"`
+	} `xml:"Style"`
+}
+
+// spreadsheetMLNamedFormats maps named ss:Format values to built-in number format ids
+var spreadsheetMLNamedFormats = map[string]int{
+	"General":        0,
+	"General Number": 0,
+	"General Date":   22,
+	"Short Date":     14,
+	"Medium Date":    15,
+	"Short Time":     20,
+	"Medium Time":    18,
+	"Long Time":      19,
+	"Fixed":          2,
+	"Standard":       4,
+	"Percent":        10,
+	"Scientific":     11,
+}
+
+func (options *rawxmlWorksheetOptions) freezePane() *TFreezePane {
+	if nil == options.SplitHorizontal && nil == options.SplitVertical {
+		return nil
+	}
+	res := &TFreezePane{Frozen: 0 < len(options.FreezePanes)}
+	if res.Frozen {
+		if nil != options.SplitHorizontal {
+			res.Rows = int(*options.SplitHorizontal)
+		}
+		if nil != options.SplitVertical {
+			res.Columns = int(*options.SplitVertical)
+		}
+	} else {
+		// split position is measured in twips, so pane extent is taken from its first cell
+		res.Rows = options.TopRowBottomPane
+		res.Columns = options.LeftColumnRightPane
+	}
+	res.TopLeftCell = makeCellAddr(options.LeftColumnRightPane+1, options.TopRowBottomPane+1)
+	return res
 }
 
 func makeUTF8BufferFromUTF16(reader io.Reader, isLittleEndian bool, contentLength int64) []byte {
@@ -100,12 +166,11 @@ func newXMLStream(fileName string, textEncoding TTextEnconding, BOMPresent []byt
 	}
 	fileStat, err := fileHandle.Stat()
 	fileSize := fileStat.Size()
-	/* not yet supported
 	err = xls.SetI18n("en")
 	if err != nil {
 		return err, nil
 	}
-	*/
+	xls.styleNumberFormats = make(map[string]string)
 	//var offsetBom int64 =0
 	var xmlDecodableBuf io.ReadSeeker
 	xmlDecodableBuf = xls.iteratorStreamSource
@@ -181,6 +246,7 @@ func newXMLStream(fileName string, textEncoding TTextEnconding, BOMPresent []byt
 						return fmt.Errorf("var currentSheetId has not been initialized yet... parser FSM seems to be inconsistent"), nil
 					}
 					xls.sheets = append(xls.sheets, sheet)
+					sheet.freezePane = currentSheetOptions.freezePane()
 					if 0 < len(currentSheetOptions.Selected) {
 						xls.sheetSelected = currentSheetId
 					}
@@ -212,6 +278,15 @@ func newXMLStream(fileName string, textEncoding TTextEnconding, BOMPresent []byt
 				if 2 == level {
 					_ = xls.iteratorDecoder.Skip()
 				}
+			case "Styles":
+				if 1 == level {
+					styles := &rawxmlStyles{}
+					err = xls.iteratorDecoder.DecodeElement(styles, &tok)
+					if nil != err {
+						return fmt.Errorf("Cannot decode <Styles> at offset %d: %s", offset, err), nil
+					}
+					xls.readStyles(styles)
+				}
 			default:
 				_ = xls.iteratorDecoder.Skip()
 			}
@@ -229,6 +304,11 @@ func (sheet *xmlTableSheetInfo) GetHideLevel() TSheetHideLevel {
 	return sheet.HideLevel
 }
 
+func (sheet *xmlTableSheetInfo) GetTables() []ITableInfo {
+	// tables (ListObjects) are not supported by this format
+	return []ITableInfo{}
+}
+
 func (xls *xmlHandle) Close() error {
 	return xls.iteratorStreamSource.Close()
 }
@@ -237,14 +317,66 @@ func (sheet *xmlHandle) FormatterAvailable() bool {
 	return false
 }
 
-func (xls *xmlHandle) SetI18n(string) error {
-	_, _ = os.Stderr.WriteString("WARNING! Formatter is unavailable for XLS format [1]!\n")
+// SetI18n affects DateTime cells only, other cells are returned as stored
+func (xls *xmlHandle) SetI18n(code string) error {
+	if _, ok := numFmtI18n[code]; !ok {
+		return fmt.Errorf("Unknown i18n[%s]", code)
+	}
+	xls.i18n = numFmtI18n[code]
+	xls.formatter.setI18n(xls.i18n)
+	xls.styleNumberFormatCache = make(map[string]*parsedNumberFormat)
 	return nil
 }
 
+// Formatter affects DateTime cells only
 func (xls *xmlHandle) Formatter() IExcelFormatter {
-	_, _ = os.Stderr.WriteString("WARNING! Formatter is unavailable for XLS format [2]!\n")
-	return newExcelFormatter("en")
+	return &xls.formatter
+}
+
+// readStyles keeps number formats of styles, parent style formats are inherited
+func (xls *xmlHandle) readStyles(styles *rawxmlStyles) {
+	parents := make(map[string]string)
+	for _, style := range styles.Style {
+		parents[style.ID] = style.Parent
+		if nil != style.NumberFormat {
+			xls.styleNumberFormats[style.ID] = style.NumberFormat.Format
+		}
+	}
+	for id := range parents {
+		parent := parents[id]
+		for depth := 0; depth < 16 && "" != parent; depth++ {
+			if _, ok := xls.styleNumberFormats[id]; ok {
+				break
+			}
+			if format, ok := xls.styleNumberFormats[parent]; ok {
+				xls.styleNumberFormats[id] = format
+			}
+			parent = parents[parent]
+		}
+	}
+}
+
+// getParsedNumFmt resolves named and system (like [$-F800]) formats of style by selected i18n
+func (xls *xmlHandle) getParsedNumFmt(styleId string) *parsedNumberFormat {
+	if parsedFormat, ok := xls.styleNumberFormatCache[styleId]; ok {
+		return parsedFormat
+	}
+	numFmt := xls.styleNumberFormats[styleId]
+	if numFmtId, ok := spreadsheetMLNamedFormats[numFmt]; ok {
+		numFmt = xls.i18n.numFmtDefaults[numFmtId]
+	} else if "Long Date" == numFmt {
+		numFmt = xls.i18n.numFmtSystem["[$-F800]"]
+	} else if len(numFmt) >= 2 && numFmt[0] == '[' && numFmt[1] == '$' {
+		if systemRefEnd := strings.IndexRune(numFmt, ']'); systemRefEnd >= 0 {
+			if systemFmt, found := xls.i18n.numFmtSystem[numFmt[0:systemRefEnd+1]]; found {
+				numFmt = systemFmt
+			} else {
+				numFmt = numFmt[systemRefEnd+1:]
+			}
+		}
+	}
+	xls.styleNumberFormatCache[styleId] = parseNumFmt(numFmt)
+	return xls.styleNumberFormatCache[styleId]
 }
 
 func (xls *xmlHandle) GetSheets() []ITableSheetInfo {
@@ -260,14 +392,18 @@ func (xls *xmlHandle) GetCurrentSheetId() int {
 }
 
 func (xls *xmlHandle) SetSheetId(id int) error {
+	if err := checkSheetId(id, len(xls.sheets)); nil != err {
+		return err
+	}
 	xls.iteratorLastError = nil
 	xls.iteratorCapacity = 0
 	xls.iteratorRowNum = 0
+	xls.iteratorScannedRowNum = 0
 	xls.iteratorScannedData = []string{}
+	xls.iteratorScannedHyperlinks = []string{}
+	xls.iteratorScannedErrors = nil
+	xls.iteratorScannedDecimals = nil
 	xls.iteratorXMLSegment = iteratorRXSegmentRoot
-	if id < 0 || id > len(xls.sheets) {
-		return fmt.Errorf("sheet #%d not found", id)
-	}
 	xls.iteratorSheetId = id
 	xls.iteratorDecoder = nil
 	return nil
@@ -278,13 +414,21 @@ func (xls *xmlHandle) GetLastScanError() error {
 }
 
 func (xls *xmlHandle) Scan() (err error) {
-	// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
-	if xls.iteratorScannedRowNum > xls.iteratorRowNum {
-		xls.iteratorRowNum++
-	} else {
-		err = xls.scanInternal()
-		if nil == err {
+	if 0 != xls.visibilityFilter&VisibilityFilterSkipHiddenColumns {
+		err, _ = xls.GetColumnsInfo(xls.iteratorSheetId)
+	}
+	for nil == err {
+		// if row we have scanned is not next to previously returned, just increase "previouslyReturned" counter and imply empty row
+		if xls.iteratorScannedRowNum > xls.iteratorRowNum {
 			xls.iteratorRowNum++
+		} else {
+			err = xls.scanInternal()
+			if nil == err {
+				xls.iteratorRowNum++
+			}
+		}
+		if nil != err || !xls.visibilityFilter.isRowFiltered(xls.GetScannedRowInfo()) {
+			break
 		}
 	}
 	xls.iteratorLastError = err
@@ -295,7 +439,518 @@ func (xlsx *xmlHandle) GetScanned() []string {
 	if xlsx.iteratorScannedRowNum > xlsx.iteratorRowNum {
 		return []string{}
 	}
-	return xlsx.iteratorScannedData
+	return pickStrings(xlsx.iteratorScannedData, xlsx.visibleColumnIds(len(xlsx.iteratorScannedData)))
+}
+
+func (xls *xmlHandle) GetScannedRowNum() int {
+	return xls.iteratorRowNum
+}
+
+func (xls *xmlHandle) GetScannedColumnIds() []int {
+	return scannedColumnIds(xls.visibleColumnIds(len(xls.iteratorScannedData)), len(xls.GetScanned()))
+}
+
+func (xls *xmlHandle) GetScannedRowInfo() TRowInfo {
+	if xls.iteratorScannedRowNum > xls.iteratorRowNum {
+		return TRowInfo{}
+	}
+	return xls.iteratorScannedRowInfo
+}
+
+func (xls *xmlHandle) SetVisibilityFilter(filter TVisibilityFilter) error {
+	xls.visibilityFilter = filter
+	return nil
+}
+
+func (xls *xmlHandle) GetVisibilityFilter() TVisibilityFilter {
+	return xls.visibilityFilter
+}
+
+func (xls *xmlHandle) visibleColumnIds(rowLength int) []int {
+	return xls.visibilityFilter.visibleColumnIds(xls.sheets[xls.iteratorSheetId].columns, rowLength)
+}
+
+// GetColumnsInfo reads <Column> declarations preceding rows, shared stream position is restored afterwards
+func (xls *xmlHandle) GetColumnsInfo(sheetId int) (error, []TColumnInfo) {
+	if sheetId < 0 || sheetId >= len(xls.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	sheet := xls.sheets[sheetId]
+	if nil != sheet.columns {
+		return nil, sheet.columns
+	}
+	position, err := xls.iteratorStreamXML.Seek(0, io.SeekCurrent)
+	if nil != err {
+		return err, nil
+	}
+	defer func() { _, _ = xls.iteratorStreamXML.Seek(position, io.SeekStart) }()
+	_, err = xls.iteratorStreamXML.Seek(sheet.start, io.SeekStart)
+	if nil != err {
+		return fmt.Errorf("seek [%d] failed, some file contents are missing", sheet.start), nil
+	}
+	columns := []TColumnInfo{}
+	columnNum := 0
+	decoder := xml.NewDecoder(xls.iteratorStreamXML)
+	for sheet.start+decoder.InputOffset() <= sheet.stop {
+		offset := sheet.start + decoder.InputOffset()
+		tok, tokenErr := decoder.Token()
+		if io.EOF == tokenErr {
+			break
+		}
+		if tokenErr != nil {
+			return fmt.Errorf("xml token read error in at pos %d: %s", offset, tokenErr.Error()), nil
+		}
+		startTok, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if startTok.Name.Local == "Row" {
+			// columns are declared before rows
+			break
+		}
+		if startTok.Name.Local != "Column" {
+			continue
+		}
+		columnNum++
+		if indexStr, attrExists := findXmlTokenAttrValue(&startTok, "Index"); attrExists {
+			columnNum, err = strconv.Atoi(indexStr)
+			if nil != err {
+				return fmt.Errorf("cannot parse <Column> Index attr at offset %d", offset), nil
+			}
+		}
+		span := 0
+		if spanStr, attrExists := findXmlTokenAttrValue(&startTok, "Span"); attrExists {
+			span, err = strconv.Atoi(spanStr)
+			if nil != err {
+				return fmt.Errorf("cannot parse <Column> Span attr at offset %d", offset), nil
+			}
+		}
+		hidden, _ := findXmlTokenAttrValue(&startTok, "Hidden")
+		columns = setColumnsInfo(columns, columnNum, columnNum+span, TColumnInfo{Hidden: parseXmlBool(hidden)})
+		columnNum += span
+	}
+	sheet.columns = columns
+	return nil, columns
+}
+
+func (xls *xmlHandle) GetScannedHyperlinks() []string {
+	if xls.iteratorScannedRowNum > xls.iteratorRowNum {
+		return []string{}
+	}
+	res := make([]string, len(xls.iteratorScannedData))
+	copy(res, xls.iteratorScannedHyperlinks)
+	return pickStrings(res, xls.visibleColumnIds(len(res)))
+}
+
+// GetComments walks sheet by separate decoder, shared stream position is restored afterwards
+func (xls *xmlHandle) GetComments(sheetId int) (error, map[string]TCellComment) {
+	if sheetId < 0 || sheetId >= len(xls.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	position, err := xls.iteratorStreamXML.Seek(0, io.SeekCurrent)
+	if nil != err {
+		return err, nil
+	}
+	defer func() { _, _ = xls.iteratorStreamXML.Seek(position, io.SeekStart) }()
+	_, err = xls.iteratorStreamXML.Seek(xls.sheets[sheetId].start, io.SeekStart)
+	if nil != err {
+		return fmt.Errorf("seek [%d] failed, some file contents are missing", xls.sheets[sheetId].start), nil
+	}
+	res := make(map[string]TCellComment)
+	decoder := xml.NewDecoder(xls.iteratorStreamXML)
+	rowNum := 0
+	columnNum := 0 // last occupied column, merged cells included
+	cellColumnNum := 0
+	for xls.sheets[sheetId].start+decoder.InputOffset() <= xls.sheets[sheetId].stop {
+		offset := xls.sheets[sheetId].start + decoder.InputOffset()
+		tok, tokenErr := decoder.Token()
+		if io.EOF == tokenErr {
+			break
+		}
+		if tokenErr != nil {
+			return fmt.Errorf("xml token read error in at pos %d: %s", offset, tokenErr.Error()), nil
+		}
+		startTok, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch startTok.Name.Local {
+		case "Row":
+			rowNum++
+			if indexStr, attrExists := findXmlTokenAttrValue(&startTok, "Index"); attrExists {
+				rowNum, err = strconv.Atoi(indexStr)
+				if nil != err {
+					return fmt.Errorf("cannot parse <Row> Index attr at offset %d", offset), nil
+				}
+			}
+			columnNum = 0
+		case "Cell":
+			cellColumnNum = columnNum + 1
+			if indexStr, attrExists := findXmlTokenAttrValue(&startTok, "Index"); attrExists {
+				cellColumnNum, err = strconv.Atoi(indexStr)
+				if nil != err {
+					return fmt.Errorf("cannot parse <Row>#%d<Cell> Index attr at offset %d", rowNum, offset), nil
+				}
+			}
+			columnNum = cellColumnNum
+			if mergeStr, attrExists := findXmlTokenAttrValue(&startTok, "MergeAcross"); attrExists {
+				mergeNum, err := strconv.Atoi(mergeStr)
+				if nil != err {
+					return fmt.Errorf("cannot parse <Row>#%d<Cell>#%d MergeAcross attr at offset %d", rowNum, cellColumnNum, offset), nil
+				}
+				columnNum += mergeNum
+			}
+		case "Comment":
+			author, _ := findXmlTokenAttrValue(&startTok, "Author")
+			err, text := readXmlElementText(decoder)
+			if nil != err {
+				return fmt.Errorf("cannot decode <Comment> at offset %d: %s", offset, err), nil
+			}
+			ref := makeCellAddr(cellColumnNum, rowNum)
+			res[ref] = TCellComment{Ref: ref, Author: author, Text: text}
+		}
+	}
+	return nil, res
+}
+
+// readXmlElementText concatenates all character data of current element including nested formatting tags
+func readXmlElementText(decoder *xml.Decoder) (error, string) {
+	var text strings.Builder
+	for depth := 1; depth > 0; {
+		tok, err := decoder.Token()
+		if nil != err {
+			return err, ""
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			text.Write(tok)
+		}
+	}
+	return nil, text.String()
+}
+
+type rawxmlAutoFilter struct {
+	Range  string                   `xml:"Range,attr"` // R1C1 reference
+	Column []rawxmlAutoFilterColumn `xml:"AutoFilterColumn"`
+}
+
+type rawxmlAutoFilterColumn struct {
+	Index     int                         `xml:"Index,attr"` // 1-based, absent means next to previous column
+	Type      string                      `xml:"Type,attr"`  // "All"/"Blanks"/"NonBlanks"/"Custom"/"Top"/"Bottom"/"TopPercent"/"BottomPercent"
+	Value     string                      `xml:"Value,attr"`
+	Condition []rawxmlAutoFilterCondition `xml:"AutoFilterCondition"`
+	And       *rawxmlAutoFilterConditions `xml:"AutoFilterAnd"`
+	Or        *rawxmlAutoFilterConditions `xml:"AutoFilterOr"`
+}
+
+type rawxmlAutoFilterConditions struct {
+	Condition []rawxmlAutoFilterCondition `xml:"AutoFilterCondition"`
+}
+
+type rawxmlAutoFilterCondition struct {
+	Operator string `xml:"Operator,attr"`
+	Value    string `xml:"Value,attr"`
+}
+
+var rawxmlAutoFilterOperators = map[string]string{
+	"Equals":             "equal",
+	"DoesNotEqual":       "notEqual",
+	"GreaterThan":        "greaterThan",
+	"GreaterThanOrEqual": "greaterThanOrEqual",
+	"LessThan":           "lessThan",
+	"LessThanOrEqual":    "lessThanOrEqual",
+}
+
+var reR1C1Ref = regexp.MustCompile(`^(?:R(\d+))?(?:C(\d+))?$`)
+
+// convertR1C1Range converts absolute R1C1 reference (cell, range, whole rows or columns) to A1 notation,
+// "" is returned for relative or malformed references
+func convertR1C1Range(ref string) string {
+	parts := strings.Split(ref, ":")
+	if len(parts) > 2 {
+		return ""
+	}
+	matches := make([][]string, len(parts))
+	for i, part := range parts {
+		matches[i] = reR1C1Ref.FindStringSubmatch(part)
+		if "" == part || nil == matches[i] {
+			return ""
+		}
+	}
+	coord := func(value string, missing int) int {
+		if "" == value {
+			return missing
+		}
+		res, _ := strconv.Atoi(value)
+		return res
+	}
+	if 1 == len(parts) {
+		if "" != matches[0][1] && "" != matches[0][2] {
+			return makeCellAddr(coord(matches[0][2], 1), coord(matches[0][1], 1))
+		}
+		// whole rows or columns
+		matches = append(matches, matches[0])
+	}
+	x1, y1 := coord(matches[0][2], 1), coord(matches[0][1], 1)
+	x2, y2 := coord(matches[1][2], 16384), coord(matches[1][1], 1048576)
+	return makeCellAddr(x1, y1) + ":" + makeCellAddr(x2, y2)
+}
+
+func (autoFilter *rawxmlAutoFilter) autoFilter() *TAutoFilter {
+	res := &TAutoFilter{Ref: convertR1C1Range(autoFilter.Range), Columns: []TAutoFilterColumn{}}
+	columnNum := 0
+	for _, filterColumn := range autoFilter.Column {
+		columnNum++
+		if filterColumn.Index > 0 {
+			columnNum = filterColumn.Index
+		}
+		column := TAutoFilterColumn{ColumnId: columnNum - 1}
+		switch filterColumn.Type {
+		case "Blanks":
+			column.Values = []string{}
+			column.Blank = true
+		case "NonBlanks":
+			column.Custom = &TCustomFilters{Filters: []TCustomFilter{{Operator: "notEqual", Val: " "}}}
+		case "Top", "Bottom", "TopPercent", "BottomPercent":
+			val, _ := strconv.ParseFloat(filterColumn.Value, 64)
+			column.Top10 = &TTop10Filter{
+				Top:     strings.HasPrefix(filterColumn.Type, "Top"),
+				Percent: strings.HasSuffix(filterColumn.Type, "Percent"),
+				Val:     val,
+			}
+		default:
+			conditions := filterColumn.Condition
+			column.Custom = &TCustomFilters{Filters: []TCustomFilter{}}
+			if nil != filterColumn.And {
+				column.Custom.And = true
+				conditions = append(conditions, filterColumn.And.Condition...)
+			}
+			if nil != filterColumn.Or {
+				conditions = append(conditions, filterColumn.Or.Condition...)
+			}
+			if 0 == len(conditions) {
+				continue
+			}
+			for _, condition := range conditions {
+				operator, ok := rawxmlAutoFilterOperators[condition.Operator]
+				if !ok {
+					operator = condition.Operator
+				}
+				column.Custom.Filters = append(column.Custom.Filters, TCustomFilter{Operator: operator, Val: condition.Value})
+			}
+		}
+		res.Columns = append(res.Columns, column)
+	}
+	return res
+}
+
+// walkSheetTrailer walks sheet elements placed outside of <Table> and <WorksheetOptions> by separate decoder,
+// shared stream position is restored afterwards; handler consumes element and returns true to stop walking
+func (xls *xmlHandle) walkSheetTrailer(sheetId int, handler func(decoder *xml.Decoder, tok *xml.StartElement, offset int64) (error, bool)) error {
+	if sheetId < 0 || sheetId >= len(xls.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId)
+	}
+	sheet := xls.sheets[sheetId]
+	position, err := xls.iteratorStreamXML.Seek(0, io.SeekCurrent)
+	if nil != err {
+		return err
+	}
+	defer func() { _, _ = xls.iteratorStreamXML.Seek(position, io.SeekStart) }()
+	_, err = xls.iteratorStreamXML.Seek(sheet.start, io.SeekStart)
+	if nil != err {
+		return fmt.Errorf("seek [%d] failed, some file contents are missing", sheet.start)
+	}
+	decoder := xml.NewDecoder(xls.iteratorStreamXML)
+	level := 0 // 0=/ 1=/Worksheet
+	for sheet.start+decoder.InputOffset() <= sheet.stop {
+		offset := sheet.start + decoder.InputOffset()
+		tok, tokenErr := decoder.Token()
+		if io.EOF == tokenErr {
+			break
+		}
+		if tokenErr != nil {
+			return fmt.Errorf("xml token read error in at pos %d: %s", offset, tokenErr.Error())
+		}
+		startTok, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if 0 == level {
+			level = 1
+			continue
+		}
+		switch startTok.Name.Local {
+		case "Table", "WorksheetOptions":
+			_ = decoder.Skip()
+		default:
+			err, stop := handler(decoder, &startTok, offset)
+			if nil != err || stop {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (xls *xmlHandle) GetAutoFilter(sheetId int) (error, *TAutoFilter) {
+	var res *TAutoFilter
+	err := xls.walkSheetTrailer(sheetId, func(decoder *xml.Decoder, tok *xml.StartElement, offset int64) (error, bool) {
+		if tok.Name.Local != "AutoFilter" {
+			return decoder.Skip(), false
+		}
+		autoFilter := &rawxmlAutoFilter{}
+		err := decoder.DecodeElement(autoFilter, tok)
+		if nil != err {
+			return fmt.Errorf("cannot decode <AutoFilter> at offset %d: %s", offset, err), true
+		}
+		res = autoFilter.autoFilter()
+		return nil, true
+	})
+	if nil != err {
+		return err, nil
+	}
+	return nil, res
+}
+
+type rawxmlDataValidation struct {
+	Range        string `xml:"Range"`     // comma-separated R1C1 ranges
+	Type         string `xml:"Type"`      // "List"/"Whole"/"Decimal"/"Date"/"Time"/"TextLength"/"Custom", "" for any value
+	Qualifier    string `xml:"Qualifier"` // "Between" (default)/"NotBetween"/"Equal"/"NotEqual"/"Greater"/"GreaterOrEqual"/"Less"/"LessOrEqual"
+	Value        string `xml:"Value"`
+	Min          string `xml:"Min"`
+	Max          string `xml:"Max"`
+	ErrorTitle   string `xml:"ErrorTitle"`
+	ErrorMessage string `xml:"ErrorMessage"`
+}
+
+var rawxmlDataValidationOperators = map[string]string{
+	"Between":        "between",
+	"NotBetween":     "notBetween",
+	"Equal":          "equal",
+	"NotEqual":       "notEqual",
+	"Greater":        "greaterThan",
+	"GreaterOrEqual": "greaterThanOrEqual",
+	"Less":           "lessThan",
+	"LessOrEqual":    "lessThanOrEqual",
+}
+
+func (validation *rawxmlDataValidation) dataValidation() TDataValidation {
+	refs := []string{}
+	for _, ref := range strings.Split(validation.Range, ",") {
+		if ref = convertR1C1Range(strings.TrimSpace(ref)); "" != ref {
+			refs = append(refs, ref)
+		}
+	}
+	validationType := "any"
+	if "" != validation.Type {
+		validationType = strings.ToLower(validation.Type[:1]) + validation.Type[1:]
+	}
+	operator := ""
+	if "any" != validationType && "list" != validationType && "custom" != validationType {
+		operator = "between"
+		if "" != validation.Qualifier {
+			operator = rawxmlDataValidationOperators[validation.Qualifier]
+		}
+	}
+	formula1, formula2 := validation.Value, ""
+	if "between" == operator || "notBetween" == operator {
+		formula1, formula2 = validation.Min, validation.Max
+	}
+	if "list" == validationType {
+		// list source is R1C1 reference, optionally sheet-qualified
+		source := formula1
+		sheetName := ""
+		if pos := strings.LastIndex(source, "!"); pos >= 0 {
+			sheetName, source = source[:pos+1], source[pos+1:]
+		}
+		if ref := convertR1C1Range(source); "" != ref {
+			formula1 = sheetName + ref
+		}
+	}
+	// ignore-blank flag is not decoded, excel default is assumed
+	return makeDataValidation(validationType, operator, true, validation.ErrorTitle, validation.ErrorMessage, strings.Join(refs, " "), formula1, formula2)
+}
+
+func (xls *xmlHandle) GetDataValidations(sheetId int) (error, []TDataValidation) {
+	res := []TDataValidation{}
+	err := xls.walkSheetTrailer(sheetId, func(decoder *xml.Decoder, tok *xml.StartElement, offset int64) (error, bool) {
+		if tok.Name.Local != "DataValidation" {
+			return decoder.Skip(), false
+		}
+		validation := &rawxmlDataValidation{}
+		err := decoder.DecodeElement(validation, tok)
+		if nil != err {
+			return fmt.Errorf("cannot decode <DataValidation> at offset %d: %s", offset, err), true
+		}
+		res = append(res, validation.dataValidation())
+		return nil, false
+	})
+	if nil != err {
+		return err, nil
+	}
+	return nil, res
+}
+
+func (xls *xmlHandle) GetFreezePane(sheetId int) (error, *TFreezePane) {
+	if sheetId < 0 || sheetId >= len(xls.sheets) {
+		return fmt.Errorf("sheet #%d not found", sheetId), nil
+	}
+	return nil, xls.sheets[sheetId].freezePane
+}
+
+func (xls *xmlHandle) SetAutoFilterEvaluation(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("autofilter evaluation is not supported for XML format")
+	}
+	return nil
+}
+
+func (xls *xmlHandle) SetPhoneticRuns(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("phonetic runs are not supported for XML format")
+	}
+	return nil
+}
+
+func (xls *xmlHandle) SetRichText(enabled bool) error {
+	if enabled {
+		return fmt.Errorf("rich text is not supported for XML format")
+	}
+	return nil
+}
+
+func (xls *xmlHandle) GetScannedRichText() [][]TRichTextRun {
+	return make([][]TRichTextRun, len(xls.GetScanned()))
+}
+
+func (xls *xmlHandle) GetScannedErrors() []*TCellError {
+	if xls.iteratorScannedRowNum > xls.iteratorRowNum {
+		return []*TCellError{}
+	}
+	res := make([]*TCellError, len(xls.iteratorScannedData))
+	copy(res, xls.iteratorScannedErrors)
+	return pickCellErrors(res, xls.visibleColumnIds(len(res)))
+}
+
+func (xls *xmlHandle) GetScannedDecimals() []TDecimal {
+	if xls.iteratorScannedRowNum > xls.iteratorRowNum {
+		return []TDecimal{}
+	}
+	res := make([]TDecimal, len(xls.iteratorScannedData))
+	copy(res, xls.iteratorScannedDecimals)
+	return pickDecimals(res, xls.visibleColumnIds(len(res)))
+}
+
+func (xls *xmlHandle) GetScannedStyles() []int {
+	return make([]int, len(xls.GetScanned()))
+}
+
+func (xls *xmlHandle) GetStyle(styleId int) (error, *TCellStyle) {
+	return fmt.Errorf("cell styles are not supported for XML format"), nil
 }
 
 func (xls *xmlHandle) requireScanStream() error {
@@ -317,6 +972,9 @@ func (xls *xmlHandle) scanInternal() error {
 	}
 	//var level byte = 0    // 0=./ 1=./Worksheet 2=./Worksheet/Table  3=./Worksheet/Table/Row  4=./Worksheet/Table/Row/Cell*
 	xls.iteratorScannedData = make([]string, 0, xls.iteratorCapacity)
+	xls.iteratorScannedHyperlinks = []string{}
+	xls.iteratorScannedErrors = nil
+	xls.iteratorScannedDecimals = nil
 	rowIsParsed := false
 	for !rowIsParsed {
 		var tokenErr error
@@ -377,6 +1035,11 @@ func (xls *xmlHandle) scanInternal() error {
 				if iteratorRXSegmentWT == xls.iteratorXMLSegment {
 					xls.iteratorXMLSegment = iteratorRXSegmentWTR
 					xls.iteratorScannedData = make([]string, 0, xls.iteratorCapacity)
+					xls.iteratorScannedHyperlinks = []string{}
+					xls.iteratorScannedErrors = nil
+					xls.iteratorScannedDecimals = nil
+					hidden, _ := findXmlTokenAttrValue(&tok, "Hidden")
+					xls.iteratorScannedRowInfo = TRowInfo{Hidden: parseXmlBool(hidden)}
 					currentRowNumStr, attrExists := findXmlTokenAttrValue(&tok, "Index")
 					if attrExists {
 						attrNum, err := strconv.Atoi(currentRowNumStr)
@@ -420,7 +1083,41 @@ func (xls *xmlHandle) scanInternal() error {
 						for len(xls.iteratorScannedData) < currentColumnNum-1 {
 							xls.iteratorScannedData = append(xls.iteratorScannedData, "")
 						}
-						xls.iteratorScannedData = append(xls.iteratorScannedData, cell.Data)
+						value := cell.Data.Value
+						switch cell.Data.Type {
+						case "DateTime":
+							styleId, _ := findXmlTokenAttrValue(&tok, "StyleID")
+							if formatted, err := xls.formatter.FormatValue(value, strCellTypeDate, xls.getParsedNumFmt(styleId)); nil == err {
+								value = formatted
+							}
+						case "Number":
+							if "" != strings.TrimSpace(value) {
+								for len(xls.iteratorScannedDecimals) < currentColumnNum-1 {
+									xls.iteratorScannedDecimals = append(xls.iteratorScannedDecimals, "")
+								}
+								xls.iteratorScannedDecimals = append(xls.iteratorScannedDecimals, TDecimal(strings.TrimSpace(value)))
+							}
+						case "Boolean":
+							// stored "1"/"0" is kept unless bool rendering is set explicitly
+							if xls.formatter.boolRenderingSet {
+								if formatted, err := xls.formatter.FormatValue(value, strCellTypeBool, nil); nil == err {
+									value = formatted
+								}
+							}
+						case "Error":
+							for len(xls.iteratorScannedErrors) < currentColumnNum-1 {
+								xls.iteratorScannedErrors = append(xls.iteratorScannedErrors, nil)
+							}
+							xls.iteratorScannedErrors = append(xls.iteratorScannedErrors, &TCellError{Code: value})
+							value, _ = xls.formatter.FormatValue(value, strCellTypeError, nil)
+						}
+						xls.iteratorScannedData = append(xls.iteratorScannedData, value)
+					}
+					if hyperlink, attrExists := findXmlTokenAttrValue(&tok, "HRef"); attrExists {
+						for len(xls.iteratorScannedHyperlinks) < currentColumnNum-1 {
+							xls.iteratorScannedHyperlinks = append(xls.iteratorScannedHyperlinks, "")
+						}
+						xls.iteratorScannedHyperlinks = append(xls.iteratorScannedHyperlinks, hyperlink)
 					}
 					for i := 0; i < mergeNum; i++ {
 						xls.iteratorScannedData = append(xls.iteratorScannedData, "")
@@ -0,0 +1,96 @@
+package tablescanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// SpreadsheetML sheet with hidden column B, row 2 is implied by ss:Index of the next row
+const testSpreadsheetML = `<?xml version="1.0"?>
+<?mso-application progid="Excel.Sheet"?>
+<Workbook xmlns="urn:schemas-microsoft-com:office:spreadsheet" xmlns:ss="urn:schemas-microsoft-com:office:spreadsheet">
+ <Worksheet ss:Name="Data">
+  <Table>
+   <Column ss:Index="2" ss:Hidden="1"/>
+   <Row>
+    <Cell><Data ss:Type="Number">1.5</Data></Cell>
+    <Cell><Data ss:Type="Error">#N/A</Data></Cell>
+    <Cell><Data ss:Type="Error">#DIV/0!</Data></Cell>
+   </Row>
+   <Row ss:Index="3">
+    <Cell ss:Index="2"><Data ss:Type="Error">#REF!</Data></Cell>
+    <Cell><Data ss:Type="Number">7</Data></Cell>
+   </Row>
+  </Table>
+ </Worksheet>
+</Workbook>`
+
+func TestXMLScannedErrors(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "errors.xml")
+	if err := os.WriteFile(fileName, []byte(testSpreadsheetML), 0o600); nil != err {
+		t.Fatal(err)
+	}
+	err, scanner := NewTableStream(fileName)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer nowarnCloseCloser(scanner)
+	if err := scanner.SetVisibilityFilter(VisibilityFilterSkipHiddenColumns); nil != err {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		values []string
+		errors []string // empty string for cells without error
+	}{
+		{[]string{"1.5", "#DIV/0!"}, []string{"", CellErrorDivZero}},
+		{[]string{}, []string{}},
+		{[]string{"", "7"}, []string{"", ""}},
+	}
+	for row, expectedRow := range expected {
+		if err := scanner.Scan(); nil != err {
+			t.Fatalf("row %d: %s", row+1, err)
+		}
+		values := scanner.GetScanned()
+		cellErrors := scanner.GetScannedErrors()
+		if len(values) != len(cellErrors) || len(values) != len(scanner.GetScannedDecimals()) {
+			t.Errorf("row %d: %d values, %d errors and %d decimals", row+1, len(values), len(cellErrors), len(scanner.GetScannedDecimals()))
+			continue
+		}
+		if len(values) != len(scanner.GetScannedStyles()) || len(values) != len(scanner.GetScannedRichText()) {
+			t.Errorf("row %d: %d values, %d styles and %d rich texts", row+1, len(values), len(scanner.GetScannedStyles()), len(scanner.GetScannedRichText()))
+		}
+		for i, value := range values {
+			code := ""
+			if nil != cellErrors[i] {
+				code = cellErrors[i].Code
+			}
+			if expectedRow.values[i] != value || expectedRow.errors[i] != code {
+				t.Errorf("row %d cell %d is %q with error %q, expected %q with error %q", row+1, i, value, code, expectedRow.values[i], expectedRow.errors[i])
+			}
+		}
+	}
+}
+
+func TestXMLRewind(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "rewind.xml")
+	if err := os.WriteFile(fileName, []byte(testSpreadsheetML), 0o600); nil != err {
+		t.Fatal(err)
+	}
+	err, scanner := NewTableStream(fileName)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer nowarnCloseCloser(scanner)
+	for nil == scanner.Scan() {
+	}
+	if err := scanner.SetSheetId(0); nil != err {
+		t.Fatal(err)
+	}
+	if err := scanner.Scan(); nil != err {
+		t.Fatal(err)
+	}
+	if row := scanner.GetScanned(); 1 != scanner.GetScannedRowNum() || 3 != len(row) || "1.5" != row[0] {
+		t.Errorf("rewound sheet starts with row %d %q, expected row 1", scanner.GetScannedRowNum(), row)
+	}
+}
@@ -0,0 +1,271 @@
+// Package arrow writes sheet rows as Apache Arrow IPC stream of record batches
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// flatbuffers ids of Schema.fbs and Message.fbs
+const (
+	arrowMetadataV5          = 4
+	arrowHeaderSchema        = 1
+	arrowHeaderRecordBatch   = 3
+	arrowTypeInt             = 2
+	arrowTypeFloatingPoint   = 3
+	arrowTypeUtf8            = 5
+	arrowTypeBool            = 6
+	arrowTypeTimestamp       = 10
+	arrowPrecisionDouble     = 2
+	arrowTimeUnitMillisecond = 1
+	arrowContinuation        = 0xFFFFFFFF
+)
+
+const DefaultBatchRows = 4096
+
+type TOptions struct {
+	tablescanner.TTypedRowOptions
+	BatchRows int // rows per record batch, 0 means DefaultBatchRows
+}
+
+// TWriter buffers at most one record batch, int columns are int64, float columns are float64,
+// dates are timestamp[ms] without time zone
+type TWriter struct {
+	output    io.Writer
+	columns   []tablescanner.TTypedColumn
+	batchRows int
+	builders  []*columnBuilder
+	rows      int
+}
+
+// columnBuilder keeps validity bitmap and values of column in arrow layout
+type columnBuilder struct {
+	columnType tablescanner.TColumnType
+	validity   []byte
+	nullCount  int
+	values     []byte  // fixed width values or bool bitmap
+	offsets    []int32 // utf8 offsets
+}
+
+// NewWriter writes schema message, rows are written by WriteRow() and stream is finished by Close()
+func NewWriter(output io.Writer, columns []tablescanner.TTypedColumn, batchRows int) (error, *TWriter) {
+	if batchRows <= 0 {
+		batchRows = DefaultBatchRows
+	}
+	writer := &TWriter{output: output, columns: columns, batchRows: batchRows}
+	fields := make([]*fbTable, len(columns))
+	for i, column := range columns {
+		typeId, typeTable := arrowType(column.Type)
+		fields[i] = &fbTable{fields: []fbField{
+			fbRef(column.Name),
+			fbBool(true),
+			fbInt8(typeId),
+			fbRef(typeTable),
+			{},
+			fbRef([]*fbTable{}),
+		}}
+	}
+	schema := &fbTable{fields: []fbField{fbInt16(0), fbRef(fields)}}
+	err := writer.writeMessage(arrowHeaderSchema, schema, nil)
+	if nil != err {
+		return err, nil
+	}
+	writer.reset()
+	return nil, writer
+}
+
+func arrowType(columnType tablescanner.TColumnType) (uint8, *fbTable) {
+	switch columnType {
+	case tablescanner.ColumnTypeInt:
+		return arrowTypeInt, &fbTable{fields: []fbField{fbInt32(64), fbBool(true)}}
+	case tablescanner.ColumnTypeFloat:
+		return arrowTypeFloatingPoint, &fbTable{fields: []fbField{fbInt16(arrowPrecisionDouble)}}
+	case tablescanner.ColumnTypeBool:
+		return arrowTypeBool, &fbTable{}
+	case tablescanner.ColumnTypeDate:
+		return arrowTypeTimestamp, &fbTable{fields: []fbField{fbInt16(arrowTimeUnitMillisecond)}}
+	}
+	return arrowTypeUtf8, &fbTable{}
+}
+
+func (writer *TWriter) reset() {
+	writer.rows = 0
+	writer.builders = make([]*columnBuilder, len(writer.columns))
+	for i, column := range writer.columns {
+		writer.builders[i] = &columnBuilder{columnType: column.Type}
+		if tablescanner.ColumnTypeString == column.Type || tablescanner.ColumnTypeEmpty == column.Type {
+			writer.builders[i].offsets = []int32{0}
+		}
+	}
+}
+
+// WriteRow appends values returned by TTypedRowReader.Next(), full batch is written immediately
+func (writer *TWriter) WriteRow(row []interface{}) error {
+	for i, builder := range writer.builders {
+		var value interface{}
+		if i < len(row) {
+			value = row[i]
+		}
+		if err := builder.append(writer.rows, value); nil != err {
+			return fmt.Errorf("column %s: %w", writer.columns[i].Name, err)
+		}
+	}
+	writer.rows++
+	if writer.rows >= writer.batchRows {
+		return writer.flush()
+	}
+	return nil
+}
+
+func setBit(bitmap []byte, index int) []byte {
+	for len(bitmap) <= index/8 {
+		bitmap = append(bitmap, 0)
+	}
+	bitmap[index/8] |= 1 << uint(index%8)
+	return bitmap
+}
+
+func (builder *columnBuilder) append(index int, value interface{}) error {
+	for len(builder.validity) <= index/8 {
+		builder.validity = append(builder.validity, 0)
+	}
+	if nil == value {
+		builder.nullCount++
+	} else {
+		builder.validity = setBit(builder.validity, index)
+	}
+	var scalar [8]byte
+	switch builder.columnType {
+	case tablescanner.ColumnTypeInt:
+		number, _ := value.(int64)
+		binary.LittleEndian.PutUint64(scalar[:], uint64(number))
+		builder.values = append(builder.values, scalar[:]...)
+	case tablescanner.ColumnTypeFloat:
+		number, _ := value.(float64)
+		binary.LittleEndian.PutUint64(scalar[:], math.Float64bits(number))
+		builder.values = append(builder.values, scalar[:]...)
+	case tablescanner.ColumnTypeDate:
+		var milliseconds int64
+		if date, ok := value.(time.Time); ok {
+			milliseconds = date.UnixNano() / int64(time.Millisecond)
+		}
+		binary.LittleEndian.PutUint64(scalar[:], uint64(milliseconds))
+		builder.values = append(builder.values, scalar[:]...)
+	case tablescanner.ColumnTypeBool:
+		for len(builder.values) <= index/8 {
+			builder.values = append(builder.values, 0)
+		}
+		if flag, _ := value.(bool); flag {
+			builder.values = setBit(builder.values, index)
+		}
+	default:
+		text, _ := value.(string)
+		if int64(len(builder.values))+int64(len(text)) > math.MaxInt32 {
+			return fmt.Errorf("utf8 data of record batch exceeds 2GB, use smaller batches")
+		}
+		builder.values = append(builder.values, text...)
+		builder.offsets = append(builder.offsets, int32(len(builder.values)))
+	}
+	return nil
+}
+
+// flush writes buffered rows as record batch
+func (writer *TWriter) flush() error {
+	if 0 == writer.rows {
+		return nil
+	}
+	var nodes, buffers, body []byte
+	addBuffer := func(data []byte) {
+		var buffer [16]byte
+		binary.LittleEndian.PutUint64(buffer[:], uint64(len(body)))
+		binary.LittleEndian.PutUint64(buffer[8:], uint64(len(data)))
+		buffers = append(buffers, buffer[:]...)
+		body = append(body, data...)
+		for 0 != len(body)%8 {
+			body = append(body, 0)
+		}
+	}
+	for _, builder := range writer.builders {
+		var node [16]byte
+		binary.LittleEndian.PutUint64(node[:], uint64(writer.rows))
+		binary.LittleEndian.PutUint64(node[8:], uint64(builder.nullCount))
+		nodes = append(nodes, node[:]...)
+		addBuffer(builder.validity)
+		if nil != builder.offsets {
+			offsets := make([]byte, 4*len(builder.offsets))
+			for i, offset := range builder.offsets {
+				binary.LittleEndian.PutUint32(offsets[4*i:], uint32(offset))
+			}
+			addBuffer(offsets)
+		}
+		addBuffer(builder.values)
+	}
+	batch := &fbTable{fields: []fbField{
+		fbInt64(int64(writer.rows)),
+		fbRef(fbStructs{count: len(writer.builders), align: 8, data: nodes}),
+		fbRef(fbStructs{count: len(buffers) / 16, align: 8, data: buffers}),
+	}}
+	err := writer.writeMessage(arrowHeaderRecordBatch, batch, body)
+	writer.reset()
+	return err
+}
+
+// writeMessage writes encapsulated message: continuation, metadata size, Message flatbuffer and body
+func (writer *TWriter) writeMessage(headerType uint8, header *fbTable, body []byte) error {
+	metadata := fbFinish(&fbTable{fields: []fbField{
+		fbInt16(arrowMetadataV5),
+		fbInt8(headerType),
+		fbRef(header),
+		fbInt64(int64(len(body))),
+	}})
+	prefix := make([]byte, 8)
+	binary.LittleEndian.PutUint32(prefix, arrowContinuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(metadata)))
+	for _, data := range [][]byte{prefix, metadata, body} {
+		if _, err := writer.output.Write(data); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the last batch and end-of-stream marker, underlying writer is not closed
+func (writer *TWriter) Close() error {
+	if err := writer.flush(); nil != err {
+		return err
+	}
+	end := make([]byte, 8)
+	binary.LittleEndian.PutUint32(end, arrowContinuation)
+	_, err := writer.output.Write(end)
+	return err
+}
+
+// WriteSheet streams sheet rows typed by tablescanner.NewTypedRowReader()
+func WriteSheet(output io.Writer, scanner tablescanner.ITableDocumentScanner, sheetId int, options TOptions) error {
+	err, reader := tablescanner.NewTypedRowReader(scanner, sheetId, options.TTypedRowOptions)
+	if nil != err {
+		return err
+	}
+	err, writer := NewWriter(output, reader.Columns(), options.BatchRows)
+	if nil != err {
+		return err
+	}
+	for {
+		err, row := reader.Next()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err
+		}
+		if err = writer.WriteRow(row); nil != err {
+			return err
+		}
+	}
+	return writer.Close()
+}
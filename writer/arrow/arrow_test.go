@@ -0,0 +1,308 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// fbReader reads flatbuffers tables written by fbFinish, it is independent from fbBuilder layout
+type fbReader struct {
+	t   *testing.T
+	buf []byte
+}
+
+func (reader fbReader) u16(pos int) int {
+	return int(binary.LittleEndian.Uint16(reader.buf[pos:]))
+}
+
+func (reader fbReader) u32(pos int) int {
+	return int(binary.LittleEndian.Uint32(reader.buf[pos:]))
+}
+
+// field returns position of field value in table, -1 when field is absent
+func (reader fbReader) field(table int, id int) int {
+	vtable := table - int(int32(binary.LittleEndian.Uint32(reader.buf[table:])))
+	if 4+2*id >= reader.u16(vtable) {
+		return -1
+	}
+	offset := reader.u16(vtable + 4 + 2*id)
+	if 0 == offset {
+		return -1
+	}
+	return table + offset
+}
+
+func (reader fbReader) ref(table int, id int) int {
+	pos := reader.field(table, id)
+	if pos < 0 {
+		reader.t.Fatalf("field #%d of table at %d is absent", id, table)
+	}
+	return pos + reader.u32(pos)
+}
+
+func (reader fbReader) scalar(table int, id int, size int) int64 {
+	pos := reader.field(table, id)
+	if pos < 0 {
+		return 0
+	}
+	switch size {
+	case 1:
+		return int64(reader.buf[pos])
+	case 2:
+		return int64(int16(reader.u16(pos)))
+	case 4:
+		return int64(int32(reader.u32(pos)))
+	}
+	return int64(binary.LittleEndian.Uint64(reader.buf[pos:]))
+}
+
+func (reader fbReader) str(table int, id int) string {
+	pos := reader.ref(table, id)
+	return string(reader.buf[pos+4 : pos+4+reader.u32(pos)])
+}
+
+// tables returns positions of tables referred by vector field
+func (reader fbReader) tables(table int, id int) []int {
+	pos := reader.ref(table, id)
+	res := make([]int, reader.u32(pos))
+	for i := range res {
+		element := pos + 4 + 4*i
+		res[i] = element + reader.u32(element)
+	}
+	return res
+}
+
+// structs returns vector of structs of two int64 fields (FieldNode and Buffer)
+func (reader fbReader) structs(table int, id int) [][2]int64 {
+	pos := reader.ref(table, id)
+	if 0 != (pos+4)%8 {
+		reader.t.Errorf("struct vector at %d is not aligned to 8 bytes", pos)
+	}
+	res := make([][2]int64, reader.u32(pos))
+	for i := range res {
+		res[i][0] = int64(binary.LittleEndian.Uint64(reader.buf[pos+4+16*i:]))
+		res[i][1] = int64(binary.LittleEndian.Uint64(reader.buf[pos+4+16*i+8:]))
+	}
+	return res
+}
+
+type testField struct {
+	name     string
+	nullable bool
+	typeId   int64
+	param    int64 // bit width, precision or time unit
+}
+
+type testMessage struct {
+	headerType int64
+	reader     fbReader
+	header     int
+	body       []byte
+}
+
+// readMessages splits IPC stream to encapsulated messages up to end-of-stream marker
+func readMessages(t *testing.T, stream []byte) []testMessage {
+	res := []testMessage{}
+	for {
+		if len(stream) < 8 || arrowContinuation != binary.LittleEndian.Uint32(stream) {
+			t.Fatalf("continuation marker is expected")
+		}
+		size := int(binary.LittleEndian.Uint32(stream[4:]))
+		if 0 == size {
+			if 8 != len(stream) {
+				t.Errorf("%d bytes follow end-of-stream marker", len(stream)-8)
+			}
+			return res
+		}
+		if 0 != size%8 {
+			t.Errorf("metadata size %d is not multiple of 8", size)
+		}
+		reader := fbReader{t: t, buf: stream[8 : 8+size]}
+		message := reader.u32(0)
+		if arrowMetadataV5 != reader.scalar(message, 0, 2) {
+			t.Errorf("metadata version is %d", reader.scalar(message, 0, 2))
+		}
+		bodySize := int(reader.scalar(message, 3, 8))
+		res = append(res, testMessage{
+			headerType: reader.scalar(message, 1, 1),
+			reader:     reader,
+			header:     reader.ref(message, 2),
+			body:       stream[8+size : 8+size+bodySize],
+		})
+		stream = stream[8+size+bodySize:]
+	}
+}
+
+func readSchema(t *testing.T, message testMessage) []testField {
+	if arrowHeaderSchema != message.headerType {
+		t.Fatalf("first message is %d, expected schema", message.headerType)
+	}
+	reader := message.reader
+	res := []testField{}
+	for _, field := range reader.tables(message.header, 1) {
+		typeId := reader.scalar(field, 2, 1)
+		typeTable := reader.ref(field, 3)
+		param := int64(0)
+		switch typeId {
+		case arrowTypeInt:
+			param = reader.scalar(typeTable, 0, 4)
+			if 1 != reader.scalar(typeTable, 1, 1) {
+				t.Errorf("int column %s is unsigned", reader.str(field, 0))
+			}
+		case arrowTypeFloatingPoint, arrowTypeTimestamp:
+			param = reader.scalar(typeTable, 0, 2)
+		}
+		res = append(res, testField{name: reader.str(field, 0), nullable: 1 == reader.scalar(field, 1, 1), typeId: typeId, param: param})
+	}
+	return res
+}
+
+func isSet(bitmap []byte, index int) bool {
+	return 0 != bitmap[index/8]&(1<<uint(index%8))
+}
+
+// readBatch decodes record batch to rows, values of null cells are nil
+func readBatch(t *testing.T, message testMessage, fields []testField) [][]interface{} {
+	if arrowHeaderRecordBatch != message.headerType {
+		t.Fatalf("message is %d, expected record batch", message.headerType)
+	}
+	reader := message.reader
+	length := int(reader.scalar(message.header, 0, 8))
+	nodes := reader.structs(message.header, 1)
+	buffers := reader.structs(message.header, 2)
+	if len(fields) != len(nodes) {
+		t.Fatalf("batch has %d nodes, expected %d", len(nodes), len(fields))
+	}
+	buffer := func() []byte {
+		if 0 == len(buffers) {
+			t.Fatalf("buffers are exhausted")
+		}
+		offset, size := buffers[0][0], buffers[0][1]
+		buffers = buffers[1:]
+		if 0 != offset%8 {
+			t.Errorf("buffer offset %d is not aligned to 8 bytes", offset)
+		}
+		return message.body[offset : offset+size]
+	}
+	rows := make([][]interface{}, length)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(fields))
+	}
+	for column, field := range fields {
+		if int64(length) != nodes[column][0] {
+			t.Errorf("node of %s has length %d, expected %d", field.name, nodes[column][0], length)
+		}
+		validity := buffer()
+		var offsets []byte
+		if arrowTypeUtf8 == field.typeId {
+			offsets = buffer()
+		}
+		values := buffer()
+		nulls := int64(0)
+		for row := 0; row < length; row++ {
+			if !isSet(validity, row) {
+				nulls++
+				continue
+			}
+			var value interface{}
+			switch field.typeId {
+			case arrowTypeInt:
+				value = int64(binary.LittleEndian.Uint64(values[8*row:]))
+			case arrowTypeFloatingPoint:
+				value = math.Float64frombits(binary.LittleEndian.Uint64(values[8*row:]))
+			case arrowTypeTimestamp:
+				value = time.Unix(0, int64(binary.LittleEndian.Uint64(values[8*row:]))*int64(time.Millisecond)).UTC()
+			case arrowTypeBool:
+				value = isSet(values, row)
+			case arrowTypeUtf8:
+				value = string(values[binary.LittleEndian.Uint32(offsets[4*row:]):binary.LittleEndian.Uint32(offsets[4*row+4:])])
+			}
+			rows[row][column] = value
+		}
+		if nulls != nodes[column][1] {
+			t.Errorf("node of %s has %d nulls, expected %d", field.name, nodes[column][1], nulls)
+		}
+	}
+	if 0 != len(buffers) {
+		t.Errorf("%d buffers are left", len(buffers))
+	}
+	return rows
+}
+
+func TestWriter(t *testing.T) {
+	columns := []tablescanner.TTypedColumn{
+		{Name: "id", Type: tablescanner.ColumnTypeInt},
+		{Name: "price", Type: tablescanner.ColumnTypeFloat},
+		{Name: "ok", Type: tablescanner.ColumnTypeBool},
+		{Name: "name", Type: tablescanner.ColumnTypeString},
+		{Name: "day", Type: tablescanner.ColumnTypeDate},
+	}
+	day := time.Date(2024, 2, 29, 13, 45, 10, 0, time.UTC)
+	rows := [][]interface{}{
+		{int64(1), 1.5, true, "a", day},
+		{nil, nil, nil, nil, nil},
+		{int64(-3), -2.25, false, "ĉ", day.AddDate(-100, 0, 0)},
+		{int64(math.MaxInt64), 0.0, true, "", nil},
+		{int64(5), nil, true, "long text", day.Add(time.Millisecond)},
+	}
+	stream := &bytes.Buffer{}
+	err, writer := NewWriter(stream, columns, 2)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); nil != err {
+		t.Fatal(err)
+	}
+	messages := readMessages(t, stream.Bytes())
+	fields := readSchema(t, messages[0])
+	expectedFields := []testField{
+		{"id", true, arrowTypeInt, 64},
+		{"price", true, arrowTypeFloatingPoint, arrowPrecisionDouble},
+		{"ok", true, arrowTypeBool, 0},
+		{"name", true, arrowTypeUtf8, 0},
+		{"day", true, arrowTypeTimestamp, arrowTimeUnitMillisecond},
+	}
+	if !reflect.DeepEqual(expectedFields, fields) {
+		t.Fatalf("schema is %v, expected %v", fields, expectedFields)
+	}
+	// 5 rows by 2 rows per batch
+	if 4 != len(messages) {
+		t.Fatalf("stream has %d messages, expected schema and 3 batches", len(messages))
+	}
+	decoded := [][]interface{}{}
+	for _, message := range messages[1:] {
+		decoded = append(decoded, readBatch(t, message, fields)...)
+	}
+	if !reflect.DeepEqual(rows, decoded) {
+		t.Errorf("decoded rows are\n%v\nexpected\n%v", decoded, rows)
+	}
+}
+
+func TestWriterWithoutRows(t *testing.T) {
+	stream := &bytes.Buffer{}
+	err, writer := NewWriter(stream, []tablescanner.TTypedColumn{{Name: "id", Type: tablescanner.ColumnTypeInt}}, 0)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); nil != err {
+		t.Fatal(err)
+	}
+	messages := readMessages(t, stream.Bytes())
+	if 1 != len(messages) {
+		t.Fatalf("stream has %d messages, expected schema only", len(messages))
+	}
+	if fields := readSchema(t, messages[0]); 1 != len(fields) || "id" != fields[0].name {
+		t.Errorf("schema is %v", fields)
+	}
+}
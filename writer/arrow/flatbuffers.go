@@ -0,0 +1,158 @@
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// fbTable is flatbuffers table, fields are indexed by field id and absent fields are zero values
+type fbTable struct {
+	fields []fbField
+}
+
+type fbField struct {
+	scalar []byte      // little-endian inline value
+	ref    interface{} // *fbTable, string, []*fbTable or fbStructs
+}
+
+// fbStructs is vector of fixed-size structs
+type fbStructs struct {
+	count int
+	align int
+	data  []byte
+}
+
+func fbInt8(value uint8) fbField {
+	return fbField{scalar: []byte{value}}
+}
+
+func fbBool(value bool) fbField {
+	if value {
+		return fbInt8(1)
+	}
+	return fbInt8(0)
+}
+
+func fbInt16(value int16) fbField {
+	scalar := make([]byte, 2)
+	binary.LittleEndian.PutUint16(scalar, uint16(value))
+	return fbField{scalar: scalar}
+}
+
+func fbInt32(value int32) fbField {
+	scalar := make([]byte, 4)
+	binary.LittleEndian.PutUint32(scalar, uint32(value))
+	return fbField{scalar: scalar}
+}
+
+func fbInt64(value int64) fbField {
+	scalar := make([]byte, 8)
+	binary.LittleEndian.PutUint64(scalar, uint64(value))
+	return fbField{scalar: scalar}
+}
+
+func fbRef(ref interface{}) fbField {
+	return fbField{ref: ref}
+}
+
+// fbBuilder lays flatbuffer out front to back: vtable precedes its table and referenced objects follow
+// the referencing field, so unsigned offsets always point forward
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbFinish serializes root table, result is padded to 8 bytes
+func fbFinish(root *fbTable) []byte {
+	builder := &fbBuilder{buf: make([]byte, 4)}
+	rootPos := builder.writeTable(root)
+	binary.LittleEndian.PutUint32(builder.buf, uint32(rootPos))
+	builder.pad(8)
+	return builder.buf
+}
+
+func (builder *fbBuilder) pad(align int) {
+	for 0 != len(builder.buf)%align {
+		builder.buf = append(builder.buf, 0)
+	}
+}
+
+func (builder *fbBuilder) reserve(size int) int {
+	pos := len(builder.buf)
+	builder.buf = append(builder.buf, make([]byte, size)...)
+	return pos
+}
+
+func (builder *fbBuilder) writeTable(table *fbTable) int {
+	offsets := make([]int, len(table.fields))
+	size := 4 // soffset to vtable
+	align := 4
+	for i, field := range table.fields {
+		fieldSize := len(field.scalar)
+		if nil != field.ref {
+			fieldSize = 4
+		}
+		if 0 == fieldSize {
+			continue
+		}
+		size = (size + fieldSize - 1) / fieldSize * fieldSize
+		offsets[i] = size
+		size += fieldSize
+		if fieldSize > align {
+			align = fieldSize
+		}
+	}
+	builder.pad(2)
+	vtablePos := builder.reserve(4 + 2*len(offsets))
+	binary.LittleEndian.PutUint16(builder.buf[vtablePos:], uint16(4+2*len(offsets)))
+	binary.LittleEndian.PutUint16(builder.buf[vtablePos+2:], uint16(size))
+	for i, offset := range offsets {
+		binary.LittleEndian.PutUint16(builder.buf[vtablePos+4+2*i:], uint16(offset))
+	}
+	builder.pad(align)
+	tablePos := builder.reserve(size)
+	binary.LittleEndian.PutUint32(builder.buf[tablePos:], uint32(int32(tablePos-vtablePos)))
+	for i, field := range table.fields {
+		if nil != field.scalar {
+			copy(builder.buf[tablePos+offsets[i]:], field.scalar)
+		}
+	}
+	for i, field := range table.fields {
+		if nil != field.ref {
+			builder.patchOffset(tablePos+offsets[i], builder.writeObject(field.ref))
+		}
+	}
+	return tablePos
+}
+
+func (builder *fbBuilder) writeObject(object interface{}) int {
+	switch typed := object.(type) {
+	case *fbTable:
+		return builder.writeTable(typed)
+	case string:
+		builder.pad(4)
+		pos := builder.reserve(4)
+		binary.LittleEndian.PutUint32(builder.buf[pos:], uint32(len(typed)))
+		builder.buf = append(append(builder.buf, typed...), 0)
+		return pos
+	case []*fbTable:
+		builder.pad(4)
+		pos := builder.reserve(4 + 4*len(typed))
+		binary.LittleEndian.PutUint32(builder.buf[pos:], uint32(len(typed)))
+		for i, table := range typed {
+			builder.patchOffset(pos+4+4*i, builder.writeTable(table))
+		}
+		return pos
+	case fbStructs:
+		for 0 != (len(builder.buf)+4)%typed.align {
+			builder.buf = append(builder.buf, 0)
+		}
+		pos := builder.reserve(4)
+		binary.LittleEndian.PutUint32(builder.buf[pos:], uint32(typed.count))
+		builder.buf = append(builder.buf, typed.data...)
+		return pos
+	}
+	panic("unsupported flatbuffers object")
+}
+
+func (builder *fbBuilder) patchOffset(fieldPos int, targetPos int) {
+	binary.LittleEndian.PutUint32(builder.buf[fieldPos:], uint32(targetPos-fieldPos))
+}
@@ -0,0 +1,91 @@
+// Package jsonl writes sheet rows as JSON Lines objects keyed by header names
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// TWriter writes one json object per row, keys keep column order, dates are RFC 3339 strings
+type TWriter struct {
+	output *bufio.Writer
+	keys   [][]byte // json encoded column names
+}
+
+func NewWriter(output io.Writer, columns []tablescanner.TTypedColumn) (error, *TWriter) {
+	writer := &TWriter{output: bufio.NewWriter(output), keys: make([][]byte, len(columns))}
+	for i, column := range columns {
+		key, err := json.Marshal(column.Name)
+		if nil != err {
+			return err, nil
+		}
+		writer.keys[i] = key
+	}
+	return nil, writer
+}
+
+// WriteRow writes values returned by TTypedRowReader.Next(), nil and non-finite numbers are written as null
+func (writer *TWriter) WriteRow(row []interface{}) error {
+	_ = writer.output.WriteByte('{')
+	for i, key := range writer.keys {
+		if i > 0 {
+			_ = writer.output.WriteByte(',')
+		}
+		_, _ = writer.output.Write(key)
+		_ = writer.output.WriteByte(':')
+		var value interface{}
+		if i < len(row) {
+			value = row[i]
+		}
+		switch typed := value.(type) {
+		case float64:
+			if math.IsInf(typed, 0) || math.IsNaN(typed) {
+				value = nil
+			}
+		case time.Time:
+			value = typed.Format(time.RFC3339Nano)
+		}
+		encoded, err := json.Marshal(value)
+		if nil != err {
+			return err
+		}
+		_, _ = writer.output.Write(encoded)
+	}
+	_, _ = writer.output.WriteString("}\n")
+	return nil
+}
+
+// Close flushes buffered output, underlying writer is not closed
+func (writer *TWriter) Close() error {
+	return writer.output.Flush()
+}
+
+// WriteSheet streams sheet rows typed by tablescanner.NewTypedRowReader()
+func WriteSheet(output io.Writer, scanner tablescanner.ITableDocumentScanner, sheetId int, options tablescanner.TTypedRowOptions) error {
+	err, reader := tablescanner.NewTypedRowReader(scanner, sheetId, options)
+	if nil != err {
+		return err
+	}
+	err, writer := NewWriter(output, reader.Columns())
+	if nil != err {
+		return err
+	}
+	for {
+		err, row := reader.Next()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err
+		}
+		if err = writer.WriteRow(row); nil != err {
+			return err
+		}
+	}
+	return writer.Close()
+}
@@ -0,0 +1,137 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// readLines decodes json objects line by line, keys are returned in written order
+func readLines(t *testing.T, output []byte) ([][]string, [][]interface{}) {
+	keys := [][]string{}
+	values := [][]interface{}{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
+		decoder.UseNumber()
+		if token, err := decoder.Token(); nil != err || json.Delim('{') != token {
+			t.Fatalf("line %q is not json object", scanner.Text())
+		}
+		lineKeys := []string{}
+		lineValues := []interface{}{}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if nil != err {
+				t.Fatalf("line %q: %s", scanner.Text(), err)
+			}
+			var value interface{}
+			if err := decoder.Decode(&value); nil != err {
+				t.Fatalf("line %q: %s", scanner.Text(), err)
+			}
+			lineKeys = append(lineKeys, token.(string))
+			lineValues = append(lineValues, value)
+		}
+		if token, err := decoder.Token(); nil != err || json.Delim('}') != token || decoder.More() {
+			t.Fatalf("line %q has trailing data", scanner.Text())
+		}
+		keys = append(keys, lineKeys)
+		values = append(values, lineValues)
+	}
+	return keys, values
+}
+
+func TestWriter(t *testing.T) {
+	columns := []tablescanner.TTypedColumn{
+		{Name: "id", Type: tablescanner.ColumnTypeInt},
+		{Name: "price", Type: tablescanner.ColumnTypeFloat},
+		{Name: "ok", Type: tablescanner.ColumnTypeBool},
+		{Name: "name \"quoted\"", Type: tablescanner.ColumnTypeString},
+		{Name: "day", Type: tablescanner.ColumnTypeDate},
+	}
+	day := time.Date(2024, 2, 29, 13, 45, 10, 500000000, time.UTC)
+	rows := [][]interface{}{
+		{int64(math.MaxInt64), 1.5, true, "a\nb", day},
+		{nil, nil, nil, nil, nil},
+		{int64(-3), math.NaN(), false, "", day.AddDate(-100, 0, 0)},
+		{int64(0), math.Inf(-1)}, // short row is completed by nulls
+	}
+	output := &bytes.Buffer{}
+	err, writer := NewWriter(output, columns)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); nil != err {
+		t.Fatal(err)
+	}
+	keys, values := readLines(t, output.Bytes())
+	expectedKeys := []string{"id", "price", "ok", "name \"quoted\"", "day"}
+	for i := range keys {
+		if !reflect.DeepEqual(expectedKeys, keys[i]) {
+			t.Errorf("line %d has keys %q, expected %q", i+1, keys[i], expectedKeys)
+		}
+	}
+	expected := [][]interface{}{
+		{json.Number("9223372036854775807"), json.Number("1.5"), true, "a\nb", "2024-02-29T13:45:10.5Z"},
+		{nil, nil, nil, nil, nil},
+		{json.Number("-3"), nil, false, "", "1924-02-29T13:45:10.5Z"},
+		{json.Number("0"), nil, nil, nil, nil},
+	}
+	if !reflect.DeepEqual(expected, values) {
+		t.Errorf("decoded lines are\n%v\nexpected\n%v", values, expected)
+	}
+	if parsed, err := time.Parse(time.RFC3339, values[0][4].(string)); nil != err || !parsed.Equal(day) {
+		t.Errorf("date %v is not RFC 3339 of %s: %v", values[0][4], day, err)
+	}
+}
+
+// SpreadsheetML sheet with header row, empty and error cells
+const testSheet = `<?xml version="1.0"?>
+<?mso-application progid="Excel.Sheet"?>
+<Workbook xmlns="urn:schemas-microsoft-com:office:spreadsheet" xmlns:ss="urn:schemas-microsoft-com:office:spreadsheet">
+ <Worksheet ss:Name="Data">
+  <Table>
+   <Row><Cell><Data ss:Type="String">amount</Data></Cell><Cell><Data ss:Type="String">note</Data></Cell><Cell><Data ss:Type="String">day</Data></Cell></Row>
+   <Row><Cell><Data ss:Type="Number">1.25</Data></Cell><Cell><Data ss:Type="String">first</Data></Cell><Cell><Data ss:Type="DateTime">2024-03-01T00:00:00.000</Data></Cell></Row>
+   <Row><Cell><Data ss:Type="Error">#DIV/0!</Data></Cell><Cell ss:Index="3"><Data ss:Type="DateTime">2024-03-02T12:30:00.000</Data></Cell></Row>
+  </Table>
+ </Worksheet>
+</Workbook>`
+
+func TestWriteSheet(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "sheet.xml")
+	if err := os.WriteFile(fileName, []byte(testSheet), 0o600); nil != err {
+		t.Fatal(err)
+	}
+	err, scanner := tablescanner.NewTableStream(fileName)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer scanner.Close()
+	output := &bytes.Buffer{}
+	if err := WriteSheet(output, scanner, 0, tablescanner.TTypedRowOptions{HeaderRows: 1}); nil != err {
+		t.Fatal(err)
+	}
+	keys, values := readLines(t, output.Bytes())
+	expectedKeys := [][]string{{"amount", "note", "day"}, {"amount", "note", "day"}}
+	expected := [][]interface{}{
+		{json.Number("1.25"), "first", "2024-03-01T00:00:00Z"},
+		{nil, nil, "2024-03-02T12:30:00Z"},
+	}
+	if !reflect.DeepEqual(expectedKeys, keys) || !reflect.DeepEqual(expected, values) {
+		t.Errorf("sheet is written as %q %v, expected %q %v", keys, values, expectedKeys, expected)
+	}
+}
@@ -0,0 +1,301 @@
+// Package parquet writes sheet rows as Apache Parquet file of uncompressed PLAIN encoded row groups
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+// parquet.thrift enum values
+const (
+	parquetBoolean           = 0
+	parquetInt64             = 2
+	parquetDouble            = 5
+	parquetByteArray         = 6
+	parquetOptional          = 1
+	parquetConvertedUTF8     = 0
+	parquetEncodingPlain     = 0
+	parquetEncodingRLE       = 3
+	parquetPageData          = 0
+	parquetCodecUncompressed = 0
+)
+
+const parquetMagic = "PAR1"
+
+const DefaultRowGroupRows = 65536
+
+const createdBy = "golang-tablescanner"
+
+type TOptions struct {
+	tablescanner.TTypedRowOptions
+	RowGroupRows int // rows per row group, 0 means DefaultRowGroupRows
+}
+
+// TWriter buffers at most one row group, every column chunk is single data page;
+// int columns are INT64, float columns are DOUBLE, dates are INT64 TIMESTAMP(MILLIS) not adjusted to UTC
+type TWriter struct {
+	output       io.Writer
+	offset       int64
+	columns      []tablescanner.TTypedColumn
+	rowGroupRows int
+	chunks       []*columnChunk
+	rows         int
+	totalRows    int64
+	rowGroups    []rowGroupMeta
+}
+
+// columnChunk keeps definition levels and PLAIN encoded non-null values of column
+type columnChunk struct {
+	columnType tablescanner.TColumnType
+	defined    []byte // bit-packed definition levels
+	values     []byte
+	boolCount  int
+}
+
+type columnChunkMeta struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+type rowGroupMeta struct {
+	columns []columnChunkMeta
+	size    int64
+	rows    int64
+}
+
+// NewWriter writes file header, rows are written by WriteRow() and file footer is written by Close()
+func NewWriter(output io.Writer, columns []tablescanner.TTypedColumn, rowGroupRows int) (error, *TWriter) {
+	if rowGroupRows <= 0 {
+		rowGroupRows = DefaultRowGroupRows
+	}
+	if rowGroupRows > math.MaxInt32 {
+		return fmt.Errorf("row group of %d rows exceeds %d rows", rowGroupRows, math.MaxInt32), nil
+	}
+	writer := &TWriter{output: output, columns: columns, rowGroupRows: rowGroupRows}
+	if err := writer.write([]byte(parquetMagic)); nil != err {
+		return err, nil
+	}
+	writer.reset()
+	return nil, writer
+}
+
+func (writer *TWriter) write(data []byte) error {
+	written, err := writer.output.Write(data)
+	writer.offset += int64(written)
+	return err
+}
+
+func (writer *TWriter) reset() {
+	writer.rows = 0
+	writer.chunks = make([]*columnChunk, len(writer.columns))
+	for i, column := range writer.columns {
+		writer.chunks[i] = &columnChunk{columnType: column.Type}
+	}
+}
+
+func physicalType(columnType tablescanner.TColumnType) int32 {
+	switch columnType {
+	case tablescanner.ColumnTypeInt, tablescanner.ColumnTypeDate:
+		return parquetInt64
+	case tablescanner.ColumnTypeFloat:
+		return parquetDouble
+	case tablescanner.ColumnTypeBool:
+		return parquetBoolean
+	}
+	return parquetByteArray
+}
+
+// WriteRow appends values returned by TTypedRowReader.Next(), full row group is written immediately
+func (writer *TWriter) WriteRow(row []interface{}) error {
+	for i, chunk := range writer.chunks {
+		var value interface{}
+		if i < len(row) {
+			value = row[i]
+		}
+		chunk.append(writer.rows, value)
+	}
+	writer.rows++
+	if writer.rows >= writer.rowGroupRows {
+		return writer.flush()
+	}
+	return nil
+}
+
+func (chunk *columnChunk) append(index int, value interface{}) {
+	if 0 == index%8 {
+		chunk.defined = append(chunk.defined, 0)
+	}
+	if nil == value {
+		return
+	}
+	chunk.defined[index/8] |= 1 << uint(index%8)
+	var scalar [8]byte
+	switch chunk.columnType {
+	case tablescanner.ColumnTypeInt:
+		number, _ := value.(int64)
+		binary.LittleEndian.PutUint64(scalar[:], uint64(number))
+		chunk.values = append(chunk.values, scalar[:]...)
+	case tablescanner.ColumnTypeFloat:
+		number, _ := value.(float64)
+		binary.LittleEndian.PutUint64(scalar[:], math.Float64bits(number))
+		chunk.values = append(chunk.values, scalar[:]...)
+	case tablescanner.ColumnTypeDate:
+		date, _ := value.(time.Time)
+		binary.LittleEndian.PutUint64(scalar[:], uint64(date.UnixNano()/int64(time.Millisecond)))
+		chunk.values = append(chunk.values, scalar[:]...)
+	case tablescanner.ColumnTypeBool:
+		if 0 == chunk.boolCount%8 {
+			chunk.values = append(chunk.values, 0)
+		}
+		if flag, _ := value.(bool); flag {
+			chunk.values[chunk.boolCount/8] |= 1 << uint(chunk.boolCount%8)
+		}
+		chunk.boolCount++
+	default:
+		text, _ := value.(string)
+		binary.LittleEndian.PutUint32(scalar[:], uint32(len(text)))
+		chunk.values = append(append(chunk.values, scalar[:4]...), text...)
+	}
+}
+
+// flush writes buffered rows as row group
+func (writer *TWriter) flush() error {
+	if 0 == writer.rows {
+		return nil
+	}
+	rowGroup := rowGroupMeta{rows: int64(writer.rows)}
+	for _, chunk := range writer.chunks {
+		// definition levels are single bit-packed run of RLE/bit-packing hybrid encoding with 4 bytes length prefix
+		var levels [4 + binary.MaxVarintLen64]byte
+		headerSize := binary.PutUvarint(levels[4:], uint64(len(chunk.defined))<<1|1)
+		binary.LittleEndian.PutUint32(levels[:4], uint32(headerSize+len(chunk.defined)))
+		pageSize := 4 + headerSize + len(chunk.defined) + len(chunk.values)
+		if int64(pageSize) > math.MaxInt32 {
+			return fmt.Errorf("data page exceeds 2GB, use smaller row groups")
+		}
+		header := &thriftWriter{}
+		header.structBegin()
+		header.fieldI32(1, parquetPageData)
+		header.fieldI32(2, int32(pageSize))
+		header.fieldI32(3, int32(pageSize))
+		header.fieldStruct(5, func() {
+			header.fieldI32(1, int32(writer.rows))
+			header.fieldI32(2, parquetEncodingPlain)
+			header.fieldI32(3, parquetEncodingRLE)
+			header.fieldI32(4, parquetEncodingRLE)
+		})
+		header.structEnd()
+		column := columnChunkMeta{offset: writer.offset, values: int64(writer.rows)}
+		for _, data := range [][]byte{header.buf, levels[:4+headerSize], chunk.defined, chunk.values} {
+			if err := writer.write(data); nil != err {
+				return err
+			}
+		}
+		column.size = writer.offset - column.offset
+		rowGroup.size += column.size
+		rowGroup.columns = append(rowGroup.columns, column)
+	}
+	writer.rowGroups = append(writer.rowGroups, rowGroup)
+	writer.totalRows += int64(writer.rows)
+	writer.reset()
+	return nil
+}
+
+// Close writes the last row group and file footer, underlying writer is not closed
+func (writer *TWriter) Close() error {
+	if err := writer.flush(); nil != err {
+		return err
+	}
+	footer := &thriftWriter{}
+	footer.structBegin()
+	footer.fieldI32(1, 1)
+	footer.fieldStructList(2, len(writer.columns)+1, func(i int) {
+		if 0 == i {
+			footer.fieldString(4, "schema")
+			footer.fieldI32(5, int32(len(writer.columns)))
+			return
+		}
+		column := writer.columns[i-1]
+		footer.fieldI32(1, physicalType(column.Type))
+		footer.fieldI32(3, parquetOptional)
+		footer.fieldString(4, column.Name)
+		switch column.Type {
+		case tablescanner.ColumnTypeDate:
+			footer.fieldStruct(10, func() {
+				footer.fieldStruct(8, func() {
+					footer.fieldBool(1, false)
+					footer.fieldStruct(2, func() {
+						footer.fieldStruct(1, func() {})
+					})
+				})
+			})
+		case tablescanner.ColumnTypeInt, tablescanner.ColumnTypeFloat, tablescanner.ColumnTypeBool:
+		default:
+			footer.fieldI32(6, parquetConvertedUTF8)
+			footer.fieldStruct(10, func() {
+				footer.fieldStruct(1, func() {})
+			})
+		}
+	})
+	footer.fieldI64(3, writer.totalRows)
+	footer.fieldStructList(4, len(writer.rowGroups), func(i int) {
+		rowGroup := writer.rowGroups[i]
+		footer.fieldStructList(1, len(rowGroup.columns), func(j int) {
+			column := rowGroup.columns[j]
+			footer.fieldI64(2, column.offset)
+			footer.fieldStruct(3, func() {
+				footer.fieldI32(1, physicalType(writer.columns[j].Type))
+				footer.fieldI32List(2, []int32{parquetEncodingPlain, parquetEncodingRLE})
+				footer.fieldStringList(3, []string{writer.columns[j].Name})
+				footer.fieldI32(4, parquetCodecUncompressed)
+				footer.fieldI64(5, column.values)
+				footer.fieldI64(6, column.size)
+				footer.fieldI64(7, column.size)
+				footer.fieldI64(9, column.offset)
+			})
+		})
+		footer.fieldI64(2, rowGroup.size)
+		footer.fieldI64(3, rowGroup.rows)
+	})
+	footer.fieldString(6, createdBy)
+	footer.structEnd()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer.buf)))
+	for _, data := range [][]byte{footer.buf, length[:], []byte(parquetMagic)} {
+		if err := writer.write(data); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSheet streams sheet rows typed by tablescanner.NewTypedRowReader()
+func WriteSheet(output io.Writer, scanner tablescanner.ITableDocumentScanner, sheetId int, options TOptions) error {
+	err, reader := tablescanner.NewTypedRowReader(scanner, sheetId, options.TTypedRowOptions)
+	if nil != err {
+		return err
+	}
+	err, writer := NewWriter(output, reader.Columns(), options.RowGroupRows)
+	if nil != err {
+		return err
+	}
+	for {
+		err, row := reader.Next()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err
+		}
+		if err = writer.WriteRow(row); nil != err {
+			return err
+		}
+	}
+	return writer.Close()
+}
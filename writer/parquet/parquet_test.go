@@ -0,0 +1,298 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	tablescanner "github.com/technix86/golang-tablescanner"
+)
+
+func TestThriftWriter(t *testing.T) {
+	writer := &thriftWriter{}
+	writer.structBegin()
+	writer.fieldI32(1, 0)
+	writer.fieldI32(2, -1)
+	writer.fieldI64(20, 300) // field id delta above 15 is written as zigzag id
+	writer.fieldString(21, "ab")
+	writer.fieldBool(22, true)
+	writer.fieldBool(23, false)
+	writer.fieldI32List(24, []int32{1, 2})
+	writer.fieldStruct(25, func() {
+		writer.fieldI32(1, 1)
+	})
+	writer.fieldI32List(26, make([]int32, 15)) // list size from 15 is written as varint
+	writer.structEnd()
+	expected := []byte{
+		0x15, 0x00,
+		0x15, 0x01,
+		0x06, 0x28, 0xD8, 0x04,
+		0x18, 0x02, 'a', 'b',
+		0x11,
+		0x12,
+		0x19, 0x25, 0x02, 0x04,
+		0x1C, 0x15, 0x02, 0x00,
+		0x19, 0xF5, 0x0F, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0x00,
+	}
+	if !bytes.Equal(expected, writer.buf) {
+		t.Errorf("encoded struct is\n% X\nexpected\n% X", writer.buf, expected)
+	}
+}
+
+// thriftReader decodes compact protocol to maps by field id, it is independent from thriftWriter
+type thriftReader struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+func (reader *thriftReader) varint() uint64 {
+	value, size := binary.Uvarint(reader.buf[reader.pos:])
+	if size <= 0 {
+		reader.t.Fatalf("broken varint at %d", reader.pos)
+	}
+	reader.pos += size
+	return value
+}
+
+func (reader *thriftReader) zigzag() int64 {
+	value := reader.varint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (reader *thriftReader) value(valueType byte) interface{} {
+	switch valueType {
+	case thriftBoolTrue:
+		return true
+	case thriftBoolFalse:
+		return false
+	case thriftI32, thriftI64:
+		return reader.zigzag()
+	case thriftBinary:
+		size := int(reader.varint())
+		reader.pos += size
+		return string(reader.buf[reader.pos-size : reader.pos])
+	case thriftList:
+		header := reader.buf[reader.pos]
+		reader.pos++
+		size := int(header >> 4)
+		if 15 == size {
+			size = int(reader.varint())
+		}
+		res := make([]interface{}, size)
+		for i := range res {
+			res[i] = reader.value(header & 0x0F)
+		}
+		return res
+	case thriftStruct:
+		return reader.structure()
+	}
+	reader.t.Fatalf("unexpected thrift type %d at %d", valueType, reader.pos)
+	return nil
+}
+
+func (reader *thriftReader) structure() map[int]interface{} {
+	res := make(map[int]interface{})
+	id := 0
+	for {
+		header := reader.buf[reader.pos]
+		reader.pos++
+		if 0 == header {
+			return res
+		}
+		if delta := int(header >> 4); 0 != delta {
+			id += delta
+		} else {
+			id = int(reader.zigzag())
+		}
+		res[id] = reader.value(header & 0x0F)
+	}
+}
+
+func readThrift(t *testing.T, data []byte) (map[int]interface{}, int) {
+	reader := &thriftReader{t: t, buf: data}
+	return reader.structure(), reader.pos
+}
+
+func field(t *testing.T, structure map[int]interface{}, path ...int) interface{} {
+	var value interface{} = structure
+	for _, id := range path {
+		typed, ok := value.(map[int]interface{})
+		if !ok {
+			t.Fatalf("field %v is not a struct", path)
+		}
+		if value, ok = typed[id]; !ok {
+			t.Fatalf("field %v is absent", path)
+		}
+	}
+	return value
+}
+
+// readDefinitionLevels decodes RLE/bit-packing hybrid levels of bit width 1 with 4 bytes length prefix
+func readDefinitionLevels(t *testing.T, data []byte, count int) ([]bool, int) {
+	size := int(binary.LittleEndian.Uint32(data))
+	reader := &thriftReader{t: t, buf: data[4 : 4+size]}
+	res := []bool{}
+	for reader.pos < len(reader.buf) {
+		header := reader.varint()
+		if 0 == header&1 {
+			defined := 0 != reader.buf[reader.pos]
+			reader.pos++
+			for i := uint64(0); i < header>>1; i++ {
+				res = append(res, defined)
+			}
+			continue
+		}
+		for i := 0; i < int(header>>1)*8; i++ {
+			res = append(res, 0 != reader.buf[reader.pos+i/8]&(1<<uint(i%8)))
+		}
+		reader.pos += int(header >> 1)
+	}
+	if len(res) < count {
+		t.Fatalf("%d definition levels are decoded, expected %d", len(res), count)
+	}
+	return res[:count], 4 + size
+}
+
+// readColumnChunk decodes single data page of column chunk
+func readColumnChunk(t *testing.T, file []byte, offset int64, physical int64, rows int) []interface{} {
+	header, headerSize := readThrift(t, file[offset:])
+	if int64(parquetPageData) != field(t, header, 1) {
+		t.Fatalf("page type is %v", field(t, header, 1))
+	}
+	if rows != int(field(t, header, 5, 1).(int64)) {
+		t.Errorf("page has %v values, expected %d", field(t, header, 5, 1), rows)
+	}
+	pageSize := int(field(t, header, 3).(int64))
+	page := file[int(offset)+headerSize : int(offset)+headerSize+pageSize]
+	defined, levelsSize := readDefinitionLevels(t, page, rows)
+	values := page[levelsSize:]
+	res := make([]interface{}, rows)
+	bools := 0
+	for row, isDefined := range defined {
+		if !isDefined {
+			continue
+		}
+		switch physical {
+		case parquetInt64:
+			res[row] = int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetDouble:
+			res[row] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetBoolean:
+			res[row] = 0 != values[bools/8]&(1<<uint(bools%8))
+			bools++
+		case parquetByteArray:
+			size := binary.LittleEndian.Uint32(values)
+			res[row] = string(values[4 : 4+size])
+			values = values[4+size:]
+		}
+	}
+	if parquetBoolean == physical {
+		values = values[(bools+7)/8:]
+	}
+	if 0 != len(values) {
+		t.Errorf("%d bytes are left after values of page", len(values))
+	}
+	return res
+}
+
+func TestWriter(t *testing.T) {
+	columns := []tablescanner.TTypedColumn{
+		{Name: "id", Type: tablescanner.ColumnTypeInt},
+		{Name: "price", Type: tablescanner.ColumnTypeFloat},
+		{Name: "ok", Type: tablescanner.ColumnTypeBool},
+		{Name: "name", Type: tablescanner.ColumnTypeString},
+		{Name: "day", Type: tablescanner.ColumnTypeDate},
+	}
+	day := time.Date(2024, 2, 29, 13, 45, 10, 0, time.UTC)
+	rows := [][]interface{}{
+		{int64(1), 1.5, true, "a", day},
+		{nil, nil, nil, nil, nil},
+		{int64(-3), -2.25, false, "ĉ", day.AddDate(-100, 0, 0)},
+		{int64(math.MaxInt64), 0.0, true, "", nil},
+		{int64(5), nil, true, "long text", day.Add(time.Millisecond)},
+	}
+	// 9 rows by 4 rows per group make bool bitmap and definition levels cross byte boundary
+	rows = append(rows, rows[:4]...)
+	output := &bytes.Buffer{}
+	err, writer := NewWriter(output, columns, 4)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); nil != err {
+		t.Fatal(err)
+	}
+	file := output.Bytes()
+	if !bytes.HasPrefix(file, []byte(parquetMagic)) || !bytes.HasSuffix(file, []byte(parquetMagic)) {
+		t.Fatalf("magic is not found")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer, size := readThrift(t, file[len(file)-8-footerSize:len(file)-8])
+	if footerSize != size {
+		t.Errorf("footer is %d bytes, decoded %d", footerSize, size)
+	}
+	if int64(len(rows)) != field(t, footer, 3) {
+		t.Errorf("num_rows is %v, expected %d", field(t, footer, 3), len(rows))
+	}
+	schema := field(t, footer, 2).([]interface{})
+	if len(columns)+1 != len(schema) || int64(len(columns)) != field(t, schema[0].(map[int]interface{}), 5) {
+		t.Fatalf("schema is %v", schema)
+	}
+	expectedTypes := []int64{parquetInt64, parquetDouble, parquetBoolean, parquetByteArray, parquetInt64}
+	for i, column := range columns {
+		element := schema[i+1].(map[int]interface{})
+		if column.Name != field(t, element, 4) || expectedTypes[i] != field(t, element, 1) || int64(parquetOptional) != field(t, element, 3) {
+			t.Errorf("schema element of %s is %v", column.Name, element)
+		}
+	}
+	if _, ok := field(t, schema[4].(map[int]interface{}), 10, 1).(map[int]interface{}); !ok {
+		t.Errorf("name column is not STRING")
+	}
+	if false != field(t, schema[5].(map[int]interface{}), 10, 8, 1) {
+		t.Errorf("day column is adjusted to UTC")
+	}
+	if _, ok := field(t, schema[5].(map[int]interface{}), 10, 8, 2, 1).(map[int]interface{}); !ok {
+		t.Errorf("day column is not TIMESTAMP(MILLIS)")
+	}
+	rowGroups := field(t, footer, 4).([]interface{})
+	if 3 != len(rowGroups) {
+		t.Fatalf("file has %d row groups, expected 3", len(rowGroups))
+	}
+	decoded := [][]interface{}{}
+	for _, rowGroup := range rowGroups {
+		groupRows := int(field(t, rowGroup.(map[int]interface{}), 3).(int64))
+		chunks := field(t, rowGroup.(map[int]interface{}), 1).([]interface{})
+		values := make([][]interface{}, len(chunks))
+		for i, chunk := range chunks {
+			meta := field(t, chunk.(map[int]interface{}), 3).(map[int]interface{})
+			if columns[i].Name != field(t, meta, 3).([]interface{})[0] {
+				t.Errorf("column chunk path is %v, expected %s", field(t, meta, 3), columns[i].Name)
+			}
+			values[i] = readColumnChunk(t, file, field(t, meta, 9).(int64), field(t, meta, 1).(int64), groupRows)
+		}
+		for row := 0; row < groupRows; row++ {
+			decodedRow := make([]interface{}, len(columns))
+			for i := range columns {
+				decodedRow[i] = values[i][row]
+				if milliseconds, ok := decodedRow[i].(int64); ok && tablescanner.ColumnTypeDate == columns[i].Type {
+					decodedRow[i] = time.Unix(0, milliseconds*int64(time.Millisecond)).UTC()
+				}
+			}
+			decoded = append(decoded, decodedRow)
+		}
+	}
+	if !reflect.DeepEqual(rows, decoded) {
+		t.Errorf("decoded rows are\n%v\nexpected\n%v", decoded, rows)
+	}
+}
@@ -0,0 +1,118 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// thrift compact protocol type ids
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// thriftWriter encodes structs by thrift compact protocol, fields must be written in ascending id order
+type thriftWriter struct {
+	buf     []byte
+	lastIds []int16
+}
+
+func (writer *thriftWriter) varint(value uint64) {
+	var encoded [binary.MaxVarintLen64]byte
+	writer.buf = append(writer.buf, encoded[:binary.PutUvarint(encoded[:], value)]...)
+}
+
+func (writer *thriftWriter) zigzag(value int64) {
+	writer.varint(uint64((value << 1) ^ (value >> 63)))
+}
+
+func (writer *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	last := &writer.lastIds[len(writer.lastIds)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		writer.buf = append(writer.buf, byte(delta)<<4|fieldType)
+	} else {
+		writer.buf = append(writer.buf, fieldType)
+		writer.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (writer *thriftWriter) structBegin() {
+	writer.lastIds = append(writer.lastIds, 0)
+}
+
+func (writer *thriftWriter) structEnd() {
+	writer.buf = append(writer.buf, 0)
+	writer.lastIds = writer.lastIds[:len(writer.lastIds)-1]
+}
+
+func (writer *thriftWriter) fieldBool(id int16, value bool) {
+	if value {
+		writer.fieldHeader(id, thriftBoolTrue)
+	} else {
+		writer.fieldHeader(id, thriftBoolFalse)
+	}
+}
+
+func (writer *thriftWriter) fieldI32(id int16, value int32) {
+	writer.fieldHeader(id, thriftI32)
+	writer.zigzag(int64(value))
+}
+
+func (writer *thriftWriter) fieldI64(id int16, value int64) {
+	writer.fieldHeader(id, thriftI64)
+	writer.zigzag(value)
+}
+
+func (writer *thriftWriter) fieldString(id int16, value string) {
+	writer.fieldHeader(id, thriftBinary)
+	writer.varint(uint64(len(value)))
+	writer.buf = append(writer.buf, value...)
+}
+
+// fieldStruct writes struct field, body writes its fields
+func (writer *thriftWriter) fieldStruct(id int16, body func()) {
+	writer.fieldHeader(id, thriftStruct)
+	writer.structBegin()
+	body()
+	writer.structEnd()
+}
+
+func (writer *thriftWriter) listHeader(id int16, elementType byte, size int) {
+	writer.fieldHeader(id, thriftList)
+	if size < 15 {
+		writer.buf = append(writer.buf, byte(size)<<4|elementType)
+	} else {
+		writer.buf = append(writer.buf, 0xF0|elementType)
+		writer.varint(uint64(size))
+	}
+}
+
+func (writer *thriftWriter) fieldI32List(id int16, values []int32) {
+	writer.listHeader(id, thriftI32, len(values))
+	for _, value := range values {
+		writer.zigzag(int64(value))
+	}
+}
+
+func (writer *thriftWriter) fieldStringList(id int16, values []string) {
+	writer.listHeader(id, thriftBinary, len(values))
+	for _, value := range values {
+		writer.varint(uint64(len(value)))
+		writer.buf = append(writer.buf, value...)
+	}
+}
+
+// fieldStructList writes list of size structs, body writes fields of i-th struct
+func (writer *thriftWriter) fieldStructList(id int16, size int, body func(i int)) {
+	writer.listHeader(id, thriftStruct, size)
+	for i := 0; i < size; i++ {
+		writer.structBegin()
+		body(i)
+		writer.structEnd()
+	}
+}